@@ -11,11 +11,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/PerformLine/friendscript/scripting"
 	"github.com/PerformLine/friendscript/utils"
 	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/sliceutil"
 	"github.com/PerformLine/go-stockutil/stringutil"
-	"github.com/PerformLine/go-stockutil/timeutil"
 	"github.com/PerformLine/go-stockutil/typeutil"
 	"github.com/kyokomi/emoji"
 	defaults "github.com/mcuadros/go-defaults"
@@ -183,7 +183,8 @@ func (self *Commands) Run(filename string, args *RunArgs) (interface{}, error) {
 	})
 }
 
-// Pauses execution of the current script for the given duration.
+// Pauses execution of the current script for the given duration.  Durations may be given as
+// duration literals (e.g.: 500ms, 2m30s), strings in the same format, or as a number of milliseconds.
 func (self *Commands) Wait(delay interface{}) error {
 	var duration time.Duration
 
@@ -191,13 +192,25 @@ func (self *Commands) Wait(delay interface{}) error {
 		duration = delayD
 	} else if delayMs, err := stringutil.ConvertToInteger(delay); err == nil {
 		duration = time.Duration(delayMs) * time.Millisecond
-	} else if delayParsed, err := timeutil.ParseDuration(fmt.Sprintf("%v", delay)); err == nil {
+	} else if delayParsed, err := scripting.ParseDuration(fmt.Sprintf("%v", delay)); err == nil {
 		duration = delayParsed
 	} else {
 		return fmt.Errorf("invalid duration: %v", err)
 	}
 
 	log.Infof("Waiting for %v", duration)
-	time.Sleep(duration)
-	return nil
+
+	if self.env == nil {
+		time.Sleep(duration)
+		return nil
+	}
+
+	var ctx = utils.RuntimeContext(self.env)
+
+	select {
+	case <-time.After(duration):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
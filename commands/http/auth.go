@@ -0,0 +1,723 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// send a username and password with every request
+	AuthBasic = `basic`
+
+	// send a token with every request
+	AuthBearer = `bearer`
+
+	// answer the server's digest challenge using a username and password
+	AuthDigest = `digest`
+
+	// fetch a token from an OAuth2 token endpoint using client credentials, and send it with every request
+	AuthOAuth2 = `oauth2`
+
+	// sign requests with an access key using AWS Signature Version 4
+	AuthAWS = `aws`
+)
+
+// The prefix for credential values that should be read from an environment variable, e.g.: "env:API_TOKEN"
+var CredentialEnvPrefix = `env:`
+
+// tokens are refreshed this long before they actually expire
+var TokenExpirySkew = 10 * time.Second
+
+type AuthArgs struct {
+	// The authentication mechanism to use: "basic", "bearer", "digest", "oauth2", or "aws".  If not
+	// given, this is inferred from which credentials are present.
+	Type string `json:"type"`
+
+	// The username for basic and digest authentication.
+	Username string `json:"username"`
+
+	// The password for basic and digest authentication.
+	Password string `json:"password"`
+
+	// The token for bearer authentication.
+	Token string `json:"token"`
+
+	// The URL of the OAuth2 token endpoint.
+	TokenURL string `json:"token_url"`
+
+	// The OAuth2 client ID.
+	ClientID string `json:"client_id"`
+
+	// The OAuth2 client secret.
+	ClientSecret string `json:"client_secret"`
+
+	// The OAuth2 scopes to request.
+	Scopes []string `json:"scopes"`
+
+	// How the OAuth2 client credentials are sent to the token endpoint: "basic" (in an Authorization
+	// header) or "body" (as form fields.)
+	ClientAuth string `json:"client_auth"`
+
+	// The AWS access key ID (defaults to the AWS_ACCESS_KEY_ID environment variable.)
+	AccessKey string `json:"access_key"`
+
+	// The AWS secret access key (defaults to the AWS_SECRET_ACCESS_KEY environment variable.)
+	SecretKey string `json:"secret_key"`
+
+	// The AWS session token, if using temporary credentials (defaults to the AWS_SESSION_TOKEN
+	// environment variable.)
+	SessionToken string `json:"session_token"`
+
+	// The AWS region (defaults to the AWS_REGION or AWS_DEFAULT_REGION environment variables.)
+	Region string `json:"region"`
+
+	// The name of the AWS service being called (e.g.: "s3", "execute-api".)
+	Service string `json:"service"`
+}
+
+// return the authentication mechanism these options describe
+func (self *AuthArgs) mechanism() string {
+	if self.Type != `` {
+		return strings.ToLower(self.Type)
+	}
+
+	switch {
+	case self.Token != ``:
+		return AuthBearer
+	case self.TokenURL != ``:
+		return AuthOAuth2
+	case self.AccessKey != `` || self.Service != ``:
+		return AuthAWS
+	case self.Username != ``:
+		return AuthBasic
+	}
+
+	return ``
+}
+
+// whether requests may need to be sent more than once (or have their body hashed) to authenticate
+func (self *AuthArgs) needsBody() bool {
+	if self == nil {
+		return false
+	}
+
+	switch self.mechanism() {
+	case AuthDigest, AuthOAuth2, AuthAWS:
+		return true
+	}
+
+	return false
+}
+
+// whether the request body is hashed to authenticate requests, so it must be read before they're sent
+func (self *AuthArgs) signsBody() bool {
+	return self != nil && self.mechanism() == AuthAWS
+}
+
+// Resolve a credential value, which may refer to an environment variable.  If the value is empty,
+// the first of the fallback environment variables that is set is used.
+func credential(value string, fallbackEnv ...string) (string, error) {
+	if strings.HasPrefix(value, CredentialEnvPrefix) {
+		var name = strings.TrimPrefix(value, CredentialEnvPrefix)
+
+		if v, ok := os.LookupEnv(name); ok {
+			return v, nil
+		} else {
+			return ``, fmt.Errorf("environment variable %s is not set", name)
+		}
+	} else if value == `` {
+		for _, name := range fallbackEnv {
+			if v := os.Getenv(name); v != `` {
+				return v, nil
+			}
+		}
+	}
+
+	return value, nil
+}
+
+// resolve several credentials at once, stopping at the first error
+func credentials(values ...*string) error {
+	for _, value := range values {
+		if v, err := credential(*value); err == nil {
+			*value = v
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Add credentials to the given request.  The body is only needed for mechanisms that sign it.
+func (self *Commands) authenticate(req *http.Request, reqargs *RequestArgs, body []byte) error {
+	var auth = reqargs.Auth
+
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.mechanism() {
+	case AuthBasic:
+		var username, password = auth.Username, auth.Password
+
+		if err := credentials(&username, &password); err == nil {
+			req.SetBasicAuth(username, password)
+		} else {
+			return err
+		}
+
+	case AuthBearer:
+		if token, err := credential(auth.Token); err == nil {
+			req.Header.Set(`Authorization`, `Bearer `+token)
+		} else {
+			return err
+		}
+
+	case AuthDigest:
+		return self.digestAuthenticate(req, auth, body)
+
+	case AuthOAuth2:
+		if token, err := self.oauth2Token(reqargs); err == nil {
+			req.Header.Set(`Authorization`, token.authorization())
+		} else {
+			return err
+		}
+
+	case AuthAWS:
+		return signAWSv4(req, auth, body, time.Now())
+
+	case ``:
+		return nil
+
+	default:
+		return fmt.Errorf("unknown authentication type %q", auth.Type)
+	}
+
+	return nil
+}
+
+// Called when a request was rejected with a 401 status; this discards any cached tokens or
+// challenges and reports whether sending the request again with new ones might succeed.
+func (self *Commands) reauthenticate(req *http.Request, reqargs *RequestArgs, response *http.Response) bool {
+	if reqargs.Auth == nil {
+		return false
+	}
+
+	switch reqargs.Auth.mechanism() {
+	case AuthDigest:
+		if challenge, ok := parseDigestChallenge(response.Header.Values(`WWW-Authenticate`)); ok {
+			self.lock.Lock()
+			self.digests[req.URL.Host] = challenge
+			self.lock.Unlock()
+
+			return true
+		}
+
+	case AuthOAuth2:
+		self.lock.Lock()
+		delete(self.tokens, reqargs.Auth.tokenKey())
+		self.lock.Unlock()
+
+		return true
+	}
+
+	return false
+}
+
+// An access token issued by an OAuth2 token endpoint.
+type oauth2Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	expires     time.Time
+}
+
+func (self *oauth2Token) expired() bool {
+	return !self.expires.IsZero() && time.Now().Add(TokenExpirySkew).After(self.expires)
+}
+
+func (self *oauth2Token) authorization() string {
+	if self.TokenType == `` || strings.EqualFold(self.TokenType, `bearer`) {
+		return `Bearer ` + self.AccessToken
+	} else {
+		return self.TokenType + ` ` + self.AccessToken
+	}
+}
+
+// tokens are cached for each combination of token endpoint, client, and scopes
+func (self *AuthArgs) tokenKey() string {
+	return self.TokenURL + `|` + self.ClientID + `|` + strings.Join(self.Scopes, ` `)
+}
+
+// return a cached token for the given request's credentials, or fetch a new one
+func (self *Commands) oauth2Token(reqargs *RequestArgs) (*oauth2Token, error) {
+	var auth = reqargs.Auth
+	var key = auth.tokenKey()
+
+	self.lock.Lock()
+	var token, ok = self.tokens[key]
+	self.lock.Unlock()
+
+	if ok && !token.expired() {
+		return token, nil
+	}
+
+	var clientID, clientSecret, tokenURL = auth.ClientID, auth.ClientSecret, auth.TokenURL
+
+	if err := credentials(&clientID, &clientSecret, &tokenURL); err != nil {
+		return nil, err
+	} else if tokenURL == `` {
+		return nil, fmt.Errorf("oauth2 authentication requires a token_url")
+	}
+
+	var form = url.Values{
+		`grant_type`: []string{`client_credentials`},
+	}
+
+	if len(auth.Scopes) > 0 {
+		form.Set(`scope`, strings.Join(auth.Scopes, ` `))
+	}
+
+	var clientAuth = strings.ToLower(auth.ClientAuth)
+
+	if clientAuth == `body` {
+		form.Set(`client_id`, clientID)
+		form.Set(`client_secret`, clientSecret)
+	} else if clientAuth != `` && clientAuth != `basic` {
+		return nil, fmt.Errorf("invalid client_auth %q", auth.ClientAuth)
+	}
+
+	req, err := http.NewRequestWithContext(self.context(), `POST`, tokenURL, strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(`Content-Type`, `application/x-www-form-urlencoded`)
+	req.Header.Set(`Accept`, `application/json`)
+
+	if clientAuth != `body` {
+		req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+	}
+
+	transport, err := self.transport(reqargs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var client = &http.Client{
+		Timeout:   reqargs.Timeout,
+		Transport: self.roundTripper(transport),
+	}
+
+	response, err := client.Do(req)
+
+	if err != nil {
+		return nil, fmt.Errorf("oauth2 token request failed: %v", err)
+	}
+
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("oauth2 token request failed: HTTP %v: %s", response.Status, strings.TrimSpace(string(data)))
+	}
+
+	token = new(oauth2Token)
+
+	if err := json.Unmarshal(data, token); err != nil {
+		return nil, fmt.Errorf("invalid oauth2 token response: %v", err)
+	} else if token.AccessToken == `` {
+		return nil, fmt.Errorf("invalid oauth2 token response: no access_token")
+	}
+
+	if token.ExpiresIn > 0 {
+		token.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+
+	self.lock.Lock()
+	self.tokens[key] = token
+	self.lock.Unlock()
+
+	return token, nil
+}
+
+// A challenge sent by a server that uses digest authentication (RFC 7616).
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	count     int
+}
+
+// find and parse the first digest challenge in the given WWW-Authenticate headers
+func parseDigestChallenge(headers []string) (*digestChallenge, bool) {
+	for _, header := range headers {
+		if len(header) < 7 || !strings.EqualFold(header[:7], `digest `) {
+			continue
+		}
+
+		var params = parseAuthParams(header[7:])
+		var challenge = &digestChallenge{
+			realm:     params[`realm`],
+			nonce:     params[`nonce`],
+			opaque:    params[`opaque`],
+			algorithm: params[`algorithm`],
+		}
+
+		// we only support "auth" quality of protection, but some servers only support RFC 2069 digests
+		if qop, ok := params[`qop`]; ok {
+			for _, option := range strings.Split(qop, `,`) {
+				if strings.TrimSpace(option) == `auth` {
+					challenge.qop = `auth`
+				}
+			}
+
+			if challenge.qop == `` {
+				continue
+			}
+		}
+
+		if challenge.nonce != `` {
+			return challenge, true
+		}
+	}
+
+	return nil, false
+}
+
+// parse the comma-separated key=value (or key="quoted value") parameters of an authentication challenge
+func parseAuthParams(in string) map[string]string {
+	var params = make(map[string]string)
+
+	for in = strings.TrimSpace(in); in != ``; {
+		var key, value string
+		var eq = strings.IndexByte(in, '=')
+
+		if eq < 0 {
+			break
+		}
+
+		key = strings.ToLower(strings.TrimSpace(in[:eq]))
+		in = strings.TrimSpace(in[eq+1:])
+
+		if strings.HasPrefix(in, `"`) {
+			var i = 1
+			var out strings.Builder
+
+			for ; i < len(in) && in[i] != '"'; i++ {
+				if in[i] == '\\' && i+1 < len(in) {
+					i++
+				}
+
+				out.WriteByte(in[i])
+			}
+
+			value = out.String()
+
+			if i < len(in) {
+				in = in[i+1:]
+			} else {
+				in = ``
+			}
+		} else if comma := strings.IndexByte(in, ','); comma >= 0 {
+			value = strings.TrimSpace(in[:comma])
+			in = in[comma:]
+		} else {
+			value = in
+			in = ``
+		}
+
+		params[key] = value
+		in = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(in), `,`))
+	}
+
+	return params
+}
+
+// answer the last digest challenge we received from this request's host (if any)
+func (self *Commands) digestAuthenticate(req *http.Request, auth *AuthArgs, body []byte) error {
+	var username, password = auth.Username, auth.Password
+
+	if err := credentials(&username, &password); err != nil {
+		return err
+	}
+
+	// each use of a challenge is numbered, so the server can tell replayed requests apart
+	self.lock.Lock()
+	var challenge, ok = self.digests[req.URL.Host]
+	var count int
+
+	if ok {
+		challenge.count += 1
+		count = challenge.count
+	}
+
+	self.lock.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	var h func() hash.Hash = md5.New
+	var algorithm = strings.ToUpper(challenge.algorithm)
+
+	switch strings.TrimSuffix(algorithm, `-SESS`) {
+	case ``, `MD5`:
+		h = md5.New
+	case `SHA-256`:
+		h = sha256.New
+	default:
+		return fmt.Errorf("unsupported digest algorithm %q", challenge.algorithm)
+	}
+
+	var digest = func(parts ...string) string {
+		var hasher = h()
+		hasher.Write([]byte(strings.Join(parts, `:`)))
+		return hex.EncodeToString(hasher.Sum(nil))
+	}
+
+	var uri = req.URL.RequestURI()
+	var nc = fmt.Sprintf("%08x", count)
+	var cnonce = randomHex(8)
+	var ha1 = digest(username, challenge.realm, password)
+	var ha2 = digest(req.Method, uri)
+	var response string
+
+	if strings.HasSuffix(algorithm, `-SESS`) {
+		ha1 = digest(ha1, challenge.nonce, cnonce)
+	}
+
+	if challenge.qop != `` {
+		response = digest(ha1, challenge.nonce, nc, cnonce, challenge.qop, ha2)
+	} else {
+		response = digest(ha1, challenge.nonce, ha2)
+	}
+
+	var header = fmt.Sprintf(
+		`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		escapeQuotes(username),
+		escapeQuotes(challenge.realm),
+		escapeQuotes(challenge.nonce),
+		escapeQuotes(uri),
+		response,
+	)
+
+	if challenge.algorithm != `` {
+		header += `, algorithm=` + challenge.algorithm
+	}
+
+	if challenge.opaque != `` {
+		header += fmt.Sprintf(`, opaque="%s"`, escapeQuotes(challenge.opaque))
+	}
+
+	if challenge.qop != `` {
+		header += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="%s"`, challenge.qop, nc, cnonce)
+	}
+
+	req.Header.Set(`Authorization`, header)
+	return nil
+}
+
+func randomHex(n int) string {
+	var data = make([]byte, n)
+	rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+// Sign the given request using AWS Signature Version 4.
+func signAWSv4(req *http.Request, auth *AuthArgs, body []byte, now time.Time) error {
+	var accessKey, secretKey, sessionToken, region, service string
+	var err error
+
+	if accessKey, err = credential(auth.AccessKey, `AWS_ACCESS_KEY_ID`); err != nil {
+		return err
+	} else if secretKey, err = credential(auth.SecretKey, `AWS_SECRET_ACCESS_KEY`); err != nil {
+		return err
+	} else if sessionToken, err = credential(auth.SessionToken, `AWS_SESSION_TOKEN`); err != nil {
+		return err
+	} else if region, err = credential(auth.Region, `AWS_REGION`, `AWS_DEFAULT_REGION`); err != nil {
+		return err
+	} else if service, err = credential(auth.Service); err != nil {
+		return err
+	}
+
+	if accessKey == `` || secretKey == `` {
+		return fmt.Errorf("aws authentication requires an access_key and secret_key")
+	} else if region == `` || service == `` {
+		return fmt.Errorf("aws authentication requires a region and service")
+	}
+
+	var payloadHash = sha256.Sum256(body)
+	var payload = hex.EncodeToString(payloadHash[:])
+	var amzDate = now.UTC().Format(`20060102T150405Z`)
+	var date = amzDate[:8]
+	var scope = strings.Join([]string{date, region, service, `aws4_request`}, `/`)
+	var host = req.Host
+
+	if host == `` {
+		host = req.URL.Host
+	}
+
+	req.Header.Set(`X-Amz-Date`, amzDate)
+
+	if sessionToken != `` {
+		req.Header.Set(`X-Amz-Security-Token`, sessionToken)
+	}
+
+	// S3 requires the payload hash to be sent as well
+	if service == `s3` {
+		req.Header.Set(`X-Amz-Content-Sha256`, payload)
+	}
+
+	// sign the host, content type, and all x-amz-* headers
+	var signed = map[string]string{
+		`host`: host,
+	}
+
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); name == `content-type` || strings.HasPrefix(name, `x-amz-`) {
+			var trimmed = make([]string, len(values))
+
+			for i, v := range values {
+				trimmed[i] = strings.Join(strings.Fields(v), ` `)
+			}
+
+			signed[name] = strings.Join(trimmed, `,`)
+		}
+	}
+
+	var names = make([]string, 0, len(signed))
+
+	for name := range signed {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + `:` + signed[name] + "\n")
+	}
+
+	var signedHeaders = strings.Join(names, `;`)
+	var path = awsCanonicalPath(req.URL, service != `s3`)
+
+	var canonicalRequest = strings.Join([]string{
+		req.Method,
+		path,
+		awsCanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	var requestHash = sha256.Sum256([]byte(canonicalRequest))
+	var stringToSign = strings.Join([]string{
+		`AWS4-HMAC-SHA256`,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	var key = []byte(`AWS4` + secretKey)
+
+	for _, part := range []string{date, region, service, `aws4_request`} {
+		key = hmacSHA256(key, part)
+	}
+
+	req.Header.Set(`Authorization`, fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey,
+		scope,
+		signedHeaders,
+		hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+
+	return nil
+}
+
+// the path with each segment URI-encoded; every service but S3 expects the segments to be encoded
+// twice.  The request is sent with the path encoded once the same way, so that the service sees the
+// path that was signed.
+func awsCanonicalPath(u *url.URL, double bool) string {
+	var segments = strings.Split(u.EscapedPath(), `/`)
+	var sent = make([]string, len(segments))
+
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+
+		sent[i] = awsEscape(segment)
+		segments[i] = sent[i]
+
+		if double {
+			segments[i] = awsEscape(segments[i])
+		}
+	}
+
+	if u.RawPath = strings.Join(sent, `/`); u.RawPath == `` {
+		return `/`
+	}
+
+	return strings.Join(segments, `/`)
+}
+
+// query string parameters sorted by name (then value), with spaces encoded as %20
+func awsCanonicalQuery(query url.Values) string {
+	var pairs = make([][2]string, 0)
+
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{awsEscape(key), awsEscape(value)})
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] == pairs[j][0] {
+			return pairs[i][1] < pairs[j][1]
+		}
+
+		return pairs[i][0] < pairs[j][0]
+	})
+
+	var out = make([]string, len(pairs))
+
+	for i, pair := range pairs {
+		out[i] = pair[0] + `=` + pair[1]
+	}
+
+	return strings.Join(out, `&`)
+}
+
+func awsEscape(in string) string {
+	return strings.ReplaceAll(url.QueryEscape(in), `+`, `%20`)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	var mac = hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
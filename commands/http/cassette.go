@@ -0,0 +1,664 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PerformLine/go-stockutil/log"
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"gopkg.in/yaml.v2"
+)
+
+// Whether requests are being recorded to a cassette or replayed from one.
+const (
+	CassetteRecord = `record`
+	CassetteReplay = `replay`
+)
+
+// The headers whose values are redacted from recordings unless other redaction rules are given.
+var DefaultRedactedHeaders = []string{
+	`Authorization`,
+	`Proxy-Authorization`,
+	`Cookie`,
+	`Set-Cookie`,
+	`X-Api-Key`,
+	`X-Amz-Security-Token`,
+}
+
+// The fields whose values are redacted from form-encoded request bodies and JSON response bodies,
+// such as the client secret sent to an OAuth2 token endpoint and the tokens it returns.  No other
+// part of a request or response body is redacted.
+var DefaultRedactedBodyFields = []string{
+	`client_secret`,
+	`access_token`,
+	`refresh_token`,
+	`id_token`,
+}
+
+// The parts of a request that must match a recorded one for its response to be replayed, unless
+// other matchers are given.
+var DefaultCassetteMatchers = []string{`method`, `url`, `body`}
+
+// The value that redacted header values are replaced with.
+const RedactedValue = `[REDACTED]`
+
+// A recording of HTTP requests and the responses they received.
+type Cassette struct {
+	// Each request and its response, in the order they were made.
+	Interactions []*Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// A single request and the response it received.
+type Interaction struct {
+	// The request that was sent.
+	Request *RecordedRequest `json:"request" yaml:"request"`
+
+	// The response that was received.
+	Response *RecordedResponse `json:"response" yaml:"response"`
+
+	// When the request was sent.
+	Started time.Time `json:"started" yaml:"started"`
+
+	// How long it took to send the request and receive the whole response.
+	Duration time.Duration `json:"duration" yaml:"duration"`
+}
+
+type RecordedRequest struct {
+	// The request method.
+	Method string `json:"method" yaml:"method"`
+
+	// The URL that was requested.
+	URL string `json:"url" yaml:"url"`
+
+	// The headers sent with the request (with redacted values replaced.)
+	Headers map[string][]string `json:"headers" yaml:"headers"`
+
+	// The request body.
+	Body string `json:"body" yaml:"body"`
+
+	// How the body is encoded: empty for text, or "base64" for binary data.
+	BodyEncoding string `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+type RecordedResponse struct {
+	// The numeric HTTP status code of the response.
+	Status int `json:"status" yaml:"status"`
+
+	// The text that accompanied the status code.
+	StatusText string `json:"status_text" yaml:"status_text"`
+
+	// The protocol version of the response (e.g.: "HTTP/1.1".)
+	Proto string `json:"proto" yaml:"proto"`
+
+	// The headers of the response (with redacted values replaced.)
+	Headers map[string][]string `json:"headers" yaml:"headers"`
+
+	// The response body.
+	Body string `json:"body" yaml:"body"`
+
+	// How the body is encoded: empty for text, or "base64" for binary data.
+	BodyEncoding string `json:"body_encoding,omitempty" yaml:"body_encoding,omitempty"`
+}
+
+type RecordArgs struct {
+	// The names of headers (in requests and responses) whose values are replaced with "[REDACTED]"
+	// in the cassette.  Names can contain "*" wildcards (e.g.: "X-Secret-*").  Defaults to
+	// DefaultRedactedHeaders.  Only the value of each cookie in a Set-Cookie header is redacted.
+	//
+	// Request and response bodies are recorded as they were sent and received, except for the
+	// fields listed in DefaultRedactedBodyFields (such as OAuth2 client secrets and access tokens.)
+	// Cassettes of requests whose bodies contain other sensitive data should not be shared.
+	Redact []string `json:"redact"`
+}
+
+type ReplayArgs struct {
+	// The parts of a request that must match a recorded request for its response to be replayed.
+	// Any of "method", "url", "scheme", "host", "path", "query", "body", and "header:<name>".
+	// Defaults to DefaultCassetteMatchers.
+	Match []string `json:"match"`
+
+	// Allow recorded responses to be replayed more than once.  Otherwise each recorded response is
+	// only replayed once (in the order they were recorded), and a request that only matches responses
+	// that have already been replayed fails.
+	Repeat bool `json:"repeat"`
+}
+
+// the cassette currently being recorded or replayed
+type recorder struct {
+	commands *Commands
+	path     string
+	mode     string
+	cassette *Cassette
+	redact   []string
+	matchers []string
+	repeat   bool
+	played   []bool
+	count    int
+	lock     sync.Mutex
+}
+
+// Record every subsequent HTTP request and the response it received to a cassette file, which can
+// be played back with http::replay.  The cassette is written as YAML if the filename ends in .yaml
+// or .yml (and as JSON otherwise), and is updated after each request.  Response bodies are read in
+// full before they are returned, so downloads are not streamed while recording.  Apart from OAuth2
+// credentials and tokens, bodies are not redacted (see RecordArgs.)
+func (self *Commands) Record(path string, args *RecordArgs) error {
+	if path == `` {
+		return fmt.Errorf("a cassette path is required")
+	} else if args == nil {
+		args = &RecordArgs{}
+	}
+
+	var redact = args.Redact
+
+	if redact == nil {
+		redact = DefaultRedactedHeaders
+	}
+
+	for _, pattern := range redact {
+		if _, err := matchHeaderName(pattern, ``); err != nil {
+			return fmt.Errorf("invalid redaction rule %q: %v", pattern, err)
+		}
+	}
+
+	var rec = &recorder{
+		commands: self,
+		path:     path,
+		mode:     CassetteRecord,
+		cassette: &Cassette{
+			Interactions: make([]*Interaction, 0),
+		},
+		redact: redact,
+	}
+
+	// start with an empty cassette so that a failed recording doesn't leave an old one behind
+	if err := rec.save(); err != nil {
+		return err
+	}
+
+	self.lock.Lock()
+	self.recorder = rec
+	self.lock.Unlock()
+
+	return nil
+}
+
+// Serve every subsequent HTTP request from a cassette file written by http::record instead of
+// making it.  No requests are sent over the network; requests that don't match a recorded request
+// fail.
+func (self *Commands) Replay(path string, args *ReplayArgs) error {
+	if path == `` {
+		return fmt.Errorf("a cassette path is required")
+	} else if args == nil {
+		args = &ReplayArgs{}
+	}
+
+	var matchers = args.Match
+
+	if len(matchers) == 0 {
+		matchers = DefaultCassetteMatchers
+	}
+
+	for _, matcher := range matchers {
+		switch name, header := stringutil.SplitPair(strings.ToLower(strings.TrimSpace(matcher)), `:`); name {
+		case `method`, `url`, `scheme`, `host`, `path`, `query`, `body`:
+			continue
+		case `header`:
+			if header != `` {
+				continue
+			}
+		}
+
+		return fmt.Errorf("invalid request matcher %q", matcher)
+	}
+
+	var cassette = new(Cassette)
+
+	if data, err := self.readFile(path); err == nil {
+		if err := unmarshalCassette(path, data, cassette); err != nil {
+			return fmt.Errorf("invalid cassette %v: %v", path, err)
+		}
+	} else {
+		return err
+	}
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.recorder = &recorder{
+		commands: self,
+		path:     path,
+		mode:     CassetteReplay,
+		cassette: cassette,
+		matchers: matchers,
+		repeat:   args.Repeat,
+		played:   make([]bool, len(cassette.Interactions)),
+	}
+
+	return nil
+}
+
+// Stop recording or replaying requests, returning the number of requests that were recorded or
+// replayed.  Subsequent requests are made normally.
+func (self *Commands) Eject() (int, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if rec := self.recorder; rec != nil {
+		self.recorder = nil
+
+		rec.lock.Lock()
+		defer rec.lock.Unlock()
+
+		return rec.count, nil
+	}
+
+	return 0, nil
+}
+
+// Return the round tripper that requests should be made with, which records or replays them if a
+// cassette is in use.
+func (self *Commands) roundTripper(transport *http.Transport) http.RoundTripper {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if self.recorder != nil {
+		return &cassetteTransport{
+			recorder: self.recorder,
+			next:     transport,
+		}
+	}
+
+	return transport
+}
+
+type cassetteTransport struct {
+	recorder *recorder
+	next     http.RoundTripper
+}
+
+func (self *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = data
+	}
+
+	if self.recorder.mode == CassetteReplay {
+		return self.recorder.replay(req, body)
+	} else {
+		return self.recorder.record(self.next, req, body)
+	}
+}
+
+// make the given request and add it (and its response) to the cassette
+func (self *recorder) record(next http.RoundTripper, req *http.Request, body []byte) (*http.Response, error) {
+	var started = time.Now()
+	var sent = req.Clone(req.Context())
+
+	if body != nil {
+		sent.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	response, err := next.RoundTrip(sent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+
+	if err != nil {
+		return nil, err
+	}
+
+	response.Body = ioutil.NopCloser(bytes.NewReader(data))
+
+	var interaction = &Interaction{
+		Request: &RecordedRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: self.redactHeaders(req.Header),
+		},
+		Response: &RecordedResponse{
+			Status:     response.StatusCode,
+			StatusText: strings.TrimPrefix(response.Status, strconv.Itoa(response.StatusCode)+` `),
+			Proto:      response.Proto,
+			Headers:    self.redactHeaders(response.Header),
+		},
+		Started:  started,
+		Duration: time.Since(started),
+	}
+
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeRecordedBody(
+		redactBody(req.Header.Get(`Content-Type`), body),
+	)
+
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeRecordedBody(
+		redactBody(response.Header.Get(`Content-Type`), data),
+	)
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	self.cassette.Interactions = append(self.cassette.Interactions, interaction)
+	self.count += 1
+
+	log.Debugf("friendscript/http: recorded %v %v to %v", req.Method, req.URL, self.path)
+
+	return response, self.save()
+}
+
+// respond to the given request with a recorded response
+func (self *recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	var found = -1
+	var repeat = -1
+
+	// responses are replayed in the order they were recorded, then (if allowed) from the start again
+	for i, interaction := range self.cassette.Interactions {
+		if !self.matches(interaction.Request, req, body) {
+			continue
+		} else if !self.played[i] {
+			found = i
+			break
+		} else if self.repeat && repeat < 0 {
+			repeat = i
+		}
+	}
+
+	if found < 0 {
+		found = repeat
+	}
+
+	if found < 0 {
+		return nil, fmt.Errorf("no request recorded in %v matches %v %v", self.path, req.Method, req.URL)
+	}
+
+	var recorded = self.cassette.Interactions[found].Response
+	var header = make(http.Header)
+	var proto = recorded.Proto
+	var statusText = recorded.StatusText
+
+	self.played[found] = true
+	self.count += 1
+
+	data, err := decodeRecordedBody(recorded.Body, recorded.BodyEncoding)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid response body in %v: %v", self.path, err)
+	}
+
+	for name, values := range recorded.Headers {
+		header[http.CanonicalHeaderKey(name)] = append([]string{}, values...)
+	}
+
+	if proto == `` {
+		proto = `HTTP/1.1`
+	}
+
+	if statusText == `` {
+		statusText = http.StatusText(recorded.Status)
+	}
+
+	major, minor, _ := http.ParseHTTPVersion(proto)
+
+	log.Debugf("friendscript/http: replaying %v %v from %v", req.Method, req.URL, self.path)
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, statusText),
+		StatusCode:    recorded.Status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// report whether the given request matches a recorded one
+func (self *recorder) matches(recorded *RecordedRequest, req *http.Request, body []byte) bool {
+	recordedURL, err := url.Parse(recorded.URL)
+
+	if err != nil {
+		return false
+	}
+
+	for _, matcher := range self.matchers {
+		var name, header = stringutil.SplitPair(strings.ToLower(strings.TrimSpace(matcher)), `:`)
+		var matched bool
+
+		switch name {
+		case `method`:
+			matched = strings.EqualFold(recorded.Method, req.Method)
+		case `url`:
+			matched = strings.EqualFold(recordedURL.Scheme, req.URL.Scheme) &&
+				strings.EqualFold(recordedURL.Host, req.URL.Host) &&
+				recordedURL.Path == req.URL.Path &&
+				recordedURL.Query().Encode() == req.URL.Query().Encode()
+		case `scheme`:
+			matched = strings.EqualFold(recordedURL.Scheme, req.URL.Scheme)
+		case `host`:
+			matched = strings.EqualFold(recordedURL.Host, req.URL.Host)
+		case `path`:
+			matched = (recordedURL.Path == req.URL.Path)
+		case `query`:
+			matched = (recordedURL.Query().Encode() == req.URL.Query().Encode())
+		case `body`:
+			// the request is redacted the same way the recorded one was before comparing them
+			if data, err := decodeRecordedBody(recorded.Body, recorded.BodyEncoding); err == nil {
+				matched = sameBody(
+					http.Header(recorded.Headers).Get(`Content-Type`), data,
+					req.Header.Get(`Content-Type`), redactBody(req.Header.Get(`Content-Type`), body),
+				)
+			}
+		case `header`:
+			var values = http.Header(recorded.Headers).Values(header)
+			matched = (strings.Join(values, `, `) == strings.Join(req.Header.Values(header), `, `))
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Report whether two request bodies are the same.  Multipart bodies are compared without their
+// (randomly generated) boundaries, and JSON bodies are compared by value.
+func sameBody(recordedType string, recorded []byte, actualType string, actual []byte) bool {
+	recorded = withoutBoundary(recordedType, recorded)
+	actual = withoutBoundary(actualType, actual)
+
+	if bytes.Equal(recorded, actual) {
+		return true
+	} else if json.Valid(recorded) && json.Valid(actual) {
+		var a, b interface{}
+
+		json.Unmarshal(recorded, &a)
+		json.Unmarshal(actual, &b)
+
+		return reflect.DeepEqual(a, b)
+	}
+
+	return false
+}
+
+func withoutBoundary(contentType string, body []byte) []byte {
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil {
+		if boundary := params[`boundary`]; boundary != `` && strings.HasPrefix(mediaType, `multipart/`) {
+			return bytes.ReplaceAll(body, []byte(boundary), nil)
+		}
+	}
+
+	return body
+}
+
+// copy the given headers, replacing the values of those that match a redaction rule
+func (self *recorder) redactHeaders(header http.Header) map[string][]string {
+	var out = make(map[string][]string)
+
+	for name, values := range header {
+		out[name] = append([]string{}, values...)
+
+		for _, pattern := range self.redact {
+			if ok, _ := matchHeaderName(pattern, name); ok {
+				for i, value := range out[name] {
+					out[name][i] = redactHeaderValue(name, value)
+				}
+
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+// cookies keep their names and attributes so that they can still be replayed, e.g.:
+// "session=abc123; Path=/" is recorded as "session=[REDACTED]; Path=/"
+func redactHeaderValue(name string, value string) string {
+	if http.CanonicalHeaderKey(name) == `Set-Cookie` {
+		if i := strings.Index(value, `=`); i > 0 {
+			var attributes string
+
+			if j := strings.Index(value, `;`); j > i {
+				attributes = value[j:]
+			}
+
+			return value[:i+1] + RedactedValue + attributes
+		}
+	}
+
+	return RedactedValue
+}
+
+// replace the values of fields in DefaultRedactedBodyFields in form-encoded and JSON object bodies
+func redactBody(contentType string, body []byte) []byte {
+	var mediaType, _, _ = mime.ParseMediaType(contentType)
+
+	switch {
+	case mediaType == `application/x-www-form-urlencoded`:
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			var redacted bool
+
+			for _, field := range DefaultRedactedBodyFields {
+				if _, ok := form[field]; ok {
+					form.Set(field, RedactedValue)
+					redacted = true
+				}
+			}
+
+			if redacted {
+				return []byte(form.Encode())
+			}
+		}
+
+	case mediaType == `application/json` || strings.HasSuffix(mediaType, `+json`):
+		var object map[string]interface{}
+
+		if err := json.Unmarshal(body, &object); err == nil {
+			var redacted bool
+
+			for _, field := range DefaultRedactedBodyFields {
+				if _, ok := object[field]; ok {
+					object[field] = RedactedValue
+					redacted = true
+				}
+			}
+
+			if redacted {
+				if data, err := json.Marshal(object); err == nil {
+					return data
+				}
+			}
+		}
+	}
+
+	return body
+}
+
+func matchHeaderName(pattern string, name string) (bool, error) {
+	return path.Match(strings.ToLower(strings.TrimSpace(pattern)), strings.ToLower(name))
+}
+
+// write the cassette to its file (the caller must hold the recorder's lock)
+func (self *recorder) save() error {
+	if data, err := marshalCassette(self.path, self.cassette); err == nil {
+		return self.commands.writeFile(self.path, data)
+	} else {
+		return err
+	}
+}
+
+// cassettes are YAML files if their names say so, and JSON files otherwise
+func isYAMLCassette(filename string) bool {
+	switch strings.ToLower(path.Ext(filename)) {
+	case `.yaml`, `.yml`:
+		return true
+	default:
+		return false
+	}
+}
+
+func marshalCassette(filename string, cassette *Cassette) ([]byte, error) {
+	if isYAMLCassette(filename) {
+		return yaml.Marshal(cassette)
+	} else {
+		return json.MarshalIndent(cassette, ``, `  `)
+	}
+}
+
+func unmarshalCassette(filename string, data []byte, cassette *Cassette) error {
+	if isYAMLCassette(filename) {
+		return yaml.Unmarshal(data, cassette)
+	} else {
+		return json.Unmarshal(data, cassette)
+	}
+}
+
+// bodies are stored as text where possible, and as base64 otherwise
+func encodeRecordedBody(data []byte) (string, string) {
+	if utf8.Valid(data) {
+		return string(data), ``
+	} else {
+		return base64.StdEncoding.EncodeToString(data), `base64`
+	}
+}
+
+func decodeRecordedBody(body string, encoding string) ([]byte, error) {
+	switch encoding {
+	case ``:
+		return []byte(body), nil
+	case `base64`:
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("unknown body encoding %q", encoding)
+	}
+}
@@ -0,0 +1,359 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"strings"
+
+	"github.com/PerformLine/go-stockutil/log"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"gopkg.in/yaml.v2"
+)
+
+// The formats that response bodies can be decoded from, which can be given as the ResponseType
+// instead of a MIME type.
+const (
+	FormatText   = `text`
+	FormatJSON   = `json`
+	FormatXML    = `xml`
+	FormatYAML   = `yaml`
+	FormatForm   = `form`
+	FormatCSV    = `csv`
+	FormatTSV    = `tsv`
+	FormatNDJSON = `ndjson`
+)
+
+// MIME types (other than those with a +json, +xml, or +yaml suffix) and the format they are decoded as.
+var mediaTypeFormats = map[string]string{
+	`application/json`:                  FormatJSON,
+	`text/json`:                         FormatJSON,
+	`application/xml`:                   FormatXML,
+	`text/xml`:                          FormatXML,
+	`application/yaml`:                  FormatYAML,
+	`application/x-yaml`:                FormatYAML,
+	`text/yaml`:                         FormatYAML,
+	`text/x-yaml`:                       FormatYAML,
+	`application/x-www-form-urlencoded`: FormatForm,
+	`text/csv`:                          FormatCSV,
+	`application/csv`:                   FormatCSV,
+	`text/tab-separated-values`:         FormatTSV,
+	`application/x-ndjson`:              FormatNDJSON,
+	`application/ndjson`:                FormatNDJSON,
+	`application/jsonl`:                 FormatNDJSON,
+	`application/x-jsonlines`:           FormatNDJSON,
+}
+
+// Return the lowercased media type and charset (if any) from a Content-Type header value.
+func parseContentType(value string) (string, string) {
+	if value == `` {
+		return ``, ``
+	}
+
+	if mediaType, params, err := mime.ParseMediaType(value); err == nil {
+		return mediaType, strings.ToLower(params[`charset`])
+	} else {
+		mediaType, _ := stringutil.SplitPair(value, `;`)
+		return strings.ToLower(strings.TrimSpace(mediaType)), ``
+	}
+}
+
+// Return the format that a body of the given media type should be decoded as, or an empty string
+// if it should be left as-is.
+func mediaTypeFormat(mediaType string) string {
+	if format, ok := mediaTypeFormats[mediaType]; ok {
+		return format
+	}
+
+	switch {
+	case strings.HasSuffix(mediaType, `+json`):
+		return FormatJSON
+	case strings.HasSuffix(mediaType, `+xml`):
+		return FormatXML
+	case strings.HasSuffix(mediaType, `+yaml`):
+		return FormatYAML
+	case strings.HasPrefix(mediaType, `text/`):
+		return FormatText
+	}
+
+	return ``
+}
+
+// Decode a response body according to its Content-Type header (or the given response type, which
+// is either a format name or a MIME type that overrides the header.)  Text in a charset other than
+// UTF-8 is converted to UTF-8 first.
+func decodeResponseBody(data []byte, contentType string, responseType string) (interface{}, error) {
+	var mediaType, charset = parseContentType(contentType)
+	var format string
+	var transcoded bool
+
+	if strings.Contains(responseType, `/`) {
+		var rtCharset string
+
+		if mediaType, rtCharset = parseContentType(responseType); rtCharset != `` {
+			charset = rtCharset
+		}
+	} else if responseType != `` {
+		format = strings.ToLower(responseType)
+	}
+
+	if format == `` {
+		format = mediaTypeFormat(mediaType)
+	}
+
+	if charset != `` {
+		if text, err := transcode(data, charset); err == nil {
+			data = text
+			transcoded = true
+		} else {
+			log.Debugf("friendscript/http: <- not transcoding body: %v", err)
+		}
+	}
+
+	switch format {
+	case FormatJSON:
+		var out interface{}
+
+		if err := json.Unmarshal(data, &out); err == nil {
+			return out, nil
+		} else {
+			return nil, err
+		}
+
+	case FormatXML:
+		return decodeXML(data, transcoded)
+
+	case FormatYAML:
+		var out interface{}
+
+		if err := yaml.Unmarshal(data, &out); err == nil {
+			return yamlToNative(out), nil
+		} else {
+			return nil, err
+		}
+
+	case FormatForm:
+		if values, err := url.ParseQuery(string(bytes.TrimSpace(data))); err == nil {
+			var out = make(map[string]interface{})
+
+			for key, vs := range values {
+				if len(vs) == 1 {
+					out[key] = vs[0]
+				} else {
+					out[key] = sliceutil.Sliceify(vs)
+				}
+			}
+
+			return out, nil
+		} else {
+			return nil, err
+		}
+
+	case FormatCSV:
+		return decodeCSV(data, ',')
+
+	case FormatTSV:
+		return decodeCSV(data, '\t')
+
+	case FormatNDJSON:
+		var out = make([]interface{}, 0)
+		var decoder = json.NewDecoder(bytes.NewReader(data))
+
+		for {
+			var value interface{}
+
+			if err := decoder.Decode(&value); err == nil {
+				out = append(out, value)
+			} else if err == io.EOF {
+				return out, nil
+			} else {
+				return nil, err
+			}
+		}
+
+	case FormatText, ``:
+		return string(data), nil
+
+	default:
+		return nil, fmt.Errorf("Unknown response type %q", responseType)
+	}
+}
+
+// Decode CSV data into a list of objects, using the first row as the keys.
+func decodeCSV(data []byte, delimiter rune) (interface{}, error) {
+	var reader = csv.NewReader(bytes.NewReader(data))
+	var out = make([]interface{}, 0)
+
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	if rows, err := reader.ReadAll(); err == nil {
+		if len(rows) == 0 {
+			return out, nil
+		}
+
+		var header = rows[0]
+
+		for _, row := range rows[1:] {
+			var record = make(map[string]interface{})
+
+			for i, key := range header {
+				if i < len(row) {
+					record[key] = row[i]
+				} else {
+					record[key] = ``
+				}
+			}
+
+			out = append(out, record)
+		}
+
+		return out, nil
+	} else {
+		return nil, err
+	}
+}
+
+// Decode an XML document into an object keyed on the name of the root element.  Elements that
+// only contain text become strings; all others become objects whose keys are the names of child
+// elements (which become arrays when repeated), attributes (prefixed with "@"), and any text
+// content (as "#text".)
+func decodeXML(data []byte, transcoded bool) (interface{}, error) {
+	var decoder = xml.NewDecoder(bytes.NewReader(data))
+
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if transcoded {
+			return input, nil
+		} else if data, err := ioutil.ReadAll(input); err == nil {
+			if text, err := transcode(data, label); err == nil {
+				return bytes.NewReader(text), nil
+			} else {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	for {
+		if token, err := decoder.Token(); err == nil {
+			if start, ok := token.(xml.StartElement); ok {
+				if value, err := decodeXMLElement(decoder, start); err == nil {
+					return map[string]interface{}{
+						start.Name.Local: value,
+					}, nil
+				} else {
+					return nil, err
+				}
+			}
+		} else if err == io.EOF {
+			return nil, fmt.Errorf("XML document has no root element")
+		} else {
+			return nil, err
+		}
+	}
+}
+
+func decodeXMLElement(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	var out = make(map[string]interface{})
+	var text strings.Builder
+
+	for _, attr := range start.Attr {
+		if attr.Name.Space == `xmlns` || attr.Name.Local == `xmlns` {
+			continue
+		}
+
+		out[`@`+attr.Name.Local] = attr.Value
+	}
+
+	for {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if child, err := decodeXMLElement(decoder, t); err == nil {
+				var key = t.Name.Local
+
+				if existing, ok := out[key]; !ok {
+					out[key] = child
+				} else if list, ok := existing.([]interface{}); ok {
+					out[key] = append(list, child)
+				} else {
+					out[key] = []interface{}{existing, child}
+				}
+			} else {
+				return nil, err
+			}
+
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.EndElement:
+			var content = strings.TrimSpace(text.String())
+
+			if len(out) == 0 {
+				return content, nil
+			} else if content != `` {
+				out[`#text`] = content
+			}
+
+			return out, nil
+		}
+	}
+}
+
+// YAML objects decode with interface{} keys, which are converted to strings here.
+func yamlToNative(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		var out = make(map[string]interface{})
+
+		for key, item := range v {
+			out[typeutil.String(key)] = yamlToNative(item)
+		}
+
+		return out
+
+	case []interface{}:
+		for i, item := range v {
+			v[i] = yamlToNative(item)
+		}
+
+		return v
+	}
+
+	return value
+}
+
+// Convert text in the given charset to UTF-8.
+func transcode(data []byte, charset string) ([]byte, error) {
+	encoding, err := htmlindex.Get(strings.TrimSpace(charset))
+
+	if err != nil {
+		return nil, fmt.Errorf("unsupported charset %q", charset)
+	}
+
+	// a byte order mark takes precedence over the charset name
+	var decoder = unicode.BOMOverride(encoding.NewDecoder())
+
+	if text, _, err := transform.Bytes(decoder, data); err == nil {
+		return text, nil
+	} else {
+		return nil, fmt.Errorf("invalid %v text: %v", charset, err)
+	}
+}
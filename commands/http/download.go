@@ -0,0 +1,410 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/log"
+)
+
+type DownloadArgs struct {
+	// The path to write the response body to.
+	To string `json:"to"`
+
+	// If the response body is larger than this many bytes, the download is aborted.
+	MaxSize int64 `json:"max_size"`
+
+	// If given, the downloaded file must have this SHA-256 checksum (as a hex string).
+	SHA256 string `json:"sha256"`
+
+	// If given, the downloaded file must have this SHA-1 checksum (as a hex string).
+	SHA1 string `json:"sha1"`
+
+	// If given, the downloaded file must have this MD5 checksum (as a hex string).
+	MD5 string `json:"md5"`
+
+	// If the destination is a partially-downloaded local file, only request the rest of it.  Partial
+	// files are also kept if the download fails so that it can be resumed later.  Destinations that
+	// the runtime provides a writer for can't be resumed, and are always downloaded in full.
+	Resume bool `json:"resume"`
+
+	// Headers that will be sent with the request.
+	Headers map[string]interface{} `json:"headers"`
+
+	// Query string parameters that will be added to the URL.
+	Params map[string]interface{} `json:"params"`
+
+	// The name of a session (created with http::session) to make the request with.
+	Session string `json:"session"`
+
+	// Credentials to authenticate the request with.
+	Auth *AuthArgs `json:"auth"`
+
+	// The amount of time to wait for the whole download to complete.  Unlike other requests, downloads
+	// have no time limit unless one is given here (the timeout set with http::defaults doesn't apply.)
+	Timeout time.Duration `json:"timeout"`
+
+	// The amount of time to wait for a connection to be established.
+	ConnectTimeout time.Duration `json:"connect_timeout"`
+
+	// The number of times to retry the request if it fails to connect or returns a retryable status.
+	Retries *int `json:"retries"`
+}
+
+type DownloadResponse struct {
+	// The path the response body was written to.
+	Path string `json:"path"`
+
+	// The numeric HTTP status code of the response.
+	Status int `json:"status"`
+
+	// The MIME type of the downloaded file (if known).
+	ContentType string `json:"type"`
+
+	// The number of bytes written by this download.
+	Bytes int64 `json:"bytes"`
+
+	// The total size of the downloaded file in bytes (larger than Bytes if the download was resumed.)
+	Size int64 `json:"size"`
+
+	// Whether an existing partial download was continued.
+	Resumed bool `json:"resumed"`
+
+	// The SHA-256 checksum of the downloaded file (as a hex string).
+	SHA256 string `json:"sha256"`
+
+	// The time (in milliseconds) from sending the request until the download completed.
+	Took int64 `json:"took"`
+
+	// How long each phase of the download took.
+	Timing *Timing `json:"timing"`
+
+	// The URL that was downloaded (after following any redirects.)
+	URL string `json:"url"`
+
+	// The number of times the request was made (more than 1 if it was retried.)
+	Attempts int `json:"attempts"`
+}
+
+// Download the given URL to a file.  The response body is written as it is received rather than
+// being held in memory, and can be limited in size, verified against a checksum, and resumed.
+func (self *Commands) Download(url string, args *DownloadArgs) (*DownloadResponse, error) {
+	if args == nil || args.To == `` {
+		return nil, fmt.Errorf("a destination path must be specified with 'to'")
+	}
+
+	var reqargs = &RequestArgs{
+		Headers:        make(map[string]interface{}),
+		Params:         args.Params,
+		Session:        args.Session,
+		Auth:           args.Auth,
+		Timeout:        args.Timeout,
+		ConnectTimeout: args.ConnectTimeout,
+		Retries:        args.Retries,
+	}
+
+	// a download can take arbitrarily long, so it isn't limited by the default timeout
+	if reqargs.Timeout <= 0 {
+		reqargs.Timeout = noTimeout
+	}
+
+	for k, v := range args.Headers {
+		reqargs.Headers[k] = v
+	}
+
+	// the destination is resolved once, so that the file that is resumed is the one that is written
+	path, w, err := self.downloadWriter(args.To)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// a writer supplied by the runtime is closed here unless the download gets as far as writing to it
+	defer func() {
+		if closer, ok := w.(io.Closer); ok {
+			closer.Close()
+		}
+	}()
+
+	var local = (w == nil)
+
+	// see how much of the file we already have
+	var offset int64
+
+	if args.Resume {
+		if !local {
+			log.Debugf("friendscript/http: cannot resume download to %v, downloading all of it", path)
+		} else if stat, err := os.Stat(path); err == nil && stat.Mode().IsRegular() {
+			offset = stat.Size()
+		}
+
+		if offset > 0 {
+			reqargs.Headers[`Range`] = fmt.Sprintf("bytes=%d-", offset)
+		}
+	}
+
+	var out = &DownloadResponse{
+		Path: path,
+	}
+
+	ex, err := self.send(`GET`, url, reqargs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the server can't resume from the end of our partial file; unless that's because we already
+	// have all of it, the local file doesn't match the remote one and we have to start over
+	if offset > 0 && ex.response.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		if length, ok := contentRangeLength(ex.response.Header.Get(`Content-Range`)); !ok || length != offset {
+			log.Debugf("friendscript/http: cannot resume download to %v from byte %d, restarting", path, offset)
+
+			ex.response.Body.Close()
+			delete(reqargs.Headers, `Range`)
+			offset = 0
+
+			if ex, err = self.send(`GET`, url, reqargs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var response = ex.response
+	var transferStart = time.Now()
+
+	defer response.Body.Close()
+
+	reqargs = ex.reqargs
+	out.Status = response.StatusCode
+	out.Attempts = ex.attempts
+	out.URL = response.Request.URL.String()
+	out.ContentType, _ = parseContentType(response.Header.Get(`Content-Type`))
+
+	switch response.StatusCode {
+	case http.StatusPartialContent:
+		if offset == 0 {
+			return nil, fmt.Errorf("server sent a partial response that was not requested")
+		} else if first, ok := contentRangeStart(response.Header.Get(`Content-Range`)); !ok || first != offset {
+			return nil, fmt.Errorf("server resumed the download at the wrong offset (Content-Range: %s)", response.Header.Get(`Content-Range`))
+		}
+
+		out.Resumed = true
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// we already have the whole file, so there is nothing left to download
+		if offset > 0 {
+			out.Resumed = true
+			out.Size = offset
+			out.Timing = ex.tracer.finish(transferStart)
+			out.Took = int64(out.Timing.Total / time.Millisecond)
+
+			return out, self.verifyDownload(args, out, nil)
+		}
+
+		fallthrough
+
+	default:
+		if isErrorStatus(response.StatusCode, reqargs.Statuses) {
+			return nil, fmt.Errorf("HTTP %v", response.Status)
+		}
+
+		// the server sent the whole file, so start over
+		offset = 0
+	}
+
+	if args.MaxSize > 0 && response.ContentLength > 0 && offset+response.ContentLength > args.MaxSize {
+		return nil, fmt.Errorf("download is larger than the maximum size of %d bytes", args.MaxSize)
+	}
+
+	var checksums = newDownloadChecksums()
+
+	// the checksums cover the whole file, including the part that was already downloaded
+	if offset > 0 {
+		if existing, err := os.Open(path); err == nil {
+			_, err = io.CopyN(checksums, existing, offset)
+			existing.Close()
+
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	if local {
+		if file, err := openDownload(path, offset); err == nil {
+			w = file
+		} else {
+			return nil, err
+		}
+	}
+
+	// the body is limited to one more byte than is allowed so we can tell whether it was too large
+	var body io.Reader = response.Body
+
+	if args.MaxSize > 0 {
+		body = io.LimitReader(body, args.MaxSize-offset+1)
+	}
+
+	log.Debugf("friendscript/http: <- downloading to %v (from byte %d)", path, offset)
+
+	written, err := io.Copy(io.MultiWriter(w, checksums), body)
+
+	if closer, ok := w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	w = nil
+
+	out.Bytes = written
+	out.Size = offset + written
+	out.SHA256 = hex.EncodeToString(checksums.sha256.Sum(nil))
+	out.Timing = ex.tracer.finish(transferStart)
+	out.Took = int64(out.Timing.Total / time.Millisecond)
+
+	if err == nil && args.MaxSize > 0 && out.Size > args.MaxSize {
+		err = fmt.Errorf("download is larger than the maximum size of %d bytes", args.MaxSize)
+	} else if err == nil {
+		err = self.verifyDownload(args, out, checksums)
+
+		if err == nil {
+			return out, nil
+		}
+	} else if args.Resume {
+		// keep what we have so that the download can be resumed
+		return nil, err
+	}
+
+	// don't leave incomplete or invalid files behind
+	if local {
+		os.Remove(path)
+	}
+
+	return nil, err
+}
+
+// return the (possibly rewritten) path of a download's destination, along with the writer the runtime
+// provides for it; if there is no writer, the destination is a local file
+func (self *Commands) downloadWriter(path string) (string, io.Writer, error) {
+	if self.env != nil {
+		if p, w, err := self.env.GetWriterForPath(path); err == nil {
+			if w != nil {
+				return p, w, nil
+			}
+		} else {
+			return ``, nil, err
+		}
+	}
+
+	return path, nil, nil
+}
+
+// open a local file to download to, appending to it if we're resuming from the given offset
+func openDownload(path string, offset int64) (*os.File, error) {
+	var flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+
+	return os.OpenFile(path, flags, 0644)
+}
+
+// compare the checksums of a completed download against the expected ones (if any)
+func (self *Commands) verifyDownload(args *DownloadArgs, out *DownloadResponse, checksums *downloadChecksums) error {
+	// downloads that were already complete are checksummed by reading the file back
+	if checksums == nil {
+		checksums = newDownloadChecksums()
+
+		if file, err := os.Open(out.Path); err == nil {
+			_, err = io.Copy(checksums, file)
+			file.Close()
+
+			if err != nil {
+				return err
+			}
+		} else {
+			return err
+		}
+
+		out.SHA256 = hex.EncodeToString(checksums.sha256.Sum(nil))
+	}
+
+	for _, check := range []struct {
+		name     string
+		expected string
+		hash     hash.Hash
+	}{
+		{`SHA-256`, args.SHA256, checksums.sha256},
+		{`SHA-1`, args.SHA1, checksums.sha1},
+		{`MD5`, args.MD5, checksums.md5},
+	} {
+		if check.expected == `` {
+			continue
+		}
+
+		if actual := hex.EncodeToString(check.hash.Sum(nil)); !strings.EqualFold(actual, strings.TrimSpace(check.expected)) {
+			return fmt.Errorf("%s checksum mismatch: expected %s, got %s", check.name, check.expected, actual)
+		}
+	}
+
+	return nil
+}
+
+type downloadChecksums struct {
+	io.Writer
+	sha256 hash.Hash
+	sha1   hash.Hash
+	md5    hash.Hash
+}
+
+func newDownloadChecksums() *downloadChecksums {
+	var checksums = &downloadChecksums{
+		sha256: sha256.New(),
+		sha1:   sha1.New(),
+		md5:    md5.New(),
+	}
+
+	checksums.Writer = io.MultiWriter(checksums.sha256, checksums.sha1, checksums.md5)
+	return checksums
+}
+
+// return the first byte position of a "Content-Range: bytes first-last/length" header
+func contentRangeStart(value string) (int64, bool) {
+	var first int64
+
+	if !strings.HasPrefix(value, `bytes `) {
+		return 0, false
+	} else if _, err := fmt.Sscanf(strings.TrimPrefix(value, `bytes `), "%d-", &first); err == nil {
+		return first, true
+	}
+
+	return 0, false
+}
+
+// return the complete length from a "Content-Range: bytes first-last/length" (or "bytes */length") header
+func contentRangeLength(value string) (int64, bool) {
+	var length int64
+
+	if !strings.HasPrefix(value, `bytes `) {
+		return 0, false
+	} else if i := strings.LastIndex(value, `/`); i < 0 {
+		return 0, false
+	} else if _, err := fmt.Sscanf(value[i+1:], "%d", &length); err == nil {
+		return length, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+// The version of the HAR format that is exported.
+const HarVersion = `1.2`
+
+// An HTTP Archive (see http://www.softwareishard.com/blog/har-12-spec/), which can be loaded into
+// browser developer tools and other HTTP debugging tools.
+type Har struct {
+	Log *HarLog `json:"log"`
+}
+
+// Return the archive as it would be written to a file, so that scripts can work with the whole thing.
+func (self *Har) ToMap() map[string]interface{} {
+	var out = make(map[string]interface{})
+
+	if data, err := json.Marshal(self); err == nil {
+		json.Unmarshal(data, &out)
+	}
+
+	return out
+}
+
+type HarLog struct {
+	Version string      `json:"version"`
+	Creator *HarCreator `json:"creator"`
+	Entries []*HarEntry `json:"entries"`
+}
+
+type HarCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HarEntry struct {
+	StartedDateTime string                 `json:"startedDateTime"`
+	Time            float64                `json:"time"`
+	Request         *HarRequest            `json:"request"`
+	Response        *HarResponse           `json:"response"`
+	Cache           map[string]interface{} `json:"cache"`
+	Timings         *HarTimings            `json:"timings"`
+}
+
+type HarRequest struct {
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HarCookie    `json:"cookies"`
+	Headers     []*HarNameValue `json:"headers"`
+	QueryString []*HarNameValue `json:"queryString"`
+	PostData    *HarPostData    `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type HarResponse struct {
+	Status      int             `json:"status"`
+	StatusText  string          `json:"statusText"`
+	HTTPVersion string          `json:"httpVersion"`
+	Cookies     []*HarCookie    `json:"cookies"`
+	Headers     []*HarNameValue `json:"headers"`
+	Content     *HarContent     `json:"content"`
+	RedirectURL string          `json:"redirectURL"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+}
+
+type HarNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HarCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Path     string `json:"path,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Expires  string `json:"expires,omitempty"`
+	HttpOnly bool   `json:"httpOnly,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+}
+
+type HarPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HarContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HarTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type ExportHarArgs struct {
+	// If given, the HAR will be written to this file as JSON.
+	Path string `json:"path"`
+
+	// Export the requests in this cassette file instead of the one currently being recorded or
+	// replayed.
+	Cassette string `json:"cassette"`
+}
+
+// Return the requests in a cassette as an HTTP Archive (HAR), optionally writing it to a file.
+func (self *Commands) ExportHar(args *ExportHarArgs) (*Har, error) {
+	if args == nil {
+		args = &ExportHarArgs{}
+	}
+
+	var cassette = new(Cassette)
+
+	if args.Cassette != `` {
+		if data, err := self.readFile(args.Cassette); err == nil {
+			if err := unmarshalCassette(args.Cassette, data, cassette); err != nil {
+				return nil, fmt.Errorf("invalid cassette %v: %v", args.Cassette, err)
+			}
+		} else {
+			return nil, err
+		}
+	} else {
+		self.lock.Lock()
+		var rec = self.recorder
+		self.lock.Unlock()
+
+		if rec == nil {
+			return nil, fmt.Errorf("no cassette is being recorded or replayed")
+		}
+
+		rec.lock.Lock()
+		cassette.Interactions = append(cassette.Interactions, rec.cassette.Interactions...)
+		rec.lock.Unlock()
+	}
+
+	var har = &Har{
+		Log: &HarLog{
+			Version: HarVersion,
+			Creator: harCreator(),
+			Entries: make([]*HarEntry, 0, len(cassette.Interactions)),
+		},
+	}
+
+	for _, interaction := range cassette.Interactions {
+		if entry, err := interaction.harEntry(); err == nil {
+			har.Log.Entries = append(har.Log.Entries, entry)
+		} else {
+			return nil, err
+		}
+	}
+
+	if args.Path != `` {
+		if data, err := json.MarshalIndent(har, ``, `  `); err == nil {
+			if err := self.writeFile(args.Path, data); err != nil {
+				return nil, err
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	return har, nil
+}
+
+func (self *Interaction) harEntry() (*HarEntry, error) {
+	var request, response = self.Request, self.Response
+
+	if request == nil || response == nil {
+		return nil, fmt.Errorf("cassette contains an incomplete interaction")
+	}
+
+	u, err := url.Parse(request.URL)
+
+	if err != nil {
+		return nil, err
+	}
+
+	reqBody, err := decodeRecordedBody(request.Body, request.BodyEncoding)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := decodeRecordedBody(response.Body, response.BodyEncoding)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var reqHeader = http.Header(request.Headers)
+	var resHeader = http.Header(response.Headers)
+	var millis = float64(self.Duration) / float64(time.Millisecond)
+	var proto = response.Proto
+
+	if proto == `` {
+		proto = `HTTP/1.1`
+	}
+
+	var entry = &HarEntry{
+		StartedDateTime: self.Started.Format(time.RFC3339Nano),
+		Time:            millis,
+		Request: &HarRequest{
+			Method:      request.Method,
+			URL:         request.URL,
+			HTTPVersion: proto,
+			Cookies:     make([]*HarCookie, 0),
+			Headers:     harHeaders(reqHeader),
+			QueryString: harHeaders(http.Header(u.Query())),
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: &HarResponse{
+			Status:      response.Status,
+			StatusText:  response.StatusText,
+			HTTPVersion: proto,
+			Cookies:     make([]*HarCookie, 0),
+			Headers:     harHeaders(resHeader),
+			Content: &HarContent{
+				Size:     len(resBody),
+				MimeType: resHeader.Get(`Content-Type`),
+				Text:     response.Body,
+				Encoding: response.BodyEncoding,
+			},
+			RedirectURL: resHeader.Get(`Location`),
+			HeadersSize: -1,
+			BodySize:    len(resBody),
+		},
+		Cache: make(map[string]interface{}),
+		Timings: &HarTimings{
+			Wait: millis,
+		},
+	}
+
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &HarPostData{
+			MimeType: reqHeader.Get(`Content-Type`),
+			Text:     request.Body,
+		}
+	}
+
+	for _, c := range (&http.Request{Header: reqHeader}).Cookies() {
+		entry.Request.Cookies = append(entry.Request.Cookies, &HarCookie{
+			Name:  c.Name,
+			Value: c.Value,
+		})
+	}
+
+	for _, c := range (&http.Response{Header: resHeader}).Cookies() {
+		var cookie = &HarCookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Path:     c.Path,
+			Domain:   c.Domain,
+			HttpOnly: c.HttpOnly,
+			Secure:   c.Secure,
+		}
+
+		if !c.Expires.IsZero() {
+			cookie.Expires = c.Expires.Format(time.RFC3339)
+		}
+
+		entry.Response.Cookies = append(entry.Response.Cookies, cookie)
+	}
+
+	return entry, nil
+}
+
+// headers (and query strings) are listed in name order, with one entry per value
+func harHeaders(header http.Header) []*HarNameValue {
+	var out = make([]*HarNameValue, 0, len(header))
+	var names = make([]string, 0, len(header))
+
+	for name := range header {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, value := range header[name] {
+			out = append(out, &HarNameValue{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+
+	return out
+}
+
+// report this module as the creator of exported archives, with the version it was built from (if known)
+func harCreator() *HarCreator {
+	var creator = &HarCreator{
+		Name:    `friendscript`,
+		Version: `devel`,
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if info.Main.Path == `github.com/PerformLine/friendscript` && info.Main.Version != `(devel)` {
+			creator.Version = info.Main.Version
+		}
+
+		for _, dep := range info.Deps {
+			if dep.Path == `github.com/PerformLine/friendscript` {
+				creator.Version = dep.Version
+			}
+		}
+	}
+
+	return creator
+}
@@ -3,7 +3,7 @@ package http
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,22 +11,31 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/PerformLine/friendscript/utils"
 	"github.com/PerformLine/go-stockutil/httputil"
 	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/maputil"
-	"github.com/PerformLine/go-stockutil/sliceutil"
 	"github.com/PerformLine/go-stockutil/stringutil"
 	"github.com/PerformLine/go-stockutil/typeutil"
 	defaults "github.com/mcuadros/go-defaults"
 )
 
+// a Timeout that overrides any default with no time limit at all
+const noTimeout time.Duration = -1
+
 type Commands struct {
 	utils.Module
-	env      utils.Runtime
-	defaults RequestArgs
+	env        utils.Runtime
+	defaults   RequestArgs
+	sessions   map[string]*Session
+	transports map[transportKey]*http.Transport
+	tokens     map[string]*oauth2Token
+	digests    map[string]*digestChallenge
+	recorder   *recorder
+	lock       sync.Mutex
 }
 
 type RequestArgs struct {
@@ -45,10 +54,11 @@ type RequestArgs struct {
 	// The body of the request. This is processed according to what is specified in RequestType.
 	Body interface{} `json:"body"`
 
-	// The type of data in Body, specifying how it should be encoded.  Valid values are "raw", "form", and "json"
+	// The type of data in Body, specifying how it should be encoded.  Valid values are "raw", "form", "multipart", and "json"
 	RequestType string `json:"request_type,omitempty" default:"json"`
 
-	// Specify how the response body should be decoded.  Can be "raw", or a MIME type that overrides the Content-Type response header.
+	// Specify how the response body should be decoded.  Can be "raw", one of "text", "json", "xml", "yaml", "form", "csv",
+	// "tsv", or "ndjson", or a MIME type (optionally with a charset) that overrides the Content-Type response header.
 	ResponseType string `json:"response_type,omitempty"`
 
 	// Whether to disable TLS peer verification.
@@ -57,6 +67,38 @@ type RequestArgs struct {
 	// The path to the root TLS CA bundle to use for verifying peer certificates.
 	CertificateBundle string `json:"ca_bundle"`
 
+	// The path to a PEM-encoded client certificate to present to servers that require one (mutual TLS).
+	ClientCertificate string `json:"client_cert"`
+
+	// The path to the PEM-encoded private key for ClientCertificate (if it isn't in the same file.)
+	ClientKey string `json:"client_key"`
+
+	// The lowest TLS version that will be accepted ("1.0", "1.1", "1.2", or "1.3").
+	MinTLSVersion string `json:"min_tls_version"`
+
+	// A comma-separated list of the TLS cipher suites that may be used (e.g.:
+	// "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256").  This does not apply to TLS 1.3.
+	CipherSuites string `json:"ciphers"`
+
+	// The URL of an HTTP, HTTPS, or SOCKS5 proxy to send requests through, or "none" to connect
+	// directly.  If not given, the HTTP_PROXY and HTTPS_PROXY environment variables are used.
+	Proxy string `json:"proxy"`
+
+	// A comma-separated list of hosts, IP addresses, or CIDR ranges that should not be proxied.
+	// If not given, the NO_PROXY environment variable is used.
+	NoProxy string `json:"no_proxy"`
+
+	// A map of hostnames (or host:port pairs) to the address that should be connected to instead,
+	// e.g.: {"api.example.com": "127.0.0.1:8443"}.
+	Resolve map[string]interface{} `json:"resolve"`
+
+	// The path to a Unix socket that all connections are made to (the host in the URL is only
+	// used for the Host header.)
+	UnixSocket string `json:"unix_socket"`
+
+	// The amount of time to wait for a connection to be established.
+	ConnectTimeout time.Duration `json:"connect_timeout"`
+
 	// A comma-separated list of numbers (e.g.: 200) or inclusive number ranges (e.g. 200-399) specifying HTTP statuses that are
 	// expected and non-erroneous.
 	Statuses string `json:"statuses" default:"200-299"`
@@ -66,6 +108,45 @@ type RequestArgs struct {
 
 	// Specify that absolutely no processing should be done on the response body.
 	RawBody bool `json:"raw"`
+
+	// The name of a session (created with http::session) whose options and cookies should be used.
+	Session string `json:"session"`
+
+	// Credentials to authenticate the request with.
+	Auth *AuthArgs `json:"auth"`
+
+	// Whether to follow redirects (the default.)  If false, the redirect response is returned instead.
+	FollowRedirects *bool `json:"follow_redirects"`
+
+	// The most redirects that will be followed before giving up.
+	MaxRedirects int `json:"max_redirects" default:"10"`
+
+	// The number of times to retry a request that failed (in a way described by RetryOn or RetryStatuses.)
+	// A request can set this to 0 to disable retries that its session would otherwise make.
+	Retries *int `json:"retries"`
+
+	// A comma-separated list of numbers (e.g.: 503) or inclusive number ranges (e.g. 500-599) specifying
+	// HTTP statuses that should cause the request to be retried.
+	RetryStatuses string `json:"retry_statuses" default:"429,502-504"`
+
+	// A comma-separated list of the kinds of errors that should cause the request to be retried.  Valid
+	// values are "connection" (the connection could not be made or was lost) and "timeout".
+	RetryOn string `json:"retry_on" default:"connection,timeout"`
+
+	// How long to wait before the first retry.  This doubles for every subsequent retry.
+	RetryDelay time.Duration `json:"retry_delay" default:"500ms"`
+
+	// The longest amount of time to wait between retries.
+	RetryMaxDelay time.Duration `json:"retry_max_delay" default:"30s"`
+}
+
+// return the number of times a failed request should be retried (none unless specified)
+func (self *RequestArgs) retries() int {
+	if self.Retries != nil && *self.Retries > 0 {
+		return *self.Retries
+	}
+
+	return 0
 }
 
 func (self *RequestArgs) Merge(other *RequestArgs) *RequestArgs {
@@ -81,22 +162,106 @@ func (self *RequestArgs) Merge(other *RequestArgs) *RequestArgs {
 		Statuses:          self.Statuses,
 		ContinueOnError:   self.ContinueOnError,
 		CertificateBundle: self.CertificateBundle,
+		ClientCertificate: self.ClientCertificate,
+		ClientKey:         self.ClientKey,
+		MinTLSVersion:     self.MinTLSVersion,
+		CipherSuites:      self.CipherSuites,
+		Proxy:             self.Proxy,
+		NoProxy:           self.NoProxy,
+		Resolve:           self.Resolve,
+		UnixSocket:        self.UnixSocket,
+		ConnectTimeout:    self.ConnectTimeout,
 		RawBody:           self.RawBody,
+		Session:           self.Session,
+		Auth:              self.Auth,
+		FollowRedirects:   self.FollowRedirects,
+		MaxRedirects:      self.MaxRedirects,
+		Retries:           self.Retries,
+		RetryStatuses:     self.RetryStatuses,
+		RetryOn:           self.RetryOn,
+		RetryDelay:        self.RetryDelay,
+		RetryMaxDelay:     self.RetryMaxDelay,
 	}
 
 	if other != nil {
 		out.Headers, _ = maputil.Merge(out.Headers, other.Headers)
 		out.Params, _ = maputil.Merge(out.Params, other.Params)
 		out.Cookies, _ = maputil.Merge(out.Cookies, other.Cookies)
+		out.Resolve, _ = maputil.Merge(out.Resolve, other.Resolve)
 
 		if other.RawBody {
 			out.RawBody = true
 		}
 
+		if v := other.Session; v != `` {
+			out.Session = v
+		}
+
+		if v := other.Auth; v != nil {
+			out.Auth = v
+		}
+
+		if v := other.FollowRedirects; v != nil {
+			out.FollowRedirects = v
+		}
+
+		if v := other.MaxRedirects; v > 0 {
+			out.MaxRedirects = v
+		}
+
+		if v := other.Retries; v != nil {
+			out.Retries = v
+		}
+
+		if v := other.RetryStatuses; v != `` {
+			out.RetryStatuses = v
+		}
+
+		if v := other.RetryOn; v != `` {
+			out.RetryOn = v
+		}
+
+		if v := other.RetryDelay; v > 0 {
+			out.RetryDelay = v
+		}
+
+		if v := other.RetryMaxDelay; v > 0 {
+			out.RetryMaxDelay = v
+		}
+
 		if v := other.CertificateBundle; v != `` {
 			out.CertificateBundle = v
 		}
 
+		if v := other.ClientCertificate; v != `` {
+			out.ClientCertificate = v
+			out.ClientKey = other.ClientKey
+		}
+
+		if v := other.MinTLSVersion; v != `` {
+			out.MinTLSVersion = v
+		}
+
+		if v := other.CipherSuites; v != `` {
+			out.CipherSuites = v
+		}
+
+		if v := other.Proxy; v != `` {
+			out.Proxy = v
+		}
+
+		if v := other.NoProxy; v != `` {
+			out.NoProxy = v
+		}
+
+		if v := other.UnixSocket; v != `` {
+			out.UnixSocket = v
+		}
+
+		if v := other.ConnectTimeout; v > 0 {
+			out.ConnectTimeout = v
+		}
+
 		if v := other.RequestType; v != `` {
 			out.RequestType = v
 		}
@@ -117,7 +282,7 @@ func (self *RequestArgs) Merge(other *RequestArgs) *RequestArgs {
 			out.ContinueOnError = true
 		}
 
-		if v := other.Timeout; v > 0 {
+		if v := other.Timeout; v > 0 || v == noTimeout {
 			out.Timeout = v
 		}
 
@@ -139,6 +304,18 @@ type HttpResponse struct {
 	// The time (in millisecond) that the request took to complete.
 	Took int64 `json:"took"`
 
+	// How long each phase of the request took.
+	Timing *Timing `json:"timing"`
+
+	// The URL of the final response (after following any redirects.)
+	URL string `json:"url"`
+
+	// The responses that redirected the request (if any), in the order they were followed.
+	Redirects []*Redirect `json:"redirects"`
+
+	// The cookies set by the response (including any set by redirects.)
+	Cookies []*Cookie `json:"cookies"`
+
 	// Response headers sent back from the server.
 	Headers map[string]interface{} `json:"headers"`
 
@@ -153,6 +330,9 @@ type HttpResponse struct {
 
 	// If the response status is considered an error, and errors aren't fatal, this will be true.
 	Error bool `json:"error"`
+
+	// The number of times the request was made (more than 1 if it was retried.)
+	Attempts int `json:"attempts"`
 }
 
 func New(env utils.Runtime) *Commands {
@@ -160,8 +340,12 @@ func New(env utils.Runtime) *Commands {
 	defaults.SetDefaults(reqargs)
 
 	cmd := &Commands{
-		env:      env,
-		defaults: *reqargs,
+		env:        env,
+		defaults:   *reqargs,
+		sessions:   make(map[string]*Session),
+		transports: make(map[transportKey]*http.Transport),
+		tokens:     make(map[string]*oauth2Token),
+		digests:    make(map[string]*digestChallenge),
 	}
 
 	cmd.Module = utils.NewDefaultExecutor(cmd)
@@ -209,176 +393,354 @@ func (self *Commands) Head(url string, args *RequestArgs) (*HttpResponse, error)
 	return self.request(`HEAD`, url, args)
 }
 
+// requests are cancelled when the script they were made from is (e.g.: by an expiring timeout block)
+func (self *Commands) context() context.Context {
+	return utils.RuntimeContext(self.env)
+}
+
 func (self *Commands) request(method string, url string, args *RequestArgs) (*HttpResponse, error) {
-	// this is the bit that takes any defaults set via http::defaults and overlays the per-request values
-	var reqargs = self.defaults.Merge(args)
+	if ex, err := self.send(method, url, args); err == nil {
+		return buildResponse(ex)
+	} else {
+		return nil, err
+	}
+}
 
-	client := &http.Client{
-		Timeout: reqargs.Timeout,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: reqargs.DisableVerifySSL,
-			},
-		},
+// Perform a request (retrying it if necessary), returning the response from the last attempt along
+// with the options it was made with and how it went.
+func (self *Commands) send(method string, url string, args *RequestArgs) (*exchange, error) {
+	var base = &self.defaults
+	var session *Session
+
+	// session options take precedence over http::defaults, but not over the per-request values
+	if s, err := self.requestSession(args); err == nil && s != nil {
+		session = s
+		base = base.Merge(session.Defaults)
+	} else if err != nil {
+		return nil, err
 	}
 
-	// specify CA bundle (if provided)
+	// this is the bit that takes any defaults set via http::defaults and overlays the per-request values
+	var reqargs = base.Merge(args)
+
 	if ca := reqargs.CertificateBundle; ca != `` {
 		log.Debugf("friendscript/http: Using override CA bundle at %v", ca)
+	}
+
+	// connections are pooled by sharing a transport between all requests with the same TLS settings
+	transport, err := self.transport(reqargs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Timeout:   reqargs.Timeout,
+		Transport: self.roundTripper(transport),
+	}
+
+	if session != nil {
+		client.Jar = session.jar
+	}
 
-		if err := httputil.SetRootCABundle(client, ca); err != nil {
+	conditions, err := retryConditions(reqargs)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// encode the body (if any) in preparation for sending in the request
+	var body io.Reader
+	var contentType string
+	var reopen func() (io.ReadCloser, error)
+
+	if reqargs.RequestType == `multipart` && reqargs.Body != nil {
+		body, contentType, err = self.encodeMultipart(reqargs.Body, ``)
+		reopen = self.multipartReopener(reqargs.Body, contentType)
+	} else {
+		body, contentType, err = encodeBody(reqargs.RequestType, reqargs.Body)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// bodies are buffered so that they can be sent again if the request is retried (or signed).
+	// Multipart bodies are streamed again for each attempt instead, unless they have to be buffered
+	// to be signed or contain readers that can only be read once.
+	var bodyData []byte
+	var resend = reqargs.retries() > 0 || reqargs.Auth.needsBody()
+	var reauthenticated bool
+	var sent bool
+
+	if body != nil && (reqargs.Auth.signsBody() || resend && reopen == nil) {
+		data, err := ioutil.ReadAll(body)
+		closeBody(body)
+
+		if err == nil {
+			bodyData = data
+		} else {
 			return nil, err
 		}
 	}
 
-	// encode the body (if any) in preparation for sending in the request
-	if body, contentType, err := encodeBody(reqargs.RequestType, reqargs.Body); err == nil {
-		// get a new request
-		if req, err := http.NewRequest(method, url, body); err == nil {
-			// set query string parameters
-			if len(reqargs.Params) > 0 {
-				for k, v := range reqargs.Params {
-					httputil.SetQ(req.URL, k, v)
-				}
+	for attempt := 1; ; attempt++ {
+		if bodyData != nil {
+			body = bytes.NewReader(bodyData)
+		} else if reopen != nil && sent {
+			if body, err = reopen(); err != nil {
+				return nil, err
 			}
+		}
 
-			// set the body
-			if body != nil {
-				req.Body = ioutil.NopCloser(body)
-			}
+		req, err := self.newRequest(method, url, reqargs, base, args, body, contentType)
 
-			// get headers in place
-			for k, v := range self.defaults.Headers {
-				req.Header.Set(k, typeutil.String(v))
-			}
+		if err != nil {
+			closeBody(body)
+			return nil, err
+		} else if err := self.authenticate(req, reqargs, bodyData); err != nil {
+			closeBody(body)
+			return nil, fmt.Errorf("authentication failed: %v", err)
+		}
+
+		// lets redirects that have to resend the body (e.g.: 307) stream it again too
+		if bodyData == nil && reopen != nil {
+			req.GetBody = reopen
+		}
+
+		sent = true
+
+		var ex = &exchange{
+			reqargs:   reqargs,
+			attempts:  attempt,
+			tracer:    newTracer(),
+			redirects: make([]*Redirect, 0),
+			cookies:   make([]*Cookie, 0),
+		}
+
+		client.CheckRedirect = redirectPolicy(reqargs, ex)
+
+		// perform the request
+		response, err := client.Do(ex.tracer.trace(req))
+
+		// credentials that were rejected may just be stale (e.g.: an expired token or digest nonce), so
+		// get new ones and try again once (without counting it as a retry)
+		if err == nil && response.StatusCode == http.StatusUnauthorized && !reauthenticated && self.reauthenticate(req, reqargs, response) {
+			log.Debugf("friendscript/http: <- HTTP %v, reauthenticating", response.Status)
+
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+
+			reauthenticated = true
+			attempt -= 1
+			continue
+		}
+
+		// retry failed requests (if we're allowed to) after waiting a bit
+		if attempt <= reqargs.retries() && self.shouldRetry(reqargs, conditions, response, err) {
+			var delay = retryDelay(reqargs, attempt, response)
+
+			if err != nil {
+				log.Warningf("friendscript/http: %v %v failed (attempt %d/%d), retrying in %v: %v", method, url, attempt, reqargs.retries()+1, delay, err)
+			} else {
+				log.Warningf("friendscript/http: %v %v returned HTTP %v (attempt %d/%d), retrying in %v", method, url, response.Status, attempt, reqargs.retries()+1, delay)
 
-			// set content type detected during encoding
-			if contentType != `` {
-				req.Header.Set(`Content-Type`, contentType)
+				// the connection can only be reused once the body has been read
+				io.Copy(ioutil.Discard, response.Body)
+				response.Body.Close()
 			}
 
-			// set header overrides
-			if args != nil {
-				for k, v := range args.Headers {
-					req.Header.Set(k, typeutil.String(v))
-				}
+			select {
+			case <-time.After(delay):
+				continue
+			case <-self.context().Done():
+				return nil, self.context().Err()
 			}
+		}
 
-			start := time.Now()
+		if err == nil {
+			ex.response = response
+			ex.cookies = append(ex.cookies, responseCookies(response.Request.URL, response)...)
 
-			log.Debugf("friendscript/http: -> %v %v", req.Method, req.URL)
+			return ex, nil
+		} else {
+			log.Debugf("friendscript/http: <- Request failed: %v", err)
+			return nil, err
+		}
+	}
+}
 
-			if body != nil {
-				log.Debugf("friendscript/http: -> encoded body as %v (%v)", reqargs.RequestType, contentType)
+// streamed bodies (like multipart uploads) keep files open and a goroutine writing to them until
+// they're closed, which the client only does once it sends them
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// build a request for a single attempt at sending the given body
+func (self *Commands) newRequest(method string, url string, reqargs *RequestArgs, base *RequestArgs, args *RequestArgs, body io.Reader, contentType string) (*http.Request, error) {
+	// get a new request
+	if req, err := http.NewRequestWithContext(self.context(), method, url, body); err == nil {
+		// set query string parameters
+		if len(reqargs.Params) > 0 {
+			for k, v := range reqargs.Params {
+				httputil.SetQ(req.URL, k, v)
 			}
+		}
 
-			for k, vs := range req.Header {
-				log.Debugf("friendscript/http: -> [H] %v: %v", k, strings.Join(vs, `,`))
+		// set the body (closing it tells streamed bodies to stop if the request fails)
+		if rc, ok := body.(io.ReadCloser); ok {
+			req.Body = rc
+		} else if body != nil {
+			req.Body = ioutil.NopCloser(body)
+		}
+
+		// get headers in place
+		for k, v := range base.Headers {
+			req.Header.Set(k, typeutil.String(v))
+		}
+
+		// set content type detected during encoding
+		if contentType != `` {
+			req.Header.Set(`Content-Type`, contentType)
+		}
+
+		// set header overrides
+		if args != nil {
+			for k, v := range args.Headers {
+				req.Header.Set(k, typeutil.String(v))
 			}
+		}
 
-			// populate cookies
-			if len(reqargs.Cookies) > 0 {
-				for k, v := range reqargs.Cookies {
-					req.AddCookie(&http.Cookie{
-						Name:  k,
-						Value: typeutil.String(v),
-					})
+		log.Debugf("friendscript/http: -> %v %v", req.Method, req.URL)
 
-					log.Debugf("friendscript/http: -> [C] %v: %v", k, v)
-				}
+		if body != nil {
+			log.Debugf("friendscript/http: -> encoded body as %v (%v)", reqargs.RequestType, contentType)
+		}
+
+		for k, vs := range req.Header {
+			log.Debugf("friendscript/http: -> [H] %v: %v", k, strings.Join(vs, `,`))
+		}
+
+		// populate cookies
+		if len(reqargs.Cookies) > 0 {
+			for k, v := range reqargs.Cookies {
+				req.AddCookie(&http.Cookie{
+					Name:  k,
+					Value: typeutil.String(v),
+				})
+
+				log.Debugf("friendscript/http: -> [C] %v: %v", k, v)
 			}
+		}
 
-			// perform the request
-			if response, err := client.Do(req); err == nil {
-				// build the response
-				var res = &HttpResponse{
-					Status:     response.StatusCode,
-					StatusText: response.Status,
-					Headers:    make(map[string]interface{}),
-					Took:       int64(time.Since(start).Nanoseconds() / 1e6),
-				}
+		return req, nil
+	} else {
+		return nil, err
+	}
+}
 
-				log.Debugf("friendscript/http: <- HTTP %v (took %vms)", response.Status, res.Took)
+func buildResponse(ex *exchange) (*HttpResponse, error) {
+	var response = ex.response
+	var reqargs = ex.reqargs
+	var transferStart = time.Now()
+	var streaming bool
+
+	// unless the body is handed to the caller to read, it is read and closed here (however this
+	// returns) so that its connection can be reused
+	defer func() {
+		if response.Body != nil && !streaming {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+	}()
+
+	// build the response
+	var res = &HttpResponse{
+		Status:     response.StatusCode,
+		StatusText: response.Status,
+		Headers:    responseHeaders(response.Header),
+		Took:       int64(time.Since(ex.tracer.start).Nanoseconds() / 1e6),
+		URL:        response.Request.URL.String(),
+		Redirects:  ex.redirects,
+		Cookies:    ex.cookies,
+		Attempts:   ex.attempts,
+	}
 
-				// add (autotyped) headers
-				for k, vs := range response.Header {
-					log.Debugf("friendscript/http: <- [H] %v: %v", k, strings.Join(vs, `,`))
+	res.ContentType, _ = parseContentType(response.Header.Get(`Content-Type`))
 
-					if len(vs) == 1 {
-						res.Headers[k] = typeutil.Auto(vs[0])
-					} else {
-						res.Headers[k] = sliceutil.Autotype(vs)
-					}
-				}
+	log.Debugf("friendscript/http: <- HTTP %v (took %vms)", response.Status, res.Took)
 
-				if isErrorStatus(response.StatusCode, reqargs.Statuses) {
-					if reqargs.ContinueOnError {
-						res.Error = true
-					} else {
-						log.Debugf("friendscript/http: <- Request error: %v", response.Status)
-						return nil, fmt.Errorf("HTTP %v", response.Status)
-					}
-				}
+	for k, vs := range response.Header {
+		log.Debugf("friendscript/http: <- [H] %v: %v", k, strings.Join(vs, `,`))
+	}
 
-				// decode (i.e.: decompress) response
-				if response.ContentLength < 0 || response.ContentLength > 0 {
-					if decoded, err := httputil.DecodeResponse(response); err == nil {
-						if reqargs.RawBody {
-							res.Body = ioutil.NopCloser(decoded)
-							res.Length = 0
-						} else {
-							if response.Body != nil {
-								defer response.Body.Close()
-							}
+	if isErrorStatus(response.StatusCode, reqargs.Statuses) {
+		if reqargs.ContinueOnError {
+			res.Error = true
+		} else {
+			log.Debugf("friendscript/http: <- Request error: %v", response.Status)
+			return nil, fmt.Errorf("HTTP %v", response.Status)
+		}
+	}
 
-							if data, err := ioutil.ReadAll(decoded); err == nil {
-								res.Length = int64(len(data))
-								res.Body = string(data)
-
-								if res.Length > 0 {
-									log.Debugf("friendscript/http: <- decoding body as %v", reqargs.ResponseType)
-
-									switch reqargs.ResponseType {
-									case `raw`:
-										break
-									default:
-										// automatically decode response
-										rt := response.Header.Get(`Content-Type`)
-
-										if reqargs.ResponseType != `` {
-											rt = reqargs.ResponseType
-										}
-
-										switch rt {
-										case `application/json`:
-											if err := json.Unmarshal(data, &res.Body); err != nil {
-												return nil, err
-											}
-										}
-									}
-								}
+	// decode (i.e.: decompress) response
+	if response.ContentLength < 0 || response.ContentLength > 0 {
+		if decoded, err := httputil.DecodeResponse(response); err == nil {
+			if reqargs.RawBody {
+				res.Body = &rawBody{
+					Reader: decoded,
+					closer: response.Body,
+				}
+
+				res.Length = 0
+				streaming = true
+			} else {
+				if data, err := ioutil.ReadAll(decoded); err == nil {
+					res.Length = int64(len(data))
+					res.Body = string(data)
+
+					if res.Length > 0 {
+						log.Debugf("friendscript/http: <- decoding body as %v", reqargs.ResponseType)
+
+						switch reqargs.ResponseType {
+						case `raw`:
+							break
+						default:
+							// automatically decode response
+							if body, err := decodeResponseBody(data, response.Header.Get(`Content-Type`), reqargs.ResponseType); err == nil {
+								res.Body = body
 							} else {
-								log.Debugf("friendscript/http: <- Read response failed: %v", err)
 								return nil, err
 							}
 						}
-					} else {
-						log.Debugf("friendscript/http: <- Decode response failed: %v", err)
-						return nil, err
 					}
+				} else {
+					log.Debugf("friendscript/http: <- Read response failed: %v", err)
+					return nil, err
 				}
-
-				return res, nil
-			} else {
-				log.Debugf("friendscript/http: <- Request failed: %v", err)
-				return nil, err
 			}
 		} else {
+			log.Debugf("friendscript/http: <- Decode response failed: %v", err)
 			return nil, err
 		}
-	} else {
-		return nil, err
 	}
+
+	res.Timing = ex.tracer.finish(transferStart)
+
+	return res, nil
+}
+
+// a rawBody is the decoded response body handed to the caller; closing it closes the underlying
+// response body
+type rawBody struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (self *rawBody) Close() error {
+	return self.closer.Close()
 }
 
 func encodeBody(enctype string, body interface{}) (io.Reader, string, error) {
@@ -400,6 +762,11 @@ func encodeBody(enctype string, body interface{}) (io.Reader, string, error) {
 		case `form`:
 			contentType = `application/x-www-form-urlencoded`
 
+			switch body.(type) {
+			case io.Reader, []byte:
+				return nil, ``, fmt.Errorf("form bodies must be a map of fields; use request_type: \"multipart\" to upload files or binary data")
+			}
+
 			if typeutil.IsMap(body) {
 				values := make(url.Values)
 
@@ -409,14 +776,6 @@ func encodeBody(enctype string, body interface{}) (io.Reader, string, error) {
 
 				reader = bytes.NewBufferString(values.Encode())
 
-			} else if r, ok := body.(io.Reader); ok {
-				contentType = `multipart/form-data`
-				reader = r
-
-			} else if b, ok := body.([]byte); ok {
-				contentType = `multipart/form-data`
-				reader = bytes.NewBuffer(b)
-
 			} else {
 				reader = bytes.NewBufferString(typeutil.String(body))
 			}
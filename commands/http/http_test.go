@@ -1,12 +1,37 @@
 package http
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/PerformLine/friendscript/utils"
+	"github.com/PerformLine/go-stockutil/maputil"
 	"github.com/stretchr/testify/require"
 )
 
@@ -69,6 +94,17 @@ func TestPost(t *testing.T) {
 
 	_, err = client.Get(fmt.Sprintf("%v/cookies", server.URL), nil)
 	assert.NoError(err)
+
+	// binary form bodies can only be sent as multipart
+	for _, body := range []interface{}{[]byte(`data`), strings.NewReader(`data`)} {
+		_, err = client.Post(fmt.Sprintf("%v/form", server.URL), &RequestArgs{
+			RequestType: `form`,
+			Body:        body,
+		})
+
+		assert.Error(err)
+		assert.Contains(err.Error(), `request_type: "multipart"`)
+	}
 }
 
 func TestIsErrorStatus(t *testing.T) {
@@ -105,3 +141,2004 @@ func TestIsErrorStatus(t *testing.T) {
 	assert.True(isErrorStatus(405, `200,204,404`))
 
 }
+
+func TestSessions(t *testing.T) {
+	assert := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/login`, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:  `sid`,
+			Value: `abc123`,
+			Path:  `/`,
+		})
+
+		http.SetCookie(w, &http.Cookie{
+			Name:  `scoped`,
+			Value: `yes`,
+			Path:  `/admin`,
+		})
+	})
+
+	mux.HandleFunc(`/whoami`, func(w http.ResponseWriter, req *http.Request) {
+		if cookie, err := req.Cookie(`sid`); err == nil {
+			fmt.Fprintf(w, "%s:%s", cookie.Value, req.Header.Get(`X-Client`))
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	var connections int64
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&connections, 1)
+		}
+	}
+
+	server.Start()
+	defer server.Close()
+
+	var client = New(nil)
+
+	// requests without a session don't remember cookies...
+	_, err := client.Get(server.URL+`/login`, nil)
+	assert.NoError(err)
+	_, err = client.Get(server.URL+`/whoami`, nil)
+	assert.Error(err)
+
+	// ...but still reuse connections
+	assert.EqualValues(1, atomic.LoadInt64(&connections))
+	assert.Len(client.transports, 1)
+
+	// requests in a session do
+	_, err = client.Get(server.URL+`/whoami`, &RequestArgs{
+		Session: `api`,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `no such HTTP session "api"`)
+
+	assert.NoError(client.Session(`api`, &RequestArgs{
+		Headers: map[string]interface{}{
+			`X-Client`: `friendscript`,
+		},
+	}))
+
+	_, err = client.Post(server.URL+`/login`, &RequestArgs{
+		Session: `api`,
+	})
+
+	assert.NoError(err)
+
+	res, err := client.Get(server.URL+`/whoami`, &RequestArgs{
+		Session: `api`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`abc123:friendscript`, res.Body)
+	assert.EqualValues(1, atomic.LoadInt64(&connections))
+
+	// inspecting cookies
+	cookies, err := client.Cookies(`api`, nil)
+	assert.NoError(err)
+	assert.Len(cookies, 2)
+	assert.Equal(`scoped`, cookies[0].Name)
+	assert.Equal(`/admin`, cookies[0].Path)
+	assert.Equal(`sid`, cookies[1].Name)
+	assert.Equal(`abc123`, cookies[1].Value)
+	assert.Equal(`/`, cookies[1].Path)
+	assert.True(cookies[1].HostOnly)
+
+	cookies, err = client.Cookies(`api`, &CookiesArgs{
+		URL: server.URL + `/whoami`,
+	})
+
+	assert.NoError(err)
+	assert.Len(cookies, 1)
+	assert.Equal(`sid`, cookies[0].Name)
+
+	// exporting and importing cookies
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	exported, err := client.ExportCookies(`api`, &ExportCookiesArgs{
+		Path: filepath.Join(dir, `cookies.json`),
+	})
+
+	assert.NoError(err)
+	assert.Len(exported, 2)
+
+	assert.NoError(client.Session(`copy`, nil))
+
+	n, err := client.ImportCookies(`copy`, &ImportCookiesArgs{
+		Path: filepath.Join(dir, `cookies.json`),
+	})
+
+	assert.NoError(err)
+	assert.Equal(2, n)
+
+	res, err = client.Get(server.URL+`/whoami`, &RequestArgs{
+		Session: `copy`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`abc123:`, res.Body)
+
+	assert.NoError(client.Session(`manual`, nil))
+
+	n, err = client.ImportCookies(`manual`, &ImportCookiesArgs{
+		Cookies: []interface{}{
+			map[string]interface{}{
+				`name`:      `sid`,
+				`value`:     `xyz`,
+				`domain`:    `127.0.0.1`,
+				`host_only`: true,
+			},
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(1, n)
+
+	// sessions can be the default for all requests
+	assert.NoError(client.Defaults(&RequestArgs{
+		Session: `manual`,
+	}))
+
+	res, err = client.Get(server.URL+`/whoami`, nil)
+	assert.NoError(err)
+	assert.Equal(`xyz:`, res.Body)
+
+	// closing a session discards it
+	assert.NoError(client.CloseSession(`manual`))
+	_, err = client.Get(server.URL+`/whoami`, nil)
+	assert.Error(err)
+	assert.Error(client.CloseSession(`manual`))
+
+	// different TLS settings use different transports
+	assert.NoError(client.Defaults(nil))
+
+	_, err = client.Get(server.URL+`/login`, &RequestArgs{
+		DisableVerifySSL: true,
+	})
+
+	assert.NoError(err)
+	assert.Len(client.transports, 2)
+}
+
+func TestRetries(t *testing.T) {
+	assert := require.New(t)
+
+	var lock sync.Mutex
+	var attempts = make(map[string]int)
+
+	// fails the first "fail" requests to each path in the way given by "mode"
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		attempts[req.URL.Path] += 1
+		var n = attempts[req.URL.Path]
+		lock.Unlock()
+
+		var body, _ = ioutil.ReadAll(req.Body)
+
+		if fail, _ := strconv.Atoi(req.URL.Query().Get(`fail`)); n <= fail {
+			switch req.URL.Query().Get(`mode`) {
+			case `hangup`:
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				conn.Close()
+			case `slow`:
+				time.Sleep(200 * time.Millisecond)
+			case `after`:
+				w.Header().Set(`Retry-After`, `0`)
+				w.WriteHeader(http.StatusTooManyRequests)
+			default:
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+
+			return
+		}
+
+		fmt.Fprintf(w, "attempt %d: %s", n, body)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+	var retries, noRetries = 3, 0
+	var fast = &RequestArgs{
+		Retries:    &retries,
+		RetryDelay: time.Millisecond,
+	}
+
+	// retryable statuses
+	res, err := client.Post(server.URL+`/status?fail=2`, fast.Merge(&RequestArgs{
+		RequestType: `raw`,
+		Body:        `hello`,
+	}))
+
+	assert.NoError(err)
+	assert.Equal(3, res.Attempts)
+	assert.Equal(`attempt 3: hello`, res.Body)
+
+	// Retry-After is honored
+	res, err = client.Get(server.URL+`/after?fail=1&mode=after`, fast)
+	assert.NoError(err)
+	assert.Equal(2, res.Attempts)
+
+	// connection errors (using POST, since the transport itself retries some idempotent requests)
+	res, err = client.Post(server.URL+`/hangup?fail=2&mode=hangup`, fast)
+	assert.NoError(err)
+	assert.Equal(3, res.Attempts)
+
+	// timeouts
+	res, err = client.Get(server.URL+`/slow?fail=1&mode=slow`, fast.Merge(&RequestArgs{
+		Timeout: 50 * time.Millisecond,
+	}))
+
+	assert.NoError(err)
+	assert.Equal(2, res.Attempts)
+
+	// giving up after running out of attempts
+	_, err = client.Get(server.URL+`/exhausted?fail=10`, fast)
+	assert.Error(err)
+	assert.Contains(err.Error(), `503`)
+	assert.Equal(4, attempts[`/exhausted`])
+
+	// only the given statuses and errors are retried
+	_, err = client.Get(server.URL+`/unlisted?fail=1`, fast.Merge(&RequestArgs{
+		RetryStatuses: `500`,
+	}))
+
+	assert.Error(err)
+	assert.Equal(1, attempts[`/unlisted`])
+
+	_, err = client.Post(server.URL+`/noconn?fail=1&mode=hangup`, fast.Merge(&RequestArgs{
+		RetryOn: `timeout`,
+	}))
+
+	assert.Error(err)
+	assert.Equal(1, attempts[`/noconn`])
+
+	_, err = client.Get(server.URL+`/x`, fast.Merge(&RequestArgs{
+		RetryOn: `sometimes`,
+	}))
+
+	assert.Error(err)
+
+	// requests are made once by default
+	res, err = client.Get(server.URL+`/once`, nil)
+	assert.NoError(err)
+	assert.Equal(1, res.Attempts)
+
+	// retries can be turned off for a single request
+	_, err = client.Get(server.URL+`/override?fail=1`, fast.Merge(&RequestArgs{
+		Retries: &noRetries,
+	}))
+
+	assert.Error(err)
+	assert.Equal(1, attempts[`/override`])
+}
+
+func TestRetryDelay(t *testing.T) {
+	assert := require.New(t)
+
+	var reqargs = &RequestArgs{
+		RetryDelay:    100 * time.Millisecond,
+		RetryMaxDelay: time.Second,
+	}
+
+	for i := 0; i < 100; i++ {
+		d := retryDelay(reqargs, 1, nil)
+		assert.True(d >= 50*time.Millisecond && d <= 100*time.Millisecond, d)
+
+		d = retryDelay(reqargs, 3, nil)
+		assert.True(d >= 200*time.Millisecond && d <= 400*time.Millisecond, d)
+
+		d = retryDelay(reqargs, 20, nil)
+		assert.True(d >= 500*time.Millisecond && d <= time.Second, d)
+	}
+
+	var response = &http.Response{
+		Header: make(http.Header),
+	}
+
+	response.Header.Set(`Retry-After`, `2`)
+	assert.Equal(time.Second, retryDelay(reqargs, 1, response))
+
+	reqargs.RetryMaxDelay = time.Minute
+	assert.Equal(2*time.Second, retryDelay(reqargs, 1, response))
+
+	d, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(ok)
+	assert.True(d > 59*time.Minute)
+
+	_, ok = parseRetryAfter(`soon`)
+	assert.False(ok)
+}
+
+func TestMultipart(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var upload = filepath.Join(dir, `report.json`)
+	assert.NoError(ioutil.WriteFile(upload, []byte(`{"ok":true}`), 0644))
+
+	var flaky int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/flaky`, func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&flaky, 1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			http.Redirect(w, req, `/upload`, http.StatusTemporaryRedirect)
+		}
+	})
+
+	mux.HandleFunc(`/upload`, func(w http.ResponseWriter, req *http.Request) {
+		if err := req.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var out = map[string]interface{}{
+			`fields`:   req.MultipartForm.Value,
+			`streamed`: req.ContentLength < 0,
+		}
+
+		var files = make(map[string]interface{})
+
+		for field, headers := range req.MultipartForm.File {
+			var parts = make([]string, 0)
+
+			for _, header := range headers {
+				file, err := header.Open()
+				assert.NoError(err)
+				data, err := ioutil.ReadAll(file)
+				assert.NoError(err)
+				file.Close()
+
+				parts = append(parts, fmt.Sprintf("%s|%s|%s", header.Filename, header.Header.Get(`Content-Type`), data))
+			}
+
+			files[field] = parts
+		}
+
+		out[`files`] = files
+
+		w.Header().Set(`Content-Type`, `application/json`)
+		json.NewEncoder(w).Encode(out)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+
+	res, err := client.Post(server.URL+`/upload`, &RequestArgs{
+		RequestType: `multipart`,
+		Body: map[string]interface{}{
+			`name`:   `test`,
+			`count`:  3,
+			`tags`:   []interface{}{`a`, `b`},
+			`report`: `file:` + upload,
+			`raw`:    []byte(`hello`),
+			`custom`: map[string]interface{}{
+				`content`:      `a,b,c`,
+				`filename`:     `data.csv`,
+				`content_type`: `text/csv`,
+			},
+		},
+	})
+
+	assert.NoError(err)
+
+	var body = res.Body.(map[string]interface{})
+
+	assert.Equal(true, body[`streamed`])
+
+	assert.Equal(map[string]interface{}{
+		`name`:  []interface{}{`test`},
+		`count`: []interface{}{`3`},
+		`tags`:  []interface{}{`a`, `b`},
+	}, body[`fields`])
+
+	assert.Equal(map[string]interface{}{
+		`report`: []interface{}{`report.json|application/json|{"ok":true}`},
+		`raw`:    []interface{}{`raw|application/octet-stream|hello`},
+		`custom`: []interface{}{`data.csv|text/csv|a,b,c`},
+	}, body[`files`])
+
+	// missing files are reported before the request is made
+	_, err = client.Post(server.URL+`/upload`, &RequestArgs{
+		RequestType: `multipart`,
+		Body: map[string]interface{}{
+			`report`: `file:` + filepath.Join(dir, `missing.txt`),
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `field "report"`)
+
+	// bodies are streamed again when retried or redirected...
+	var retries = 1
+
+	res, err = client.Post(server.URL+`/flaky`, &RequestArgs{
+		RequestType:   `multipart`,
+		Retries:       &retries,
+		RetryStatuses: `503`,
+		Body: map[string]interface{}{
+			`name`:   `test`,
+			`report`: `file:` + upload,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(2, res.Attempts)
+
+	body = res.Body.(map[string]interface{})
+
+	assert.Equal(true, body[`streamed`])
+	assert.Equal(map[string]interface{}{
+		`name`: []interface{}{`test`},
+	}, body[`fields`])
+	assert.Equal(map[string]interface{}{
+		`report`: []interface{}{`report.json|application/json|{"ok":true}`},
+	}, body[`files`])
+
+	// ...unless they contain readers, which can only be read once and so are buffered
+	res, err = client.Post(server.URL+`/flaky`, &RequestArgs{
+		RequestType:   `multipart`,
+		Retries:       &retries,
+		RetryStatuses: `503`,
+		Body: map[string]interface{}{
+			`raw`: strings.NewReader(`hello`),
+		},
+	})
+
+	assert.NoError(err)
+
+	body = res.Body.(map[string]interface{})
+
+	assert.Equal(false, body[`streamed`])
+	assert.Equal(map[string]interface{}{
+		`raw`: []interface{}{`raw|application/octet-stream|hello`},
+	}, body[`files`])
+
+	// parts are closed if the request can't be made after the body has been encoded
+	for url, auth := range map[string]*AuthArgs{
+		server.URL + `/upload`: {Token: `env:FRIENDSCRIPT_TEST_MISSING_TOKEN`},
+		`http://[::1`:          nil,
+	} {
+		var part = &closeRecorder{Reader: strings.NewReader(`hello`), closed: make(chan bool, 1)}
+
+		_, err = client.Post(url, &RequestArgs{
+			RequestType: `multipart`,
+			Auth:        auth,
+			Body: map[string]interface{}{
+				`raw`: part,
+			},
+		})
+
+		assert.Error(err)
+
+		select {
+		case <-part.closed:
+		case <-time.After(time.Second):
+			assert.Fail(`part was not closed`, err.Error())
+		}
+	}
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed chan bool
+}
+
+func (self *closeRecorder) Close() error {
+	self.closed <- true
+	return nil
+}
+
+func TestDecodeResponseBody(t *testing.T) {
+	assert := require.New(t)
+
+	for _, tc := range []struct {
+		contentType  string
+		responseType string
+		body         string
+		expected     interface{}
+	}{
+		{`application/json; charset=utf-8`, ``, `{"a":1}`, map[string]interface{}{`a`: float64(1)}},
+		{`application/vnd.api+json`, ``, `[true]`, []interface{}{true}},
+		{`text/plain`, `json`, `"x"`, `x`},
+		{`text/plain`, `application/json`, `"x"`, `x`},
+		{`application/json`, `text`, `{"a":1}`, `{"a":1}`},
+		{`application/octet-stream`, ``, `{"a":1}`, `{"a":1}`},
+		{`application/x-yaml`, ``, "a: 1\nb: [x, z]\nc: {d: true}\n", map[string]interface{}{
+			`a`: 1,
+			`b`: []interface{}{`x`, `z`},
+			`c`: map[string]interface{}{`d`: true},
+		}},
+		{`application/x-www-form-urlencoded`, ``, "a=1&b=x&b=y\n", map[string]interface{}{
+			`a`: `1`,
+			`b`: []interface{}{`x`, `y`},
+		}},
+		{`text/csv`, ``, "name,age\nalice,30\nbob\n", []interface{}{
+			map[string]interface{}{`name`: `alice`, `age`: `30`},
+			map[string]interface{}{`name`: `bob`, `age`: ``},
+		}},
+		{`text/tab-separated-values`, ``, "a\tb\n1\t2\n", []interface{}{
+			map[string]interface{}{`a`: `1`, `b`: `2`},
+		}},
+		{`application/x-ndjson`, ``, "{\"a\":1}\n\n{\"a\":2}\n", []interface{}{
+			map[string]interface{}{`a`: float64(1)},
+			map[string]interface{}{`a`: float64(2)},
+		}},
+		{`application/atom+xml`, ``, `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom" lang="en">
+			<title>Test</title>
+			<entry id="1"><title>One</title></entry>
+			<entry id="2">Two</entry>
+		</feed>`, map[string]interface{}{
+			`feed`: map[string]interface{}{
+				`@lang`: `en`,
+				`title`: `Test`,
+				`entry`: []interface{}{
+					map[string]interface{}{`@id`: `1`, `title`: `One`},
+					map[string]interface{}{`@id`: `2`, `#text`: `Two`},
+				},
+			},
+		}},
+		{`text/plain; charset=iso-8859-1`, ``, "caf\xe9", `café`},
+		{`text/plain; charset=windows-1252`, ``, "\x93hi\x94 \x80", "“hi” €"},
+		{`text/plain; charset=utf-16le`, ``, "h\x00i\x00", `hi`},
+		{`text/plain; charset=utf-16`, ``, "\xfe\xff\x00h\x00i", `hi`},
+		{`text/plain; charset=utf-8`, ``, "\xef\xbb\xbfhi", `hi`},
+		{`text/plain; charset=shift_jis`, ``, "\x93\xfa\x96\x7b", `日本`},
+		{`text/plain; charset=koi8-r`, ``, "\xd0\xd2\xc9\xd7\xc5\xd4", `привет`},
+		{`text/plain; charset=gbk`, ``, "\xd6\xd0\xce\xc4", `中文`},
+		{`application/json; charset=iso-8859-1`, ``, "{\"a\":\"\xe9\"}", map[string]interface{}{`a`: `é`}},
+		{`application/xml`, ``, "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><a>\xe9</a>", map[string]interface{}{`a`: `é`}},
+		{`text/plain; charset=klingon`, ``, `qapla'`, `qapla'`},
+	} {
+		value, err := decodeResponseBody([]byte(tc.body), tc.contentType, tc.responseType)
+		assert.NoError(err, tc.contentType)
+		assert.Equal(tc.expected, value, tc.contentType)
+	}
+
+	_, err := decodeResponseBody([]byte(`x`), `text/plain`, `bogus`)
+	assert.Error(err)
+
+	_, err = decodeResponseBody([]byte(`{`), `application/problem+json`, ``)
+	assert.Error(err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set(`Content-Type`, `application/json; charset=utf-8`)
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	res, err := New(nil).Get(server.URL, nil)
+	assert.NoError(err)
+	assert.Equal(`application/json`, res.ContentType)
+	assert.Equal(map[string]interface{}{`ok`: true}, res.Body)
+}
+
+func TestDownload(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var content = []byte(strings.Repeat(`0123456789`, 1000))
+	var checksum = fmt.Sprintf("%x", sha256.Sum256(content))
+	var ranges []string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/file.txt`, func(w http.ResponseWriter, req *http.Request) {
+		ranges = append(ranges, req.Header.Get(`Range`))
+		http.ServeContent(w, req, `file.txt`, time.Time{}, bytes.NewReader(content))
+	})
+
+	mux.HandleFunc(`/chunked`, func(w http.ResponseWriter, req *http.Request) {
+		for i := 0; i < 10; i++ {
+			w.Write(content[:1000])
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	mux.HandleFunc(`/missing`, func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	mux.HandleFunc(`/slow`, func(w http.ResponseWriter, req *http.Request) {
+		for i := 0; i < 5; i++ {
+			w.Write(content[:1000])
+			w.(http.Flusher).Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+	var path = filepath.Join(dir, `file.txt`)
+
+	_, err = client.Download(server.URL+`/file.txt`, nil)
+	assert.Error(err)
+
+	// plain download
+	res, err := client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:     path,
+		SHA256: strings.ToUpper(checksum),
+	})
+
+	assert.NoError(err)
+	assert.Equal(path, res.Path)
+	assert.Equal(http.StatusOK, res.Status)
+	assert.EqualValues(len(content), res.Bytes)
+	assert.EqualValues(len(content), res.Size)
+	assert.Equal(checksum, res.SHA256)
+	assert.Equal(`text/plain`, res.ContentType)
+	assert.False(res.Resumed)
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(content, data)
+
+	// checksum mismatches and oversized files are removed
+	_, err = client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:  path,
+		MD5: `d41d8cd98f00b204e9800998ecf8427e`,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `MD5 checksum mismatch`)
+	assert.NoFileExists(path)
+
+	_, err = client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:      path,
+		MaxSize: 5000,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `maximum size`)
+	assert.NoFileExists(path)
+
+	_, err = client.Download(server.URL+`/chunked`, &DownloadArgs{
+		To:      path,
+		MaxSize: 5000,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `maximum size`)
+	assert.NoFileExists(path)
+
+	res, err = client.Download(server.URL+`/chunked`, &DownloadArgs{
+		To:      path,
+		MaxSize: 10000,
+	})
+
+	assert.NoError(err)
+	assert.EqualValues(10000, res.Bytes)
+
+	// error statuses don't touch the destination
+	_, err = client.Download(server.URL+`/missing`, &DownloadArgs{
+		To: path,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `404`)
+	assert.FileExists(path)
+
+	// resume a partial download
+	assert.NoError(ioutil.WriteFile(path, content[:4000], 0644))
+	ranges = nil
+
+	res, err = client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:     path,
+		Resume: true,
+		SHA256: checksum,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`bytes=4000-`}, ranges)
+	assert.Equal(http.StatusPartialContent, res.Status)
+	assert.True(res.Resumed)
+	assert.EqualValues(6000, res.Bytes)
+	assert.EqualValues(len(content), res.Size)
+	assert.Equal(checksum, res.SHA256)
+
+	data, err = ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(content, data)
+
+	// resuming a complete download does nothing
+	res, err = client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:     path,
+		Resume: true,
+		SHA256: checksum,
+	})
+
+	assert.NoError(err)
+	assert.True(res.Resumed)
+	assert.EqualValues(0, res.Bytes)
+	assert.EqualValues(len(content), res.Size)
+	assert.Equal(checksum, res.SHA256)
+
+	// a local file larger than the remote one can't be resumed, so the download starts over
+	assert.NoError(ioutil.WriteFile(path, append(content, content[:500]...), 0644))
+	ranges = nil
+
+	res, err = client.Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:     path,
+		Resume: true,
+		SHA256: checksum,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{`bytes=10500-`, ``}, ranges)
+	assert.Equal(http.StatusOK, res.Status)
+	assert.False(res.Resumed)
+	assert.EqualValues(len(content), res.Bytes)
+	assert.EqualValues(len(content), res.Size)
+
+	data, err = ioutil.ReadFile(path)
+	assert.NoError(err)
+	assert.Equal(content, data)
+
+	// destinations written by the runtime are downloaded in full, even when resuming
+	var runtime = &memoryRuntime{
+		files: make(map[string]*bytes.Buffer),
+	}
+
+	ranges = nil
+
+	res, err = New(runtime).Download(server.URL+`/file.txt`, &DownloadArgs{
+		To:     `memory:file.txt`,
+		Resume: true,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]string{``}, ranges)
+	assert.Equal(`memory:file.txt`, res.Path)
+	assert.False(res.Resumed)
+	assert.Equal(content, runtime.files[`memory:file.txt`].Bytes())
+
+	// downloads aren't limited by the default timeout, only by their own
+	assert.NoError(client.Defaults(&RequestArgs{
+		Timeout: 30 * time.Millisecond,
+	}))
+
+	_, err = client.Get(server.URL+`/slow`, nil)
+	assert.Error(err)
+
+	res, err = client.Download(server.URL+`/slow`, &DownloadArgs{
+		To: path,
+	})
+
+	assert.NoError(err)
+	assert.EqualValues(5000, res.Bytes)
+
+	_, err = client.Download(server.URL+`/slow`, &DownloadArgs{
+		To:      path,
+		Timeout: 30 * time.Millisecond,
+	})
+
+	assert.Error(err)
+}
+
+// a runtime that provides in-memory writers for paths starting with "memory:"
+type memoryRuntime struct {
+	utils.Runtime
+	files map[string]*bytes.Buffer
+}
+
+func (self *memoryRuntime) GetWriterForPath(path string) (string, io.Writer, error) {
+	if strings.HasPrefix(path, `memory:`) {
+		self.files[path] = new(bytes.Buffer)
+		return path, self.files[path], nil
+	}
+
+	return ``, nil, nil
+}
+
+func TestAuth(t *testing.T) {
+	assert := require.New(t)
+
+	var tokenRequests int64
+	var currentToken = `token-1`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/basic`, func(w http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); ok && username == `user` && password == `s3cret` {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	mux.HandleFunc(`/bearer`, func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get(`Authorization`) == `Bearer `+currentToken {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	mux.HandleFunc(`/token`, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt64(&tokenRequests, 1)
+
+		if id, secret, ok := req.BasicAuth(); !ok || id != `client` || secret != `s3cret` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		assert.NoError(req.ParseForm())
+		assert.Equal(`client_credentials`, req.PostForm.Get(`grant_type`))
+		assert.Equal(`read write`, req.PostForm.Get(`scope`))
+
+		w.Header().Set(`Content-Type`, `application/json`)
+		fmt.Fprintf(w, `{"access_token":"%s","token_type":"bearer","expires_in":3600}`, currentToken)
+	})
+
+	mux.HandleFunc(`/digest`, func(w http.ResponseWriter, req *http.Request) {
+		var params = parseAuthParams(strings.TrimPrefix(req.Header.Get(`Authorization`), `Digest `))
+		var md5hex = func(s string) string {
+			return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+		}
+
+		var ha1 = md5hex(`user:test:s3cret`)
+		var ha2 = md5hex(req.Method + `:` + params[`uri`])
+
+		if params[`nonce`] == `abc` && params[`response`] == md5hex(strings.Join([]string{
+			ha1, `abc`, params[`nc`], params[`cnonce`], `auth`, ha2,
+		}, `:`)) {
+			body, _ := ioutil.ReadAll(req.Body)
+			w.Write(body)
+		} else {
+			w.Header().Set(`WWW-Authenticate`, `Basic realm="test"`)
+			w.Header().Add(`WWW-Authenticate`, `Digest realm="test", qop="auth,auth-int", nonce="abc", opaque="xyz"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+
+	_, err := client.Get(server.URL+`/basic`, nil)
+	assert.Error(err)
+
+	_, err = client.Get(server.URL+`/basic`, &RequestArgs{
+		Auth: &AuthArgs{
+			Username: `user`,
+			Password: `s3cret`,
+		},
+	})
+
+	assert.NoError(err)
+
+	// credentials from the environment
+	t.Setenv(`FRIENDSCRIPT_TEST_PASSWORD`, `s3cret`)
+
+	_, err = client.Get(server.URL+`/basic`, &RequestArgs{
+		Auth: &AuthArgs{
+			Type:     `basic`,
+			Username: `user`,
+			Password: `env:FRIENDSCRIPT_TEST_PASSWORD`,
+		},
+	})
+
+	assert.NoError(err)
+
+	_, err = client.Get(server.URL+`/basic`, &RequestArgs{
+		Auth: &AuthArgs{
+			Username: `user`,
+			Password: `env:FRIENDSCRIPT_TEST_MISSING`,
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `FRIENDSCRIPT_TEST_MISSING is not set`)
+
+	_, err = client.Get(server.URL+`/bearer`, &RequestArgs{
+		Auth: &AuthArgs{
+			Token: `token-1`,
+		},
+	})
+
+	assert.NoError(err)
+
+	_, err = client.Get(server.URL+`/bearer`, &RequestArgs{
+		Auth: &AuthArgs{
+			Type: `magic`,
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `unknown authentication type "magic"`)
+
+	// digest authentication answers the challenge, then reuses it
+	res, err := client.Post(server.URL+`/digest?x=1`, &RequestArgs{
+		Body: `hello`,
+		Auth: &AuthArgs{
+			Type:     `digest`,
+			Username: `user`,
+			Password: `s3cret`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`hello`, res.Body)
+	assert.Equal(1, res.Attempts)
+	assert.Equal(1, client.digests[strings.TrimPrefix(server.URL, `http://`)].count)
+
+	_, err = client.Get(server.URL+`/digest`, &RequestArgs{
+		Auth: &AuthArgs{
+			Type:     `digest`,
+			Username: `user`,
+			Password: `s3cret`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(2, client.digests[strings.TrimPrefix(server.URL, `http://`)].count)
+
+	_, err = client.Get(server.URL+`/digest`, &RequestArgs{
+		Auth: &AuthArgs{
+			Type:     `digest`,
+			Username: `user`,
+			Password: `wrong`,
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `401`)
+
+	// oauth2 tokens are cached until they're rejected
+	var oauth2 = &RequestArgs{
+		Auth: &AuthArgs{
+			TokenURL:     server.URL + `/token`,
+			ClientID:     `client`,
+			ClientSecret: `s3cret`,
+			Scopes:       []string{`read`, `write`},
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err = client.Get(server.URL+`/bearer`, oauth2)
+		assert.NoError(err)
+	}
+
+	assert.EqualValues(1, atomic.LoadInt64(&tokenRequests))
+
+	currentToken = `token-2`
+
+	res, err = client.Get(server.URL+`/bearer`, oauth2)
+	assert.NoError(err)
+	assert.Equal(1, res.Attempts)
+	assert.EqualValues(2, atomic.LoadInt64(&tokenRequests))
+
+	// expired tokens are refreshed before they're used
+	client.tokens[oauth2.Auth.tokenKey()].expires = time.Now()
+
+	_, err = client.Get(server.URL+`/bearer`, oauth2)
+	assert.NoError(err)
+	assert.EqualValues(3, atomic.LoadInt64(&tokenRequests))
+
+	_, err = client.Get(server.URL+`/bearer`, &RequestArgs{
+		Auth: &AuthArgs{
+			TokenURL: server.URL + `/token`,
+			ClientID: `nobody`,
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `oauth2 token request failed: HTTP 401`)
+}
+
+func TestSignAWSv4(t *testing.T) {
+	assert := require.New(t)
+
+	// the "get-vanilla" case from the AWS Signature Version 4 test suite
+	req, err := http.NewRequest(`GET`, `https://example.amazonaws.com/`, nil)
+	assert.NoError(err)
+
+	var auth = &AuthArgs{
+		AccessKey: `AKIDEXAMPLE`,
+		SecretKey: `wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY`,
+		Region:    `us-east-1`,
+		Service:   `service`,
+	}
+
+	assert.Equal(AuthAWS, auth.mechanism())
+	assert.NoError(signAWSv4(req, auth, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	assert.Equal(`20150830T123600Z`, req.Header.Get(`X-Amz-Date`))
+	assert.Equal(
+		`AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31`,
+		req.Header.Get(`Authorization`),
+	)
+
+	// "get-vanilla-query-order-key-case"
+	req, err = http.NewRequest(`GET`, `https://example.amazonaws.com/?Param2=value2&Param1=value1`, nil)
+	assert.NoError(err)
+	assert.NoError(signAWSv4(req, auth, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	assert.True(strings.HasSuffix(req.Header.Get(`Authorization`), `Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500`))
+
+	// "get-utf8", with the path encoded on the way in as it is when sent: the path is encoded again
+	// when signing for every service but S3
+	req, err = http.NewRequest(`GET`, `https://example.amazonaws.com/%E1%88%B4`, nil)
+	assert.NoError(err)
+	assert.NoError(signAWSv4(req, auth, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	assert.True(strings.HasSuffix(req.Header.Get(`Authorization`), `Signature=697b34846207a3f72246f99d74ae1ee4fe54f44bb06730c58a0d339eb079596d`))
+	assert.Equal(`/%E1%88%B4`, req.URL.EscapedPath())
+
+	// ...and S3 paths are encoded once, with the request sent using the same encoding
+	req, err = http.NewRequest(`GET`, `https://example.amazonaws.com/bucket/my file$.txt`, nil)
+	assert.NoError(err)
+	assert.NoError(signAWSv4(req, &AuthArgs{
+		AccessKey: auth.AccessKey,
+		SecretKey: auth.SecretKey,
+		Region:    auth.Region,
+		Service:   `s3`,
+	}, nil, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)))
+	assert.True(strings.HasSuffix(req.Header.Get(`Authorization`), `Signature=05346cc712a0cba9634eb8034b6085249112e8a6b03114202b0dbd85f4d7287e`))
+	assert.Equal(`/bucket/my%20file%24.txt`, req.URL.EscapedPath())
+
+	// credentials can come from the environment
+	t.Setenv(`AWS_ACCESS_KEY_ID`, `AKIDEXAMPLE`)
+	t.Setenv(`AWS_SECRET_ACCESS_KEY`, `wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY`)
+	t.Setenv(`AWS_SESSION_TOKEN`, `session`)
+	t.Setenv(`AWS_REGION`, `us-west-2`)
+
+	var signed http.Header
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		signed = req.Header
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	_, err = New(nil).Put(server.URL+`/bucket/key`, &RequestArgs{
+		Body: `hello`,
+		Auth: &AuthArgs{
+			Type:    `aws`,
+			Service: `s3`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`session`, signed.Get(`X-Amz-Security-Token`))
+	assert.Equal(fmt.Sprintf("%x", sha256.Sum256([]byte(`hello`))), signed.Get(`X-Amz-Content-Sha256`))
+	assert.Contains(signed.Get(`Authorization`), `/us-west-2/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date;x-amz-security-token, Signature=`)
+
+	_, err = New(nil).Get(server.URL, &RequestArgs{
+		Auth: &AuthArgs{
+			Type:      `aws`,
+			AccessKey: `AKIDEXAMPLE`,
+			SecretKey: `secret`,
+		},
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `requires a region and service`)
+}
+
+// write a self-signed client certificate and its key to the given directory
+func writeClientCertificate(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var template = &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: `friendscript-test`},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	var certFile = filepath.Join(dir, `client.crt`)
+	var keyFile = filepath.Join(dir, `client.key`)
+
+	require.NoError(t, ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: `EC PRIVATE KEY`, Bytes: keyDER}), 0600))
+
+	return certFile, keyFile, cert
+}
+
+func TestTLSOptions(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	certFile, keyFile, clientCert := writeClientCertificate(t, dir)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s", req.TLS.PeerCertificates[0].Subject.CommonName)
+	})
+
+	server := httptest.NewUnstartedServer(mux)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  x509.NewCertPool(),
+		MaxVersion: tls.VersionTLS12,
+	}
+
+	server.TLS.ClientCAs.AddCert(clientCert)
+	server.Config.ErrorLog = log.New(ioutil.Discard, ``, 0)
+	server.StartTLS()
+	defer server.Close()
+
+	var caFile = filepath.Join(dir, `ca.pem`)
+	assert.NoError(ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type:  `CERTIFICATE`,
+		Bytes: server.Certificate().Raw,
+	}), 0600))
+
+	var client = New(nil)
+
+	// the server requires a client certificate
+	_, err = client.Get(server.URL, &RequestArgs{
+		CertificateBundle: caFile,
+	})
+
+	assert.Error(err)
+
+	assert.NoError(client.Defaults(&RequestArgs{
+		CertificateBundle: caFile,
+		ClientCertificate: certFile,
+		ClientKey:         keyFile,
+	}))
+
+	res, err := client.Get(server.URL, nil)
+	assert.NoError(err)
+	assert.Equal(`friendscript-test`, res.Body)
+
+	res, err = client.Get(server.URL, &RequestArgs{
+		CipherSuites: `TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`friendscript-test`, res.Body)
+
+	// the server doesn't support TLS 1.3
+	_, err = client.Get(server.URL, &RequestArgs{
+		MinTLSVersion: `1.3`,
+	})
+
+	assert.Error(err)
+
+	for _, reqargs := range []*RequestArgs{
+		{MinTLSVersion: `2.0`},
+		{CipherSuites: `TLS_MADE_UP`},
+		{ClientCertificate: caFile},
+		{ClientCertificate: filepath.Join(dir, `missing.crt`)},
+	} {
+		_, err = client.Get(server.URL, reqargs)
+		assert.Error(err)
+	}
+}
+
+func TestConnectionOptions(t *testing.T) {
+	assert := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "direct %s %s", req.Host, req.URL.Path)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "proxied %s", req.URL)
+	}))
+
+	defer proxy.Close()
+
+	_, port, err := net.SplitHostPort(strings.TrimPrefix(server.URL, `http://`))
+	assert.NoError(err)
+
+	var client = New(nil)
+
+	// proxies
+	res, err := client.Get(`http://service.example.test/hello`, &RequestArgs{
+		Proxy: proxy.URL,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`proxied http://service.example.test/hello`, res.Body)
+
+	res, err = client.Get(server.URL+`/hello`, &RequestArgs{
+		Proxy:   proxy.URL,
+		NoProxy: `example.test, 127.0.0.0/8`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(`direct 127.0.0.1:`+port+` /hello`, res.Body)
+
+	_, err = client.Get(server.URL, &RequestArgs{
+		Proxy: `ftp://proxy.example.test`,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `unsupported proxy scheme "ftp"`)
+
+	// host overrides
+	res, err = client.Get(`http://service.example.test:`+port+`/hello`, &RequestArgs{
+		Resolve: map[string]interface{}{
+			`service.example.test`: `127.0.0.1`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`direct service.example.test:`+port+` /hello`, res.Body)
+
+	res, err = client.Get(`http://service.example.test/hello`, &RequestArgs{
+		Resolve: map[string]interface{}{
+			`service.example.test:80`: `127.0.0.1:` + port,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(`direct service.example.test /hello`, res.Body)
+
+	// unix sockets
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var socket = filepath.Join(dir, `http.sock`)
+
+	listener, err := net.Listen(`unix`, socket)
+	assert.NoError(err)
+
+	go http.Serve(listener, mux)
+	defer listener.Close()
+
+	assert.NoError(client.Defaults(&RequestArgs{
+		UnixSocket: socket,
+	}))
+
+	res, err = client.Get(`http://docker/v1/info`, nil)
+	assert.NoError(err)
+	assert.Equal(`direct docker /v1/info`, res.Body)
+}
+
+func TestResponseBodiesAreReleased(t *testing.T) {
+	assert := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case `/error`:
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, strings.Repeat(`failed `, 1024))
+		case `/corrupt`:
+			w.Header().Set(`Content-Encoding`, `gzip`)
+			fmt.Fprint(w, `this is not gzip`)
+		default:
+			fmt.Fprint(w, strings.Repeat(`hello `, 1024))
+		}
+	})
+
+	var connections int32
+
+	server := httptest.NewUnstartedServer(mux)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+
+	server.Start()
+	defer server.Close()
+
+	var client = New(nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.Get(server.URL+`/error`, nil)
+		assert.Error(err)
+		assert.Contains(err.Error(), `HTTP 500`)
+
+		_, err = client.Get(server.URL+`/corrupt`, nil)
+		assert.Error(err)
+
+		res, err := client.Get(server.URL+`/raw`, &RequestArgs{
+			RawBody: true,
+		})
+
+		assert.NoError(err)
+
+		body, ok := res.Body.(io.ReadCloser)
+		assert.True(ok)
+
+		data, err := ioutil.ReadAll(body)
+		assert.NoError(err)
+		assert.Len(data, 6*1024)
+		assert.NoError(body.Close())
+	}
+
+	// every response was released, so every request reused the one connection
+	assert.EqualValues(1, atomic.LoadInt32(&connections))
+}
+
+func TestBypassProxy(t *testing.T) {
+	assert := require.New(t)
+
+	for _, tc := range []struct {
+		url        string
+		exclusions string
+		bypass     bool
+	}{
+		{`http://example.com`, ``, false},
+		{`http://example.com`, `*`, true},
+		{`http://example.com`, `example.com`, true},
+		{`http://api.example.com`, `example.com`, true},
+		{`http://api.example.com`, `.example.com`, true},
+		{`http://api.example.com`, `*.example.com`, true},
+		{`http://badexample.com`, `example.com`, false},
+		{`http://example.com`, `example.com:8080`, false},
+		{`http://example.com:8080`, `example.com:8080`, true},
+		{`https://example.com`, `example.com:443`, true},
+		{`http://10.1.2.3`, `10.0.0.0/8`, true},
+		{`http://11.1.2.3`, `10.0.0.0/8`, false},
+		{`http://[::1]:8080`, `::1`, true},
+		{`http://192.168.1.1`, `other.com, 192.168.1.1`, true},
+	} {
+		u, err := url.Parse(tc.url)
+		assert.NoError(err)
+		assert.Equal(tc.bypass, bypassProxy(u, strings.Split(tc.exclusions, `,`)), tc.url+` `+tc.exclusions)
+	}
+}
+
+func TestRedirectsAndTiming(t *testing.T) {
+	assert := require.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/first`, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: `first`, Value: `1`})
+		http.Redirect(w, req, `/second`, http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc(`/second`, func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, `/final`, http.StatusFound)
+	})
+
+	mux.HandleFunc(`/final`, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: `final`, Value: `2`})
+		fmt.Fprintf(w, "done")
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+
+	// connections are reused
+	res, err := client.Get(server.URL+`/final`, nil)
+	assert.NoError(err)
+	assert.False(res.Timing.Reused)
+	assert.True(res.Timing.FirstByte > 0)
+	assert.True(res.Timing.Total >= res.Timing.FirstByte)
+	assert.Empty(res.Redirects)
+
+	res, err = client.Get(server.URL+`/final`, nil)
+	assert.NoError(err)
+	assert.True(res.Timing.Reused)
+
+	// the whole chain is recorded
+	res, err = client.Get(server.URL+`/first`, nil)
+	assert.NoError(err)
+	assert.Equal(`done`, res.Body)
+	assert.Equal(server.URL+`/final`, res.URL)
+	assert.Len(res.Redirects, 2)
+	assert.Equal(server.URL+`/first`, res.Redirects[0].URL)
+	assert.Equal(http.StatusMovedPermanently, res.Redirects[0].Status)
+	assert.Equal(`/second`, res.Redirects[0].Headers[`Location`])
+	assert.Equal(server.URL+`/second`, res.Redirects[1].URL)
+	assert.Equal(http.StatusFound, res.Redirects[1].Status)
+	assert.Len(res.Cookies, 2)
+	assert.Equal(`first`, res.Cookies[0].Name)
+	assert.Equal(`final`, res.Cookies[1].Name)
+
+	// redirects can be returned instead of followed
+	var follow = false
+
+	res, err = client.Get(server.URL+`/first`, &RequestArgs{
+		FollowRedirects: &follow,
+		Statuses:        `200-399`,
+	})
+
+	assert.NoError(err)
+	assert.Equal(http.StatusMovedPermanently, res.Status)
+	assert.Equal(server.URL+`/first`, res.URL)
+	assert.Empty(res.Redirects)
+	assert.Len(res.Cookies, 1)
+
+	// and limited
+	_, err = client.Get(server.URL+`/first`, &RequestArgs{
+		MaxRedirects: 1,
+	})
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `stopped after 1 redirects`)
+
+	// TLS handshakes are timed
+	secure := httptest.NewTLSServer(mux)
+	defer secure.Close()
+
+	res, err = client.Get(secure.URL+`/final`, &RequestArgs{
+		DisableVerifySSL: true,
+	})
+
+	assert.NoError(err)
+	assert.True(res.Timing.TLS > 0)
+	assert.True(res.Timing.Connect > 0)
+}
+
+func TestCassettes(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	var hits int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/hello`, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set(`Content-Type`, `application/json`)
+		fmt.Fprintf(w, `{"hello": %q, "hits": %d}`, req.URL.Query().Get(`name`), atomic.LoadInt32(&hits))
+	})
+
+	mux.HandleFunc(`/echo`, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.SetCookie(w, &http.Cookie{Name: `seen`, Value: `yes`})
+		w.Header().Set(`Content-Type`, req.Header.Get(`Content-Type`))
+		io.Copy(w, req.Body)
+	})
+
+	mux.HandleFunc(`/moved`, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Redirect(w, req, `/hello?name=moved`, http.StatusFound)
+	})
+
+	mux.HandleFunc(`/binary`, func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set(`Content-Type`, `application/octet-stream`)
+		w.Write([]byte{0xff, 0x00, 0xfe})
+	})
+
+	server := httptest.NewServer(mux)
+
+	var cassette = filepath.Join(dir, `cassette.yaml`)
+	var client = New(nil)
+
+	// record
+	assert.NoError(client.Record(cassette, &RecordArgs{
+		Redact: []string{`Authorization`, `X-Secret-*`},
+	}))
+
+	res, err := client.Get(server.URL+`/hello`, &RequestArgs{
+		Params: map[string]interface{}{
+			`name`: `world`,
+			`lang`: `en`,
+		},
+		Headers: map[string]interface{}{
+			`Authorization`:  `Bearer hunter2`,
+			`X-Secret-Token`: `hunter3`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{`hello`: `world`, `hits`: float64(1)}, res.Body)
+
+	res, err = client.Post(server.URL+`/echo`, &RequestArgs{
+		Body: map[string]interface{}{`a`: 1, `b`: 2},
+	})
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{`a`: float64(1), `b`: float64(2)}, res.Body)
+
+	res, err = client.Post(server.URL+`/echo`, &RequestArgs{
+		RequestType: `multipart`,
+		Body: map[string]interface{}{
+			`field`: `value`,
+		},
+	})
+
+	assert.NoError(err)
+
+	res, err = client.Get(server.URL+`/moved`, nil)
+	assert.NoError(err)
+	assert.Equal(`moved`, maputil.M(res.Body).String(`hello`))
+
+	res, err = client.Get(server.URL+`/binary`, &RequestArgs{
+		ResponseType: `raw`,
+	})
+
+	assert.NoError(err)
+
+	recorded, err := client.Eject()
+	assert.NoError(err)
+	assert.Equal(6, recorded)
+	assert.EqualValues(6, atomic.LoadInt32(&hits))
+
+	data, err := ioutil.ReadFile(cassette)
+	assert.NoError(err)
+	assert.Contains(string(data), RedactedValue)
+	assert.NotContains(string(data), `hunter2`)
+	assert.NotContains(string(data), `hunter3`)
+
+	// replay without the server
+	server.Close()
+
+	client = New(nil)
+
+	assert.Error(client.Replay(filepath.Join(dir, `missing.yaml`), nil))
+	assert.Error(client.Replay(cassette, &ReplayArgs{
+		Match: []string{`colour`},
+	}))
+
+	assert.NoError(client.Replay(cassette, nil))
+
+	// query string order doesn't matter
+	res, err = client.Get(server.URL+`/hello?lang=en&name=world`, nil)
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{`hello`: `world`, `hits`: float64(1)}, res.Body)
+
+	// neither does the order of keys in JSON bodies (or multipart boundaries)
+	res, err = client.Post(server.URL+`/echo`, &RequestArgs{
+		RequestType: `raw`,
+		Body:        `{"b": 2, "a": 1}`,
+		Headers: map[string]interface{}{
+			`Content-Type`: `application/json`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{`a`: float64(1), `b`: float64(2)}, res.Body)
+	assert.Equal(`seen`, res.Cookies[0].Name)
+
+	_, err = client.Post(server.URL+`/echo`, &RequestArgs{
+		RequestType: `multipart`,
+		Body: map[string]interface{}{
+			`field`: `value`,
+		},
+	})
+
+	assert.NoError(err)
+
+	res, err = client.Get(server.URL+`/moved`, nil)
+	assert.NoError(err)
+	assert.Equal(`moved`, maputil.M(res.Body).String(`hello`))
+	assert.Len(res.Redirects, 1)
+
+	res, err = client.Get(server.URL+`/binary`, &RequestArgs{
+		ResponseType: `raw`,
+	})
+
+	assert.NoError(err)
+	assert.Equal("\xff\x00\xfe", res.Body)
+
+	// unmatched requests fail, as do requests whose responses have already been replayed
+	_, err = client.Get(server.URL+`/hello?name=nobody`, nil)
+	assert.Error(err)
+	assert.Contains(err.Error(), `no request recorded in`)
+
+	_, err = client.Get(server.URL+`/binary`, nil)
+	assert.Error(err)
+
+	replayed, err := client.Eject()
+	assert.NoError(err)
+	assert.Equal(6, replayed)
+
+	// ...unless repeats are allowed
+	assert.NoError(client.Replay(cassette, &ReplayArgs{
+		Match:  []string{`method`, `path`},
+		Repeat: true,
+	}))
+
+	for _, expected := range []string{`world`, `moved`, `world`} {
+		res, err = client.Get(server.URL+`/hello?name=anybody`, nil)
+		assert.NoError(err)
+		assert.Equal(expected, maputil.M(res.Body).String(`hello`))
+	}
+
+	// export as HAR
+	var harfile = filepath.Join(dir, `requests.har`)
+
+	har, err := client.ExportHar(&ExportHarArgs{
+		Path: harfile,
+	})
+
+	assert.NoError(err)
+	assert.Equal(HarVersion, har.Log.Version)
+	assert.Len(har.Log.Entries, 6)
+
+	var entry = har.Log.Entries[0]
+
+	assert.Equal(`GET`, entry.Request.Method)
+	assert.Equal(server.URL+`/hello?lang=en&name=world`, entry.Request.URL)
+	assert.Equal([]*HarNameValue{{`lang`, `en`}, {`name`, `world`}}, entry.Request.QueryString)
+	assert.Contains(entry.Request.Headers, &HarNameValue{`Authorization`, RedactedValue})
+	assert.Equal(200, entry.Response.Status)
+	assert.Equal(`OK`, entry.Response.StatusText)
+	assert.Equal(`application/json`, entry.Response.Content.MimeType)
+	assert.Contains(entry.Response.Content.Text, `"hello": "world"`)
+
+	assert.Equal(`{"a":1,"b":2}`, har.Log.Entries[1].Request.PostData.Text)
+	assert.Equal(`seen`, har.Log.Entries[1].Response.Cookies[0].Name)
+	assert.Equal(`/hello?name=moved`, har.Log.Entries[3].Response.RedirectURL)
+	assert.Equal(`base64`, har.Log.Entries[5].Response.Content.Encoding)
+	assert.Equal(3, har.Log.Entries[5].Response.Content.Size)
+
+	data, err = ioutil.ReadFile(harfile)
+	assert.NoError(err)
+
+	var exported map[string]interface{}
+	assert.NoError(json.Unmarshal(data, &exported))
+	assert.Len(maputil.M(exported).Get(`log.entries`).Value, 6)
+
+	// cassette files can be exported without being replayed
+	client.Eject()
+
+	_, err = client.ExportHar(nil)
+	assert.Error(err)
+
+	har, err = client.ExportHar(&ExportHarArgs{
+		Cassette: cassette,
+	})
+
+	assert.NoError(err)
+	assert.Len(har.Log.Entries, 6)
+}
+
+func TestCassetteRedaction(t *testing.T) {
+	assert := require.New(t)
+
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/token`, func(w http.ResponseWriter, req *http.Request) {
+		if req.PostFormValue(`client_secret`) != `s3cret` {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set(`Content-Type`, `application/json`)
+		fmt.Fprintf(w, `{"access_token": "tok3n", "token_type": "bearer", "expires_in": 3600}`)
+	})
+
+	mux.HandleFunc(`/private`, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: `session`, Value: `c00kie`, Path: `/`})
+
+		if req.Header.Get(`Authorization`) == `Bearer tok3n` {
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var cassette = filepath.Join(dir, `cassette.json`)
+	var args = &RequestArgs{
+		Auth: &AuthArgs{
+			TokenURL:     server.URL + `/token`,
+			ClientID:     `client`,
+			ClientSecret: `s3cret`,
+			ClientAuth:   `body`,
+		},
+	}
+
+	var client = New(nil)
+	assert.NoError(client.Record(cassette, nil))
+
+	res, err := client.Get(server.URL+`/private`, args)
+	assert.NoError(err)
+	assert.Equal(http.StatusNoContent, res.Status)
+
+	_, err = client.Eject()
+	assert.NoError(err)
+
+	data, err := ioutil.ReadFile(cassette)
+	assert.NoError(err)
+	assert.NotContains(string(data), `s3cret`)
+	assert.NotContains(string(data), `tok3n`)
+	assert.NotContains(string(data), `c00kie`)
+	assert.Contains(string(data), `session=`+RedactedValue+`; Path=/`)
+
+	// redacted requests still match the recording when it is replayed
+	client = New(nil)
+	assert.NoError(client.Replay(cassette, nil))
+
+	res, err = client.Get(server.URL+`/private`, args)
+	assert.NoError(err)
+	assert.Equal(http.StatusNoContent, res.Status)
+	assert.Equal(`session`, res.Cookies[0].Name)
+}
+
+func TestPaginate(t *testing.T) {
+	assert := require.New(t)
+
+	var requests []string
+	var lock sync.Mutex
+
+	// 7 items, served in pages of up to 3
+	var page = func(offset int, limit int) []map[string]interface{} {
+		var items = make([]map[string]interface{}, 0)
+
+		for i := offset; i < offset+limit && i < 7; i++ {
+			items = append(items, map[string]interface{}{`id`: i + 1})
+		}
+
+		return items
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(`/`, func(w http.ResponseWriter, req *http.Request) {
+		lock.Lock()
+		requests = append(requests, req.URL.RequestURI())
+		lock.Unlock()
+
+		var query = req.URL.Query()
+		var offset, _ = strconv.Atoi(query.Get(`offset`))
+		var limit, _ = strconv.Atoi(query.Get(`limit`))
+
+		if limit == 0 {
+			limit = 3
+		}
+
+		w.Header().Set(`Content-Type`, `application/json`)
+
+		switch req.URL.Path {
+		case `/link`:
+			var p, _ = strconv.Atoi(query.Get(`page`))
+
+			if p < 2 {
+				w.Header().Add(`Link`, `</link?page=3>; rel="last", </link?page=2>; rel="next"`)
+			} else if p == 2 {
+				w.Header().Add(`Link`, `<http://`+req.Host+`/link?page=3>; rel="next prefetch"`)
+			}
+
+			if p == 0 {
+				p = 1
+			}
+
+			json.NewEncoder(w).Encode(page((p-1)*3, 3))
+
+		case `/cursor`:
+			var cursor, _ = strconv.Atoi(query.Get(`after`))
+			var body = map[string]interface{}{
+				`data`: map[string]interface{}{
+					`items`: page(cursor, 3),
+				},
+			}
+
+			if cursor+3 < 7 {
+				body[`next`] = strconv.Itoa(cursor + 3)
+			}
+
+			json.NewEncoder(w).Encode(body)
+
+		case `/next-url`:
+			var body = map[string]interface{}{
+				`results`: page(offset, 3),
+			}
+
+			if offset+3 < 7 {
+				body[`next`] = fmt.Sprintf("/next-url?offset=%d", offset+3)
+			}
+
+			json.NewEncoder(w).Encode(body)
+
+		case `/offset`:
+			json.NewEncoder(w).Encode(page(offset, limit))
+
+		case `/object`:
+			json.NewEncoder(w).Encode(map[string]interface{}{`items`: page(0, 3)})
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var client = New(nil)
+
+	var collect = func(url string, args *PaginateArgs) ([]interface{}, []string, error) {
+		requests = nil
+
+		var items = make([]interface{}, 0)
+
+		if paginator, err := client.Paginate(url, args); err == nil {
+			for {
+				if item, ok, err := paginator.Next(); err != nil {
+					return nil, nil, err
+				} else if ok {
+					items = append(items, item)
+				} else {
+					return items, requests, nil
+				}
+			}
+		} else {
+			return nil, nil, err
+		}
+	}
+
+	var ids = func(items []interface{}) []int {
+		var out = make([]int, 0)
+
+		for _, item := range items {
+			out = append(out, int(maputil.M(item).Int(`id`)))
+		}
+
+		return out
+	}
+
+	// Link headers
+	items, reqs, err := collect(server.URL+`/link`, &PaginateArgs{
+		Params: map[string]interface{}{
+			`sort`: `id`,
+		},
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7}, ids(items))
+	assert.Equal([]string{`/link?sort=id`, `/link?page=2`, `/link?page=3`}, reqs)
+
+	// cursors
+	items, reqs, err = collect(server.URL+`/cursor`, &PaginateArgs{
+		Cursor:      `next`,
+		CursorParam: `after`,
+		Items:       `data.items`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7}, ids(items))
+	assert.Equal([]string{`/cursor`, `/cursor?after=3`, `/cursor?after=6`}, reqs)
+
+	// cursors that are URLs
+	items, reqs, err = collect(server.URL+`/next-url`, &PaginateArgs{
+		Cursor: `next`,
+		Items:  `results`,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7}, ids(items))
+	assert.Equal([]string{`/next-url`, `/next-url?offset=3`, `/next-url?offset=6`}, reqs)
+
+	// offsets
+	items, reqs, err = collect(server.URL+`/offset`, &PaginateArgs{
+		Using: `offset`,
+		Limit: 2,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6, 7}, ids(items))
+	assert.Equal([]string{
+		`/offset?limit=2&offset=0`,
+		`/offset?limit=2&offset=2`,
+		`/offset?limit=2&offset=4`,
+		`/offset?limit=2&offset=6`,
+	}, reqs)
+
+	// offsets without a limit stop at an empty page
+	items, reqs, err = collect(server.URL+`/offset`, &PaginateArgs{
+		Using:  `offset`,
+		Offset: 4,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{5, 6, 7}, ids(items))
+	assert.Equal([]string{`/offset?offset=4`, `/offset?offset=7`}, reqs)
+
+	// whole pages
+	items, reqs, err = collect(server.URL+`/link`, &PaginateArgs{
+		Pages: true,
+	})
+
+	assert.NoError(err)
+	assert.Len(items, 3)
+	assert.Len(reqs, 3)
+	assert.Equal(http.StatusOK, items[0].(*HttpResponse).Status)
+	assert.Equal(server.URL+`/link?page=3`, items[2].(*HttpResponse).URL)
+
+	// the number of pages is limited
+	items, reqs, err = collect(server.URL+`/link`, &PaginateArgs{
+		MaxPages: 2,
+	})
+
+	assert.NoError(err)
+	assert.Equal([]int{1, 2, 3, 4, 5, 6}, ids(items))
+	assert.Len(reqs, 2)
+
+	// pages are only requested when they're needed
+	requests = nil
+
+	paginator, err := client.Paginate(server.URL+`/link`, nil)
+	assert.NoError(err)
+	assert.Empty(requests)
+
+	for i := 0; i < 4; i++ {
+		_, ok, err := paginator.Next()
+		assert.NoError(err)
+		assert.True(ok)
+	}
+
+	assert.Len(requests, 2)
+
+	// errors
+	_, _, err = collect(server.URL+`/object`, nil)
+	assert.Error(err)
+
+	_, _, err = collect(server.URL+`/object`, &PaginateArgs{
+		Items: `items.0`,
+	})
+
+	assert.Error(err)
+
+	_, _, err = collect(server.URL+`/object`, &PaginateArgs{
+		Using: `cursor`,
+	})
+
+	assert.Error(err)
+
+	_, _, err = collect(server.URL+`/object`, &PaginateArgs{
+		Using: `magic`,
+	})
+
+	assert.Error(err)
+}
+
+func TestLinkRelations(t *testing.T) {
+	assert := require.New(t)
+
+	assert.Equal(map[string]string{
+		`next`:  `https://example.com/items?page=2&a=1,2`,
+		`last`:  `https://example.com/items?page=9`,
+		`first`: `/items?page=1`,
+		`start`: `/items?page=1`,
+	}, linkRelations([]string{
+		`<https://example.com/items?page=2&a=1,2>; rel="next", <https://example.com/items?page=9>; rel=last`,
+		`</items?page=1>; title="First"; REL="first start"`,
+		`<https://example.com/items?page=3>; rel="next"`,
+	}))
+
+	assert.Empty(linkRelations(nil))
+	assert.Empty(linkRelations([]string{`garbage`, `<unterminated; rel="next"`}))
+}
@@ -0,0 +1,273 @@
+package http
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/PerformLine/go-stockutil/maputil"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+// String values with this prefix are treated as the path of a file to upload.
+var MultipartFilePrefix = `file:`
+
+// a single part of a multipart/form-data body
+type formPart struct {
+	field       string
+	filename    string
+	contentType string
+	value       string
+	reader      io.Reader
+}
+
+func (self *formPart) isFile() bool {
+	return self.reader != nil
+}
+
+// Encode the given map of fields as a multipart/form-data body.  The body is streamed as it is read,
+// so files are never held in memory all at once (requests that are retried encode it again for each
+// attempt.)  It is only read into memory if it has to be signed (as with aws authentication), or if
+// it has to be sent more than once and contains readers, which can only be read once.  Field values
+// can be:
+//
+//   - strings (or any other scalar value), which are sent as regular fields
+//   - strings starting with "file:", which upload the file at the path that follows
+//   - byte slices or readers, which are uploaded as a file named after the field
+//   - objects with the keys "file" (a path) or "content" (a string, byte slice, or reader) and
+//     optionally "filename" and "content_type", to control how the file is uploaded
+//   - arrays of any of the above, which send the field several times
+//
+// If a boundary is given, it is used to separate the parts instead of a random one.
+func (self *Commands) encodeMultipart(body interface{}, boundary string) (io.Reader, string, error) {
+	if !typeutil.IsMap(body) {
+		return nil, ``, fmt.Errorf("multipart request bodies must be an object, got %T", body)
+	}
+
+	var fields = maputil.M(body).MapNative()
+	var parts = make([]*formPart, 0)
+	var names = maputil.StringKeys(fields)
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		var values []interface{}
+
+		if value := fields[name]; typeutil.IsArray(value) {
+			if _, ok := value.([]byte); ok {
+				values = []interface{}{value}
+			} else {
+				values = sliceutil.Sliceify(value)
+			}
+		} else {
+			values = []interface{}{value}
+		}
+
+		for _, value := range values {
+			if part, err := self.newFormPart(name, value); err == nil {
+				parts = append(parts, part)
+			} else {
+				closeFormParts(parts)
+				return nil, ``, fmt.Errorf("field %q: %v", name, err)
+			}
+		}
+	}
+
+	var pr, pw = io.Pipe()
+	var writer = multipart.NewWriter(pw)
+
+	if boundary != `` {
+		if err := writer.SetBoundary(boundary); err != nil {
+			closeFormParts(parts)
+			return nil, ``, err
+		}
+	}
+
+	go func() {
+		defer closeFormParts(parts)
+		pw.CloseWithError(writeFormParts(writer, parts))
+	}()
+
+	return pr, writer.FormDataContentType(), nil
+}
+
+// Return a function that encodes the given body again (with the boundary from the given content
+// type) so that it can be sent more than once, or nil if it can't be because some of its values are
+// readers (which can only be read once.)
+func (self *Commands) multipartReopener(body interface{}, contentType string) func() (io.ReadCloser, error) {
+	if hasFormReaders(body) {
+		return nil
+	}
+
+	var _, params, _ = mime.ParseMediaType(contentType)
+
+	return func() (io.ReadCloser, error) {
+		if r, _, err := self.encodeMultipart(body, params[`boundary`]); err == nil {
+			return r.(io.ReadCloser), nil
+		} else {
+			return nil, err
+		}
+	}
+}
+
+func hasFormReaders(value interface{}) bool {
+	switch value.(type) {
+	case []byte:
+		return false
+	case io.Reader:
+		return true
+	}
+
+	if typeutil.IsMap(value) {
+		for _, v := range maputil.M(value).MapNative() {
+			if hasFormReaders(v) {
+				return true
+			}
+		}
+	} else if typeutil.IsArray(value) {
+		for _, v := range sliceutil.Sliceify(value) {
+			if hasFormReaders(v) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (self *Commands) newFormPart(field string, value interface{}) (*formPart, error) {
+	var part = &formPart{
+		field: field,
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return part, nil
+
+	case string:
+		if strings.HasPrefix(v, MultipartFilePrefix) {
+			return part, self.openFormFile(part, strings.TrimPrefix(v, MultipartFilePrefix))
+		}
+
+		part.value = v
+
+	case []byte:
+		part.reader = strings.NewReader(string(v))
+		part.filename = field
+
+	case io.Reader:
+		part.reader = v
+		part.filename = field
+
+	default:
+		if typeutil.IsMap(v) {
+			var opts = maputil.M(v)
+
+			if path := opts.String(`file`); path != `` {
+				if err := self.openFormFile(part, path); err != nil {
+					return nil, err
+				}
+			} else if content := opts.Get(`content`).Value; content != nil {
+				if r, ok := content.(io.Reader); ok {
+					part.reader = r
+				} else if b, ok := content.([]byte); ok {
+					part.reader = strings.NewReader(string(b))
+				} else {
+					part.reader = strings.NewReader(typeutil.String(content))
+				}
+
+				part.filename = field
+			} else {
+				return nil, fmt.Errorf("file parts must specify either \"file\" or \"content\"")
+			}
+
+			if filename := opts.String(`filename`); filename != `` {
+				part.filename = filename
+			}
+
+			if contentType := opts.String(`content_type`); contentType != `` {
+				part.contentType = contentType
+			}
+		} else {
+			part.value = typeutil.String(v)
+		}
+	}
+
+	return part, nil
+}
+
+func (self *Commands) openFormFile(part *formPart, path string) error {
+	var rc io.ReadCloser
+
+	if self.env != nil {
+		if r, err := self.env.Open(path); err == nil {
+			rc = r
+		} else {
+			return err
+		}
+	} else if file, err := os.Open(path); err == nil {
+		rc = file
+	} else {
+		return err
+	}
+
+	part.reader = rc
+	part.filename = filepath.Base(path)
+	part.contentType = mime.TypeByExtension(filepath.Ext(path))
+
+	return nil
+}
+
+func writeFormParts(writer *multipart.Writer, parts []*formPart) error {
+	for _, part := range parts {
+		if part.isFile() {
+			var header = make(textproto.MIMEHeader)
+			var contentType = part.contentType
+
+			if contentType == `` {
+				contentType = `application/octet-stream`
+			}
+
+			header.Set(`Content-Disposition`, fmt.Sprintf(
+				`form-data; name="%s"; filename="%s"`,
+				escapeQuotes(part.field),
+				escapeQuotes(part.filename),
+			))
+
+			header.Set(`Content-Type`, contentType)
+
+			if w, err := writer.CreatePart(header); err == nil {
+				if _, err := io.Copy(w, part.reader); err != nil {
+					return err
+				}
+			} else {
+				return err
+			}
+		} else if err := writer.WriteField(part.field, part.value); err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func closeFormParts(parts []*formPart) {
+	for _, part := range parts {
+		if closer, ok := part.reader.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
@@ -0,0 +1,331 @@
+package http
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/log"
+	"github.com/PerformLine/go-stockutil/maputil"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+	defaults "github.com/mcuadros/go-defaults"
+)
+
+// The ways of finding the next page of a paginated API.
+const (
+	// follow the URL in the Link header with rel="next"
+	PaginateByLink = `link`
+
+	// send a cursor taken from each page's body with the request for the next page
+	PaginateByCursor = `cursor`
+
+	// increase an offset parameter by the number of items in each page
+	PaginateByOffset = `offset`
+)
+
+type PaginateArgs struct {
+	// How to find the next page: "link" (the default) follows Link headers with rel="next"; "cursor"
+	// sends the value found at the Cursor path in each page's body; and "offset" adds the number of
+	// items in each page to the OffsetParam parameter.  If Cursor is given, defaults to "cursor".
+	Using string `json:"using"`
+
+	// The path to the list of items in each page's body (e.g.: "data.results").  If not given, the
+	// body must be a list.
+	Items string `json:"items"`
+
+	// Yield each page (as returned by http::get) instead of the items in it.
+	Pages bool `json:"pages"`
+
+	// The path to the next page's cursor in each page's body (e.g.: "meta.next_cursor").  Pagination
+	// ends when there is no cursor.  Cursors that are URLs are requested as the next page.
+	Cursor string `json:"cursor"`
+
+	// The query string parameter that cursors are sent in.
+	CursorParam string `json:"cursor_param" default:"cursor"`
+
+	// The query string parameter that the offset of the first item of each page is sent in.
+	OffsetParam string `json:"offset_param" default:"offset"`
+
+	// The offset of the first item to request.
+	Offset int `json:"offset"`
+
+	// The query string parameter that the number of items per page is sent in.
+	LimitParam string `json:"limit_param" default:"limit"`
+
+	// The number of items to request per page.  When paginating by offset, a page with fewer items
+	// than this is the last one.
+	Limit int `json:"limit"`
+
+	// Stop after requesting this many pages.
+	MaxPages int `json:"max_pages" default:"100"`
+
+	// Headers that will be sent with each request.
+	Headers map[string]interface{} `json:"headers"`
+
+	// Query string parameters that will be added to the URL of the first page (and every page when
+	// paginating by offset or by a cursor that isn't a URL.)
+	Params map[string]interface{} `json:"params"`
+
+	// The name of a session (created with http::session) to make the requests with.
+	Session string `json:"session"`
+
+	// Credentials to authenticate the requests with.
+	Auth *AuthArgs `json:"auth"`
+
+	// The amount of time to wait for each request to complete.
+	Timeout time.Duration `json:"timeout"`
+
+	// The number of times to retry each request if it fails to connect or returns a retryable status.
+	Retries *int `json:"retries"`
+}
+
+// Iterates over the items (or pages) of a paginated API, requesting each page only once the items
+// in the previous one have been used.
+type Paginator struct {
+	commands *Commands
+	args     *PaginateArgs
+	url      string
+	cursor   string
+	offset   int
+	pages    int
+	pending  []interface{}
+	done     bool
+}
+
+// Iterate over every item in a paginated API, e.g.: `loop $item in http::paginate '...' { ... }`.
+// Pages are requested as the loop reaches them, so breaking out of the loop early stops any more
+// from being requested.
+func (self *Commands) Paginate(url string, args *PaginateArgs) (*Paginator, error) {
+	if args == nil {
+		args = &PaginateArgs{}
+	}
+
+	defaults.SetDefaults(args)
+
+	if args.Using == `` {
+		if args.Cursor != `` {
+			args.Using = PaginateByCursor
+		} else {
+			args.Using = PaginateByLink
+		}
+	}
+
+	switch args.Using = strings.ToLower(args.Using); args.Using {
+	case PaginateByLink, PaginateByOffset:
+		break
+	case PaginateByCursor:
+		if args.Cursor == `` {
+			return nil, fmt.Errorf("paginating by cursor requires the path to the cursor")
+		}
+	default:
+		return nil, fmt.Errorf("cannot paginate using %q", args.Using)
+	}
+
+	return &Paginator{
+		commands: self,
+		args:     args,
+		url:      url,
+		offset:   args.Offset,
+		pending:  make([]interface{}, 0),
+	}, nil
+}
+
+// Return the next item (or page), requesting the next page if necessary.
+func (self *Paginator) Next() (interface{}, bool, error) {
+	for len(self.pending) == 0 {
+		if self.done {
+			return nil, false, nil
+		} else if err := self.fetch(); err != nil {
+			return nil, false, err
+		}
+	}
+
+	var item = self.pending[0]
+
+	self.pending = self.pending[1:]
+	return item, true, nil
+}
+
+// request the next page, queue up its items, and work out where the page after it is
+func (self *Paginator) fetch() error {
+	if self.args.MaxPages > 0 && self.pages >= self.args.MaxPages {
+		log.Warningf("friendscript/http: stopped paginating %v after %d pages", self.url, self.pages)
+		self.done = true
+		return nil
+	}
+
+	var args = self.args
+	var reqargs = &RequestArgs{
+		Headers: args.Headers,
+		Params:  make(map[string]interface{}),
+		Session: args.Session,
+		Auth:    args.Auth,
+		Timeout: args.Timeout,
+		Retries: args.Retries,
+	}
+
+	// next page URLs already include whatever parameters they need
+	if self.pages == 0 || args.Using == PaginateByOffset || (args.Using == PaginateByCursor && !isURL(self.cursor)) {
+		for k, v := range args.Params {
+			reqargs.Params[k] = v
+		}
+	}
+
+	switch args.Using {
+	case PaginateByCursor:
+		if self.cursor != `` && !isURL(self.cursor) {
+			reqargs.Params[args.CursorParam] = self.cursor
+		}
+
+	case PaginateByOffset:
+		reqargs.Params[args.OffsetParam] = self.offset
+
+		if args.Limit > 0 {
+			reqargs.Params[args.LimitParam] = args.Limit
+		}
+	}
+
+	ex, err := self.commands.send(`GET`, self.url, reqargs)
+
+	if err != nil {
+		return err
+	}
+
+	page, err := buildResponse(ex)
+
+	if err != nil {
+		return err
+	}
+
+	self.pages += 1
+
+	var items []interface{}
+
+	if !args.Pages || args.Using == PaginateByOffset {
+		if items, err = pageItems(page.Body, args.Items); err != nil {
+			return fmt.Errorf("page %d of %v: %v", self.pages, self.url, err)
+		}
+	}
+
+	if args.Pages {
+		self.pending = append(self.pending, page)
+	} else {
+		self.pending = append(self.pending, items...)
+	}
+
+	// work out where the next page is (if there is one)
+	switch args.Using {
+	case PaginateByLink:
+		if next, ok := linkRelations(ex.response.Header.Values(`Link`))[`next`]; ok {
+			self.url = resolveReference(page.URL, next)
+		} else {
+			self.done = true
+		}
+
+	case PaginateByCursor:
+		var cursor = typeutil.String(maputil.DeepGet(page.Body, strings.Split(args.Cursor, `.`)))
+
+		// a cursor that doesn't change would request the same page forever
+		if cursor == `` || cursor == self.cursor {
+			self.done = true
+		} else if self.cursor = cursor; isURL(cursor) {
+			self.url = resolveReference(page.URL, cursor)
+		}
+
+	case PaginateByOffset:
+		if len(items) == 0 || (args.Limit > 0 && len(items) < args.Limit) {
+			self.done = true
+		}
+
+		self.offset += len(items)
+	}
+
+	return nil
+}
+
+// return the list of items at the given path in a page's body
+func pageItems(body interface{}, path string) ([]interface{}, error) {
+	var items = body
+
+	if path != `` {
+		items = maputil.DeepGet(body, strings.Split(path, `.`))
+	}
+
+	if items == nil {
+		return make([]interface{}, 0), nil
+	} else if typeutil.IsArray(items) {
+		return sliceutil.Sliceify(items), nil
+	} else if path != `` {
+		return nil, fmt.Errorf("%q is not a list", path)
+	} else {
+		return nil, fmt.Errorf("response body is not a list (specify where the items are with 'items')")
+	}
+}
+
+// Return the target of each relation in the given Link header values (RFC 8288), e.g.:
+// `<https://example.com/items?page=2>; rel="next"`.
+func linkRelations(values []string) map[string]string {
+	var links = make(map[string]string)
+
+	for _, value := range values {
+		for {
+			var start = strings.Index(value, `<`)
+
+			if start < 0 {
+				break
+			}
+
+			var end = strings.Index(value[start:], `>`) + start
+
+			if end < start {
+				break
+			}
+
+			var target = value[start+1 : end]
+			var params string
+
+			// parameters run until the start of the next link
+			if value = value[end+1:]; strings.Contains(value, `<`) {
+				params, value = stringutil.SplitPair(value, `<`)
+				value = `<` + value
+			} else {
+				params, value = value, ``
+			}
+
+			for _, param := range strings.Split(params, `;`) {
+				key, rels := stringutil.SplitPair(strings.TrimSpace(param), `=`)
+
+				if !strings.EqualFold(strings.TrimSpace(key), `rel`) {
+					continue
+				}
+
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(rels), `",`)) {
+					if rel = strings.ToLower(rel); links[rel] == `` {
+						links[rel] = target
+					}
+				}
+			}
+		}
+	}
+
+	return links
+}
+
+// report whether a cursor is a URL (absolute, or relative to the root of the current one)
+func isURL(value string) bool {
+	return strings.HasPrefix(value, `http://`) || strings.HasPrefix(value, `https://`) || strings.HasPrefix(value, `/`)
+}
+
+// resolve a (possibly relative) URL against the URL of the page it was found in
+func resolveReference(base string, ref string) string {
+	if b, err := url.Parse(base); err == nil {
+		if r, err := url.Parse(ref); err == nil {
+			return b.ResolveReference(r).String()
+		}
+	}
+
+	return ref
+}
@@ -0,0 +1,115 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// retry requests that failed because a connection could not be made (or was lost)
+	RetryOnConnection = `connection`
+
+	// retry requests that failed because they took longer than their timeout
+	RetryOnTimeout = `timeout`
+)
+
+// return which kinds of errors the given request should be retried for
+func retryConditions(reqargs *RequestArgs) (map[string]bool, error) {
+	var conditions = make(map[string]bool)
+
+	for _, condition := range strings.Split(reqargs.RetryOn, `,`) {
+		switch condition = strings.TrimSpace(strings.ToLower(condition)); condition {
+		case RetryOnConnection, RetryOnTimeout:
+			conditions[condition] = true
+		case ``, `none`:
+			continue
+		default:
+			return nil, fmt.Errorf("invalid retry_on condition %q", condition)
+		}
+	}
+
+	return conditions, nil
+}
+
+// return whether a request that returned the given response or error should be attempted again
+func (self *Commands) shouldRetry(reqargs *RequestArgs, conditions map[string]bool, response *http.Response, err error) bool {
+	// requests that were cancelled on purpose (e.g.: by a timeout block) are never retried
+	if self.context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		var nerr net.Error
+
+		if errors.As(err, &nerr) && nerr.Timeout() || errors.Is(err, context.DeadlineExceeded) {
+			return conditions[RetryOnTimeout]
+		}
+
+		var operr *net.OpError
+
+		if errors.As(err, &operr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return conditions[RetryOnConnection]
+		}
+
+		return false
+	}
+
+	return response != nil && reqargs.RetryStatuses != `` && !isErrorStatus(response.StatusCode, reqargs.RetryStatuses)
+}
+
+// Return how long to wait before making the next attempt at a request.  The delay doubles with each
+// attempt (up to RetryMaxDelay), and a random amount of up to half of it is subtracted so that many
+// clients retrying at once don't all do so at the same time.  If the server said how long to wait
+// with a Retry-After header, that is used instead.
+func retryDelay(reqargs *RequestArgs, attempt int, response *http.Response) time.Duration {
+	var delay = reqargs.RetryDelay
+
+	if response != nil {
+		if after, ok := parseRetryAfter(response.Header.Get(`Retry-After`)); ok {
+			if reqargs.RetryMaxDelay > 0 && after > reqargs.RetryMaxDelay {
+				return reqargs.RetryMaxDelay
+			}
+
+			return after
+		}
+	}
+
+	for i := 1; i < attempt && (reqargs.RetryMaxDelay <= 0 || delay < reqargs.RetryMaxDelay); i++ {
+		delay *= 2
+	}
+
+	if reqargs.RetryMaxDelay > 0 && delay > reqargs.RetryMaxDelay {
+		delay = reqargs.RetryMaxDelay
+	}
+
+	if half := int64(delay / 2); half > 0 {
+		delay -= time.Duration(rand.Int63n(half + 1))
+	}
+
+	return delay
+}
+
+// parse the value of a Retry-After header, which is either a number of seconds or an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value = strings.TrimSpace(value); value == `` {
+		return 0, false
+	} else if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	} else if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d, true
+		}
+
+		return 0, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,383 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// A named set of request options and cookies that are shared by every request made with it.
+type Session struct {
+	Name     string
+	Defaults *RequestArgs
+	jar      *sessionJar
+}
+
+// A cookie stored in a session.
+type Cookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Domain   string     `json:"domain"`
+	Path     string     `json:"path"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+	HttpOnly bool       `json:"http_only,omitempty"`
+	HostOnly bool       `json:"host_only,omitempty"`
+}
+
+func (self *Cookie) key() string {
+	return self.Domain + `|` + self.Path + `|` + self.Name
+}
+
+func (self *Cookie) expired() bool {
+	return self.Expires != nil && !self.Expires.After(time.Now())
+}
+
+// the URL that this cookie would be sent to, used when adding it to a cookie jar
+func (self *Cookie) url() *url.URL {
+	var u = &url.URL{
+		Scheme: `http`,
+		Host:   self.Domain,
+		Path:   self.Path,
+	}
+
+	if self.Secure {
+		u.Scheme = `https`
+	}
+
+	return u
+}
+
+func (self *Cookie) httpCookie() *http.Cookie {
+	var cookie = &http.Cookie{
+		Name:     self.Name,
+		Value:    self.Value,
+		Path:     self.Path,
+		Secure:   self.Secure,
+		HttpOnly: self.HttpOnly,
+	}
+
+	if !self.HostOnly {
+		cookie.Domain = self.Domain
+	}
+
+	if self.Expires != nil {
+		cookie.Expires = *self.Expires
+	}
+
+	return cookie
+}
+
+// wraps a cookie jar to keep a record of every cookie stored in it, since a cookiejar.Jar has no
+// way of listing its contents
+type sessionJar struct {
+	*cookiejar.Jar
+	cookies map[string]*Cookie
+	lock    sync.Mutex
+}
+
+func newSessionJar() *sessionJar {
+	jar, _ := cookiejar.New(nil)
+
+	return &sessionJar{
+		Jar:     jar,
+		cookies: make(map[string]*Cookie),
+	}
+}
+
+func (self *sessionJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	self.Jar.SetCookies(u, cookies)
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	for _, c := range cookies {
+		var cookie = newCookie(u, c)
+
+		if cookie.expired() {
+			delete(self.cookies, cookie.key())
+		} else {
+			self.cookies[cookie.key()] = cookie
+		}
+	}
+}
+
+// return all unexpired cookies, or only those that would be sent to the given URL
+func (self *sessionJar) list(u *url.URL) []*Cookie {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	var sent map[string]bool
+	var out = make([]*Cookie, 0)
+
+	if u != nil {
+		sent = make(map[string]bool)
+
+		for _, c := range self.Jar.Cookies(u) {
+			sent[c.Name+`=`+c.Value] = true
+		}
+	}
+
+	for key, cookie := range self.cookies {
+		if cookie.expired() {
+			delete(self.cookies, key)
+		} else if sent == nil || sent[cookie.Name+`=`+cookie.Value] {
+			out = append(out, cookie)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].key() < out[j].key()
+	})
+
+	return out
+}
+
+// the directory of the request path, which is where cookies without a Path attribute apply (RFC 6265 5.1.4)
+func defaultCookiePath(u *url.URL) string {
+	if p := u.EscapedPath(); strings.HasPrefix(p, `/`) {
+		if i := strings.LastIndex(p, `/`); i > 0 {
+			return p[:i]
+		}
+	}
+
+	return `/`
+}
+
+// return the session that the given request should be made with (if any)
+func (self *Commands) requestSession(args *RequestArgs) (*Session, error) {
+	var name = self.defaults.Session
+
+	if args != nil && args.Session != `` {
+		name = args.Session
+	}
+
+	if name == `` {
+		return nil, nil
+	}
+
+	return self.getSession(name)
+}
+
+func (self *Commands) getSession(name string) (*Session, error) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if session, ok := self.sessions[name]; ok {
+		return session, nil
+	} else {
+		return nil, fmt.Errorf("no such HTTP session %q", name)
+	}
+}
+
+// Create a named session (or change the options of an existing one).  Requests made with the
+// "session" option set to this name share the given options (which take precedence over
+// http::defaults) and a cookie jar, so cookies set by responses are sent with later requests.
+func (self *Commands) Session(name string, args *RequestArgs) error {
+	if name == `` {
+		return fmt.Errorf("a session name is required")
+	} else if args == nil {
+		args = &RequestArgs{}
+	}
+
+	args.Session = ``
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if session, ok := self.sessions[name]; ok {
+		session.Defaults = args
+	} else {
+		self.sessions[name] = &Session{
+			Name:     name,
+			Defaults: args,
+			jar:      newSessionJar(),
+		}
+	}
+
+	return nil
+}
+
+// Discard the named session and all of its cookies.
+func (self *Commands) CloseSession(name string) error {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if _, ok := self.sessions[name]; ok {
+		delete(self.sessions, name)
+		return nil
+	} else {
+		return fmt.Errorf("no such HTTP session %q", name)
+	}
+}
+
+type CookiesArgs struct {
+	// Only return the cookies that would be sent with a request to this URL.
+	URL string `json:"url"`
+}
+
+// Return the cookies stored in the named session.
+func (self *Commands) Cookies(name string, args *CookiesArgs) ([]*Cookie, error) {
+	if args == nil {
+		args = &CookiesArgs{}
+	}
+
+	if session, err := self.getSession(name); err == nil {
+		if args.URL != `` {
+			if u, err := url.Parse(args.URL); err == nil {
+				return session.jar.list(u), nil
+			} else {
+				return nil, err
+			}
+		}
+
+		return session.jar.list(nil), nil
+	} else {
+		return nil, err
+	}
+}
+
+type ExportCookiesArgs struct {
+	// If given, the cookies will be written to this file as JSON.
+	Path string `json:"path"`
+}
+
+// Return all of the cookies stored in the named session, optionally writing them to a file that
+// can be loaded with http::import_cookies.
+func (self *Commands) ExportCookies(name string, args *ExportCookiesArgs) ([]*Cookie, error) {
+	if args == nil {
+		args = &ExportCookiesArgs{}
+	}
+
+	if cookies, err := self.Cookies(name, nil); err == nil {
+		if args.Path != `` {
+			if data, err := json.MarshalIndent(cookies, ``, `  `); err == nil {
+				if err := self.writeFile(args.Path, data); err != nil {
+					return nil, err
+				}
+			} else {
+				return nil, err
+			}
+		}
+
+		return cookies, nil
+	} else {
+		return nil, err
+	}
+}
+
+type ImportCookiesArgs struct {
+	// A list of cookies (as returned by http::export_cookies) to add to the session.
+	Cookies interface{} `json:"cookies"`
+
+	// A file (as written by http::export_cookies) to load cookies from.
+	Path string `json:"path"`
+}
+
+// Add cookies to the named session, returning the number of cookies that were added.
+func (self *Commands) ImportCookies(name string, args *ImportCookiesArgs) (int, error) {
+	if args == nil {
+		args = &ImportCookiesArgs{}
+	}
+
+	var cookies []*Cookie
+
+	if session, err := self.getSession(name); err == nil {
+		if args.Path != `` {
+			if data, err := self.readFile(args.Path); err == nil {
+				if err := json.Unmarshal(data, &cookies); err != nil {
+					return 0, fmt.Errorf("invalid cookie file: %v", err)
+				}
+			} else {
+				return 0, err
+			}
+		}
+
+		if args.Cookies != nil {
+			var more []*Cookie
+
+			if data, err := json.Marshal(args.Cookies); err == nil {
+				if err := json.Unmarshal(data, &more); err == nil {
+					cookies = append(cookies, more...)
+				} else {
+					return 0, fmt.Errorf("invalid cookies: %v", err)
+				}
+			} else {
+				return 0, err
+			}
+		}
+
+		var added int
+
+		for _, cookie := range cookies {
+			if cookie.Name == `` || cookie.Domain == `` || cookie.expired() {
+				continue
+			}
+
+			if cookie.Path == `` {
+				cookie.Path = `/`
+			}
+
+			session.jar.SetCookies(cookie.url(), []*http.Cookie{cookie.httpCookie()})
+			added += 1
+		}
+
+		return added, nil
+	} else {
+		return 0, err
+	}
+}
+
+func (self *Commands) writeFile(path string, data []byte) error {
+	var w io.Writer
+
+	if self.env != nil {
+		if _, writer, err := self.env.GetWriterForPath(path); err == nil {
+			w = writer
+		} else {
+			return err
+		}
+	}
+
+	if w == nil {
+		if file, err := os.Create(path); err == nil {
+			w = file
+		} else {
+			return err
+		}
+	}
+
+	if closer, ok := w.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+func (self *Commands) readFile(path string) ([]byte, error) {
+	var rc io.ReadCloser
+
+	if self.env != nil {
+		if r, err := self.env.GetReaderForPath(path); err == nil {
+			rc = r
+		} else {
+			return nil, err
+		}
+	} else if file, err := os.Open(path); err == nil {
+		rc = file
+	} else {
+		return nil, err
+	}
+
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
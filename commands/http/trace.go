@@ -0,0 +1,233 @@
+package http
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+// How long each phase of a request took.  If the request was redirected, the DNS, connect, and
+// TLS times include every hop.
+type Timing struct {
+	// Time spent resolving hostnames.
+	DNS time.Duration `json:"dns"`
+
+	// Time spent establishing TCP connections.
+	Connect time.Duration `json:"connect"`
+
+	// Time spent performing TLS handshakes.
+	TLS time.Duration `json:"tls"`
+
+	// Time from sending the request until the first byte of the response was received.
+	FirstByte time.Duration `json:"first_byte"`
+
+	// Time spent reading the response body.
+	Transfer time.Duration `json:"transfer"`
+
+	// Time from sending the request until the response body was read.
+	Total time.Duration `json:"total"`
+
+	// Whether the request was sent on a connection that was already open.
+	Reused bool `json:"reused"`
+}
+
+// A response that redirected the request elsewhere.
+type Redirect struct {
+	// The URL that was requested.
+	URL string `json:"url"`
+
+	// The numeric HTTP status code of the response.
+	Status int `json:"status"`
+
+	// The headers of the response.
+	Headers map[string]interface{} `json:"headers"`
+}
+
+// the outcome of sending a request: the final response, along with how we got it
+type exchange struct {
+	response  *http.Response
+	reqargs   *RequestArgs
+	attempts  int
+	tracer    *tracer
+	redirects []*Redirect
+	cookies   []*Cookie
+}
+
+// record the time taken by each phase of a single attempt at a request
+type tracer struct {
+	timing   Timing
+	start    time.Time
+	dnsStart time.Time
+	dialing  map[string]time.Time
+	tlsStart time.Time
+	lock     sync.Mutex
+}
+
+func newTracer() *tracer {
+	return &tracer{
+		start:   time.Now(),
+		dialing: make(map[string]time.Time),
+	}
+}
+
+func (self *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.timing.DNS += time.Since(self.dnsStart)
+		},
+		ConnectStart: func(network string, addr string) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.dialing[network+addr] = time.Now()
+		},
+		ConnectDone: func(network string, addr string, err error) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+
+			// only count the connection that was actually used (several may be tried at once)
+			if started, ok := self.dialing[network+addr]; ok && err == nil {
+				self.timing.Connect += time.Since(started)
+			}
+		},
+		TLSHandshakeStart: func() {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.timing.TLS += time.Since(self.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.timing.Reused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			self.lock.Lock()
+			defer self.lock.Unlock()
+			self.timing.FirstByte = time.Since(self.start)
+		},
+	}
+}
+
+// attach this tracer to the given request
+func (self *tracer) trace(req *http.Request) *http.Request {
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), self.clientTrace()))
+}
+
+// finish timing the request, given when we started reading the response body
+func (self *tracer) finish(transferStart time.Time) *Timing {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	var timing = self.timing
+
+	timing.Transfer = time.Since(transferStart)
+	timing.Total = time.Since(self.start)
+
+	return &timing
+}
+
+// Return the function that decides whether the client follows a redirect, which also records each
+// response that redirected the request.
+func redirectPolicy(reqargs *RequestArgs, ex *exchange) func(*http.Request, []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if reqargs.FollowRedirects != nil && !*reqargs.FollowRedirects {
+			return http.ErrUseLastResponse
+		} else if len(via) > reqargs.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", reqargs.MaxRedirects)
+		}
+
+		if response := req.Response; response != nil {
+			var from = via[len(via)-1].URL
+
+			ex.redirects = append(ex.redirects, &Redirect{
+				URL:     from.String(),
+				Status:  response.StatusCode,
+				Headers: responseHeaders(response.Header),
+			})
+
+			ex.cookies = append(ex.cookies, responseCookies(from, response)...)
+		}
+
+		return nil
+	}
+}
+
+// convert response headers into a map of (autotyped) values
+func responseHeaders(header http.Header) map[string]interface{} {
+	var out = make(map[string]interface{})
+
+	for k, vs := range header {
+		if len(vs) == 1 {
+			out[k] = typeutil.Auto(vs[0])
+		} else {
+			out[k] = sliceutil.Autotype(vs)
+		}
+	}
+
+	return out
+}
+
+// return the cookies set by the given response to a request for the given URL
+func responseCookies(u *url.URL, response *http.Response) []*Cookie {
+	var cookies = make([]*Cookie, 0)
+
+	for _, c := range response.Cookies() {
+		cookies = append(cookies, newCookie(u, c))
+	}
+
+	return cookies
+}
+
+// Represent a cookie set by a response to a request for the given URL, filling in the attributes
+// that default to values from the URL.
+func newCookie(u *url.URL, c *http.Cookie) *Cookie {
+	var cookie = &Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   strings.TrimPrefix(strings.ToLower(c.Domain), `.`),
+		Path:     c.Path,
+		Secure:   c.Secure,
+		HttpOnly: c.HttpOnly,
+	}
+
+	if cookie.Domain == `` {
+		cookie.Domain = u.Hostname()
+		cookie.HostOnly = true
+	}
+
+	if cookie.Path == `` || !strings.HasPrefix(cookie.Path, `/`) {
+		cookie.Path = defaultCookiePath(u)
+	}
+
+	if c.MaxAge < 0 {
+		var expires = time.Unix(0, 0)
+		cookie.Expires = &expires
+	} else if c.MaxAge > 0 {
+		var expires = time.Now().Add(time.Duration(c.MaxAge) * time.Second)
+		cookie.Expires = &expires
+	} else if !c.Expires.IsZero() {
+		var expires = c.Expires
+		cookie.Expires = &expires
+	}
+
+	return cookie
+}
@@ -0,0 +1,319 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/httputil"
+)
+
+// The TLS versions that can be given as the minimum version.
+var tlsVersions = map[string]uint16{
+	`1.0`: tls.VersionTLS10,
+	`1.1`: tls.VersionTLS11,
+	`1.2`: tls.VersionTLS12,
+	`1.3`: tls.VersionTLS13,
+}
+
+// transports are shared by all requests that use the same connection settings so that connections are reused
+type transportKey struct {
+	insecure       bool
+	caBundle       string
+	clientCert     string
+	clientKey      string
+	minTLSVersion  string
+	ciphers        string
+	proxy          string
+	noProxy        string
+	resolve        string
+	unixSocket     string
+	connectTimeout time.Duration
+}
+
+func newTransportKey(reqargs *RequestArgs) transportKey {
+	var resolve = make([]string, 0, len(reqargs.Resolve))
+
+	for host, address := range reqargs.Resolve {
+		resolve = append(resolve, fmt.Sprintf("%v=%v", host, address))
+	}
+
+	sort.Strings(resolve)
+
+	return transportKey{
+		insecure:       reqargs.DisableVerifySSL,
+		caBundle:       reqargs.CertificateBundle,
+		clientCert:     reqargs.ClientCertificate,
+		clientKey:      reqargs.ClientKey,
+		minTLSVersion:  reqargs.MinTLSVersion,
+		ciphers:        reqargs.CipherSuites,
+		proxy:          reqargs.Proxy,
+		noProxy:        reqargs.NoProxy,
+		resolve:        strings.Join(resolve, `,`),
+		unixSocket:     reqargs.UnixSocket,
+		connectTimeout: reqargs.ConnectTimeout,
+	}
+}
+
+func (self *Commands) transport(reqargs *RequestArgs) (*http.Transport, error) {
+	var key = newTransportKey(reqargs)
+
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if transport, ok := self.transports[key]; ok {
+		return transport, nil
+	}
+
+	var transport = http.DefaultTransport.(*http.Transport).Clone()
+
+	if err := self.configureTLS(transport.TLSClientConfig, reqargs); err != nil {
+		return nil, err
+	}
+
+	if proxy, err := proxyFunc(reqargs.Proxy, reqargs.NoProxy); err == nil {
+		transport.Proxy = proxy
+	} else {
+		return nil, err
+	}
+
+	transport.DialContext = dialFunc(reqargs)
+
+	self.transports[key] = transport
+	return transport, nil
+}
+
+func (self *Commands) configureTLS(config *tls.Config, reqargs *RequestArgs) error {
+	config.InsecureSkipVerify = reqargs.DisableVerifySSL
+
+	// specify CA bundle (if provided)
+	if reqargs.CertificateBundle != `` {
+		if pool, err := httputil.LoadCertPool(reqargs.CertificateBundle); err == nil {
+			config.RootCAs = pool
+		} else {
+			return err
+		}
+	}
+
+	// present a client certificate to servers that ask for one (mutual TLS)
+	if certFile := reqargs.ClientCertificate; certFile != `` {
+		var keyFile = reqargs.ClientKey
+
+		// the key can be in the same file as the certificate
+		if keyFile == `` {
+			keyFile = certFile
+		}
+
+		if certPEM, err := self.readFile(certFile); err == nil {
+			if keyPEM, err := self.readFile(keyFile); err == nil {
+				if cert, err := tls.X509KeyPair(certPEM, keyPEM); err == nil {
+					config.Certificates = []tls.Certificate{cert}
+				} else {
+					return fmt.Errorf("invalid client certificate: %v", err)
+				}
+			} else {
+				return err
+			}
+		} else {
+			return err
+		}
+	} else if reqargs.ClientKey != `` {
+		return fmt.Errorf("a client key requires a client certificate")
+	}
+
+	if v := reqargs.MinTLSVersion; v != `` {
+		if version, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(v), `tls`)]; ok {
+			config.MinVersion = version
+		} else {
+			return fmt.Errorf("invalid minimum TLS version %q", v)
+		}
+	}
+
+	if reqargs.CipherSuites != `` {
+		var available = make(map[string]uint16)
+
+		for _, suite := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			available[suite.Name] = suite.ID
+		}
+
+		for _, name := range strings.Split(reqargs.CipherSuites, `,`) {
+			if name = strings.ToUpper(strings.TrimSpace(name)); name == `` {
+				continue
+			} else if id, ok := available[name]; ok {
+				config.CipherSuites = append(config.CipherSuites, id)
+			} else {
+				return fmt.Errorf("unknown cipher suite %q", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Return the function used to pick a proxy for each request.  If no proxy is given, the proxy (if
+// any) is taken from the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.
+func proxyFunc(proxy string, noProxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch strings.ToLower(proxy) {
+	case ``:
+		return http.ProxyFromEnvironment, nil
+	case `none`, `direct`:
+		return nil, nil
+	}
+
+	if !strings.Contains(proxy, `://`) {
+		proxy = `http://` + proxy
+	}
+
+	proxyURL, err := url.Parse(proxy)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy: %v", err)
+	}
+
+	switch proxyURL.Scheme {
+	case `http`, `https`, `socks5`, `socks5h`:
+		break
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+
+	if noProxy == `` {
+		noProxy = os.Getenv(`NO_PROXY`)
+	}
+
+	if noProxy == `` {
+		noProxy = os.Getenv(`no_proxy`)
+	}
+
+	var exclusions = strings.Split(noProxy, `,`)
+
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL, exclusions) {
+			return nil, nil
+		}
+
+		return proxyURL, nil
+	}, nil
+}
+
+// Report whether requests to the given URL should not be proxied.  Exclusions are hostnames (which
+// also match their subdomains, with or without a leading "." or "*."), IP addresses, or CIDR
+// ranges, optionally followed by a port; or "*" to exclude everything.
+func bypassProxy(u *url.URL, exclusions []string) bool {
+	var host = strings.ToLower(u.Hostname())
+	var port = u.Port()
+	var ip = net.ParseIP(host)
+
+	if port == `` {
+		if u.Scheme == `https` {
+			port = `443`
+		} else {
+			port = `80`
+		}
+	}
+
+	for _, exclusion := range exclusions {
+		if exclusion = strings.ToLower(strings.TrimSpace(exclusion)); exclusion == `` {
+			continue
+		} else if exclusion == `*` {
+			return true
+		}
+
+		if _, network, err := net.ParseCIDR(exclusion); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		var exHost, exPort = exclusion, ``
+
+		if h, p, err := net.SplitHostPort(exclusion); err == nil {
+			exHost, exPort = h, p
+		}
+
+		if exPort != `` && exPort != port {
+			continue
+		}
+
+		if exIP := net.ParseIP(exHost); exIP != nil {
+			if ip != nil && exIP.Equal(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		exHost = strings.TrimPrefix(strings.TrimPrefix(exHost, `*`), `.`)
+
+		if host == exHost || strings.HasSuffix(host, `.`+exHost) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Return the function used to open connections, which connects to a Unix socket (if given) or to
+// the address that the host being connected to has been overridden with.
+func dialFunc(reqargs *RequestArgs) func(context.Context, string, string) (net.Conn, error) {
+	var dialer = &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	var socket = reqargs.UnixSocket
+	var overrides = make(map[string]string)
+
+	if reqargs.ConnectTimeout > 0 {
+		dialer.Timeout = reqargs.ConnectTimeout
+	}
+
+	for host, address := range reqargs.Resolve {
+		overrides[strings.ToLower(host)] = fmt.Sprintf("%v", address)
+	}
+
+	return func(ctx context.Context, network string, addr string) (net.Conn, error) {
+		if socket != `` {
+			return dialer.DialContext(ctx, `unix`, socket)
+		}
+
+		return dialer.DialContext(ctx, network, resolveAddress(overrides, addr))
+	}
+}
+
+// replace the given host:port address with an override for either the host and port or just the host
+func resolveAddress(overrides map[string]string, addr string) string {
+	if len(overrides) == 0 {
+		return addr
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+
+	if err != nil {
+		return addr
+	}
+
+	var target, ok = overrides[strings.ToLower(addr)]
+
+	if !ok {
+		if target, ok = overrides[strings.ToLower(host)]; !ok {
+			return addr
+		}
+	}
+
+	// overrides that don't specify a port connect to the one that was requested
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	} else {
+		return net.JoinHostPort(strings.Trim(target, `[]`), port)
+	}
+}
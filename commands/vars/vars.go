@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 
+	"github.com/PerformLine/friendscript/scripting"
 	"github.com/PerformLine/friendscript/utils"
 	"github.com/PerformLine/go-stockutil/maputil"
 	"github.com/PerformLine/go-stockutil/sliceutil"
@@ -39,8 +40,8 @@ func (self *Commands) Keys() ([]string, error) {
 }
 
 // Unset the value at the given key.
-func (self *Commands) Clear(key string) {
-	self.env.Scope().Set(key, nil)
+func (self *Commands) Clear(key string) error {
+	return self.env.Scope().TrySet(key, nil)
 }
 
 type GetArgs struct {
@@ -64,7 +65,7 @@ type InterpolateArgs struct {
 
 // Return a value interpolated with values from a scope or ones that are explicitly provided.
 func (self *Commands) Interpolate(format string, args *InterpolateArgs) (string, error) {
-	return self.env.Scope().Interpolate(format), nil
+	return scripting.CompileTemplate(format).Render(self.env.Scope())
 }
 
 type SetArgs struct {
@@ -89,7 +90,10 @@ func (self *Commands) Set(key string, args *SetArgs) (interface{}, error) {
 		}
 	}
 
-	self.env.Scope().Set(key, args.Value)
+	if err := self.env.Scope().TrySet(key, args.Value); err != nil {
+		return nil, err
+	}
+
 	return args.Value, nil
 }
 
@@ -137,8 +141,7 @@ func (self *Commands) Push(key string, args *PushArgs) error {
 		newValue = sliceutil.Sliceify(args.Value)
 	}
 
-	self.env.Scope().Set(key, newValue)
-	return nil
+	return self.env.Scope().TrySet(key, newValue)
 }
 
 // Take the last value from the array at key.  If key is an array, the last value of
@@ -150,18 +153,24 @@ func (self *Commands) Pop(key string) (interface{}, error) {
 	if existing := self.env.Scope().Get(key); existing != nil {
 		values := sliceutil.Sliceify(existing)
 
+		var err error
+
 		switch len(values) {
 		case 0:
 			// clear key, return nil
-			self.env.Scope().Set(key, nil)
+			err = self.env.Scope().TrySet(key, nil)
 		case 1:
 			// clear key, return only value
 			value = values[0]
-			self.env.Scope().Set(key, nil)
+			err = self.env.Scope().TrySet(key, nil)
 		default:
 			// set existing array to all but last item, return last item
 			value = values[0]
-			self.env.Scope().Set(key, values[1:])
+			err = self.env.Scope().TrySet(key, values[1:])
+		}
+
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -1,6 +1,8 @@
 package friendscript
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -15,6 +17,7 @@ import (
 
 	prompt "github.com/c-bata/go-prompt"
 	"github.com/fatih/color"
+	multierror "github.com/hashicorp/go-multierror"
 	cmdassert "github.com/PerformLine/friendscript/commands/assert"
 	"github.com/PerformLine/friendscript/commands/core"
 	cmdfile "github.com/PerformLine/friendscript/commands/file"
@@ -56,6 +59,15 @@ type Environment struct {
 	filterCommands  map[string]bool
 	pathWriters     []utils.PathWriterFunc
 	pathReaders     []utils.PathReaderFunc
+	deferred        [][]deferredBlocks
+	contexts        []context.Context
+	aliases         []map[string]string
+}
+
+// blocks registered by a defer statement, along with the scope they were registered in
+type deferredBlocks struct {
+	blocks []*scripting.Block
+	scope  *scripting.Scope
 }
 
 // Create a new scripting environment.
@@ -71,8 +83,13 @@ func NewEnvironment(data ...map[string]interface{}) *Environment {
 
 	environment.pushScope(scripting.NewScope(nil))
 
+	// values that can't be set (e.g.: a constant that was already given) are skipped
 	for _, d := range data {
-		environment.SetData(d)
+		for k, v := range d {
+			if err := environment.TrySet(k, v); err != nil {
+				log.Warningf("invalid environment data: not setting $%s: %v", k, err)
+			}
+		}
 	}
 
 	environment.RegisterModule(scripting.UnqualifiedModuleName, core.New(environment))
@@ -117,20 +134,15 @@ func NewEnvironment(data ...map[string]interface{}) *Environment {
 // Registers a command module to the given prefix.  If a module with the same prefix already exists,
 // it will be replaced with the given module.  Prefixes will be stripped of spaces and converted to
 // snake_case.  If an empty prefix is given, the default UnqualifiedModuleName ("core") will be used.
+// Prefixes may contain several names separated by "::" (e.g.: "aws::s3") to register a module
+// within a nested namespace.
 func (self *Environment) RegisterModule(prefix string, module Module) {
-	prefix = strings.TrimSpace(prefix)
-	prefix = stringutil.Underscore(prefix)
-
-	if prefix == `` {
-		prefix = scripting.UnqualifiedModuleName
-	}
-
-	self.modules[prefix] = module
+	self.modules[normalizeModuleName(prefix)] = module
 }
 
 // Removes a registered module at the given prefix.
 func (self *Environment) UnregisterModule(prefix string) {
-	delete(self.modules, prefix)
+	delete(self.modules, normalizeModuleName(prefix))
 }
 
 // Specify a command that should not be permitted to execute.
@@ -180,15 +192,39 @@ func (self *Environment) Modules() map[string]Module {
 	return modules
 }
 
-// Retrieve the named module.
+// Retrieve the named module.  Names of submodules (e.g.: "aws::s3") are either registered as-is,
+// or are retrieved from the closest registered parent module that implements SubmoduleProvider.
 func (self *Environment) Module(name string) (Module, bool) {
-	module, ok := self.modules[name]
-	return module, ok
+	name = normalizeModuleName(name)
+
+	if module, ok := self.modules[name]; ok {
+		return module, true
+	}
+
+	var parts = strings.Split(name, scripting.ModuleSeparator)
+
+	for i := len(parts) - 1; i > 0; i-- {
+		if module, ok := self.modules[strings.Join(parts[:i], scripting.ModuleSeparator)]; ok {
+			for _, part := range parts[i:] {
+				if provider, ok := module.(SubmoduleProvider); ok {
+					if module, ok = provider.Submodule(part); !ok {
+						return nil, false
+					}
+				} else {
+					return nil, false
+				}
+			}
+
+			return module, true
+		}
+	}
+
+	return nil, false
 }
 
 // Retrieve the named module, or panic if it is not registered.
 func (self *Environment) MustModule(name string) Module {
-	if module, ok := self.modules[name]; ok {
+	if module, ok := self.Module(name); ok {
 		return module
 	} else {
 		panic(fmt.Sprintf("Module '%v' is not registered to this Friendscript environment", name))
@@ -274,37 +310,232 @@ func (self *Environment) Evaluate(script *scripting.Friendscript, scope ...*scri
 		rootScope = self.Scope()
 	}
 
+	// scripts evaluated from within other scripts (e.g.: by the "run" command) must leave the
+	// calling script and its scope as they found them
+	if len(self.stack) > 0 && rootScope != self.Scope() {
+		defer func(caller *scripting.Friendscript) {
+			self.popScope()
+			self.script = caller
+		}(self.script)
+	}
+
 	self.script = script
 	self.pushScope(rootScope)
+	rootScope.ClearReturnValue()
+	self.aliases = append(self.aliases, make(map[string]string))
 
-	for _, block := range script.Blocks() {
-		if err := self.evaluateBlock(block); err != nil {
-			return self.Scope(), err
+	defer func() {
+		self.aliases = self.aliases[:len(self.aliases)-1]
+	}()
+
+	// deferred blocks run however evaluation ended, and their errors are added to the original one
+	var err = self.withDeferredFrame(func() error {
+		// nothing is evaluated unless all of the modules the script uses are available, and the
+		// script was given valid values for all of its parameters
+		if err := self.applyImports(script); err != nil {
+			return err
+		} else if err := self.applyParams(script); err != nil {
+			return err
+		}
+
+		for _, block := range script.Blocks() {
+			if err := self.evaluateBlock(block); err != nil {
+				return err
+			}
 		}
+
+		return nil
+	})
+
+	// a "return" statement ends evaluation of the script without error
+	if fc, ok := err.(*scripting.FlowControlErr); ok && fc.Type == scripting.FlowReturn {
+		self.Scope().SetReturnValue(fc.Value)
+		err = fc.Err
 	}
 
-	return self.Scope(), nil
+	return self.Scope(), err
 }
 
-func (self *Environment) Run(scriptName string, options *utils.RunOptions) (interface{}, error) {
-	var fsp = os.Getenv(`FRIENDSCRIPT_PATH`)
-	var searchPaths = sliceutil.CompactString(strings.Split(fsp, `:`))
+// Check that all of the modules declared by the given script's use statements are registered, and
+// make any aliases they declare available to the script.  All missing modules are reported together.
+func (self *Environment) applyImports(script *scripting.Friendscript) error {
+	var missing []string
+	var aliases = self.aliases[len(self.aliases)-1]
+
+	for _, imp := range script.Imports() {
+		if _, ok := self.Module(imp.Module); !ok {
+			missing = append(missing, imp.Module)
+		} else if imp.Alias != `` {
+			if existing, ok := aliases[imp.Alias]; ok && existing != normalizeModuleName(imp.Module) {
+				return fmt.Errorf("line %d: cannot use %q as an alias for %s: it is already an alias for %s", imp.Line, imp.Alias, imp.Module, existing)
+			}
 
-	scriptName = strings.TrimSuffix(scriptName, `.fs`)
+			aliases[imp.Alias] = normalizeModuleName(imp.Module)
+		}
+	}
 
-	if options == nil {
-		options = &utils.RunOptions{
-			Isolated: true,
-			BasePath: `.`,
+	if len(missing) > 0 {
+		return fmt.Errorf("script uses modules that are not available: %s", strings.Join(missing, `, `))
+	}
+
+	return nil
+}
+
+// Return the full name of the given module, replacing the first part of the name with the module
+// it refers to if the current script declared it as an alias.
+func (self *Environment) resolveModuleName(name string) string {
+	if len(self.aliases) > 0 {
+		var first, rest = stringutil.SplitPair(name, scripting.ModuleSeparator)
+
+		if target, ok := self.aliases[len(self.aliases)-1][first]; ok {
+			if rest != `` {
+				return target + scripting.ModuleSeparator + rest
+			}
+
+			return target
+		}
+	}
+
+	return name
+}
+
+// Check the values in the current scope against the parameters declared by the given script,
+// applying defaults and converting values to their declared types.  All missing and invalid
+// parameters are reported together.
+func (self *Environment) applyParams(script *scripting.Friendscript) error {
+	var merr *multierror.Error
+	var scope = self.Scope()
+
+	if params, err := script.Params(); err == nil {
+		for _, param := range params {
+			if value, err := param.Validate(scope.Get(param.Name)); err != nil {
+				merr = multierror.Append(merr, err)
+			} else if !scope.IsReadOnly(param.Name) {
+				if !scope.IsLocal(param.Name) {
+					scope.Declare(param.Name)
+				}
+
+				if err := scope.TrySet(param.Name, value); err != nil {
+					merr = multierror.Append(merr, err)
+				}
+			}
+		}
+	} else {
+		return err
+	}
+
+	if merr != nil {
+		merr.ErrorFormat = func(errs []error) string {
+			var messages = make([]string, len(errs))
+
+			for i, err := range errs {
+				messages[i] = err.Error()
+			}
+
+			return `invalid parameters: ` + strings.Join(messages, `; `)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// Call the given function with a new frame for defer statements to register blocks in.  The blocks
+// are evaluated when the function returns (even if it panics), and their errors are added to the
+// one it returned.
+func (self *Environment) withDeferredFrame(fn func() error) (err error) {
+	self.deferred = append(self.deferred, nil)
+
+	defer func() {
+		err = withDeferredErrors(err, self.evaluateDeferred())
+	}()
+
+	return fn()
+}
+
+// Evaluate the blocks registered by defer statements in the current frame (last-in, first-out), and
+// remove the frame.  All deferred blocks are evaluated even if some of them fail.
+func (self *Environment) evaluateDeferred() error {
+	if len(self.deferred) == 0 {
+		return nil
+	}
+
+	var merr *multierror.Error
+	var deferred = self.deferred[len(self.deferred)-1]
+
+	self.deferred = self.deferred[:len(self.deferred)-1]
+
+	if len(deferred) == 0 {
+		return nil
+	}
+
+	// cleanup still has to happen when the script was cancelled (e.g.: by an expired timeout)
+	self.contexts = append(self.contexts, context.Background())
+
+	defer func() {
+		self.contexts = self.contexts[:len(self.contexts)-1]
+	}()
+
+	for i := len(deferred) - 1; i >= 0; i-- {
+		self.pushScope(scripting.NewScope(deferred[i].scope))
+
+		if err := self.evaluateBody(deferred[i].blocks); err != nil {
+			// "return" only ends the deferred block it appears in
+			if fc, ok := err.(*scripting.FlowControlErr); !ok || fc.Type != scripting.FlowReturn {
+				merr = multierror.Append(merr, err)
+			} else if fc.Err != nil {
+				merr = multierror.Append(merr, fc.Err)
+			}
 		}
+
+		self.popScope()
 	}
 
+	return merr.ErrorOrNil()
+}
+
+// Evaluate the blocks that make up the body of a statement (e.g.: a loop iteration or conditional
+// branch), stopping at the first error.  Blocks deferred within the body run when it finishes.
+func (self *Environment) evaluateBody(blocks []*scripting.Block) error {
+	return self.withDeferredFrame(func() error {
+		for _, block := range blocks {
+			if err := self.evaluateBlock(block); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// combine the error (if any) that ended evaluation with the errors from the deferred blocks that ran
+// afterwards.  A pending break, continue, or return still happens, carrying the errors with it.
+func withDeferredErrors(err error, derr error) error {
+	if derr == nil {
+		return err
+	} else if err == nil {
+		return derr
+	} else if fc, ok := err.(*scripting.FlowControlErr); ok {
+		fc.Err = withDeferredErrors(fc.Err, derr)
+		return fc
+	} else {
+		return multierror.Append(err, derr)
+	}
+}
+
+// Return the path of the named script, looking for it in the given base path and then in each of
+// the directories in the FRIENDSCRIPT_PATH environment variable.
+func (self *Environment) locateScript(scriptName string, basePath string) (string, error) {
+	var fsp = os.Getenv(`FRIENDSCRIPT_PATH`)
+	var searchPaths = sliceutil.CompactString(strings.Split(fsp, `:`))
+
+	scriptName = strings.TrimSuffix(scriptName, `.fs`)
+
 	// if the script is an absolute path, then we won't be searching for anything
 	if filepath.IsAbs(scriptName) {
 		searchPaths = []string{scriptName}
 	} else {
 		// prepend the dirname of the calling script to the searchPaths
-		searchPaths = append([]string{options.BasePath}, searchPaths...)
+		searchPaths = append([]string{basePath}, searchPaths...)
 
 		// join all the search paths with the candidate script name
 		for i, sp := range searchPaths {
@@ -314,10 +545,37 @@ func (self *Environment) Run(scriptName string, options *utils.RunOptions) (inte
 
 	// find the file
 	for _, candidate := range searchPaths {
-		if !fileutil.IsNonemptyFile(candidate) {
-			continue
+		if fileutil.IsNonemptyFile(candidate) {
+			return candidate, nil
 		}
+	}
 
+	return ``, fmt.Errorf("could not locate script %q", scriptName)
+}
+
+// Return the parameters declared by the named script (which is found the same way as by Run), e.g.:
+// so that an application can ask for their values before running it.
+func (self *Environment) Params(scriptName string) ([]*scripting.Param, error) {
+	if path, err := self.locateScript(scriptName, `.`); err == nil {
+		if script, err := scripting.LoadFromFile(path); err == nil {
+			return script.Params()
+		} else {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+}
+
+func (self *Environment) Run(scriptName string, options *utils.RunOptions) (interface{}, error) {
+	if options == nil {
+		options = &utils.RunOptions{
+			Isolated: true,
+			BasePath: `.`,
+		}
+	}
+
+	if candidate, err := self.locateScript(scriptName, options.BasePath); err == nil {
 		var scope *scripting.Scope
 
 		if options.Isolated {
@@ -328,12 +586,16 @@ func (self *Environment) Run(scriptName string, options *utils.RunOptions) (inte
 
 		if len(options.Data) > 0 {
 			for k, v := range options.Data {
-				scope.Set(k, v)
+				if err := scope.TrySet(k, v); err != nil {
+					return nil, err
+				}
 			}
 		}
 
 		if res, err := self.EvaluateFile(candidate, scope); err == nil {
-			if options.ResultKey == `` {
+			if value, ok := res.ReturnValue(); ok {
+				return value, nil
+			} else if options.ResultKey == `` {
 				return res.MostRecentValue(), err
 			} else {
 				return res.Get(options.ResultKey), nil
@@ -341,9 +603,9 @@ func (self *Environment) Run(scriptName string, options *utils.RunOptions) (inte
 		} else {
 			return nil, err
 		}
+	} else {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("could not locate script %q", scriptName)
 }
 
 func (self *Environment) replCompleter(d prompt.Document) []prompt.Suggest {
@@ -431,6 +693,16 @@ func (self *Environment) pushScope(scope *scripting.Scope) {
 	// log.Debugf("PUSH scope(%d) is active", self.Scope().Level())
 }
 
+// Return the context that governs the commands currently being evaluated.  It is cancelled when
+// a surrounding timeout block expires, and commands that wait on I/O or time should stop when it is.
+func (self *Environment) Context() context.Context {
+	if n := len(self.contexts); n > 0 {
+		return self.contexts[n-1]
+	}
+
+	return context.Background()
+}
+
 func (self *Environment) Scope() *scripting.Scope {
 	if len(self.stack) > 0 {
 		return self.stack[len(self.stack)-1]
@@ -439,14 +711,25 @@ func (self *Environment) Scope() *scripting.Scope {
 	}
 }
 
+// Set the value of a variable in the current scope.  Values wrapped with scripting.Constant are
+// made read-only, and cannot be changed by scripts.  Values that can't be set (e.g.: because a
+// constant with the same name already exists) are logged and skipped.
 func (self *Environment) Set(key string, value interface{}) {
 	self.Scope().Set(key, value)
 }
 
+// Set the value of a variable in the current scope in the same way as Set, returning an error if
+// it could not be set.
+func (self *Environment) TrySet(key string, value interface{}) error {
+	return self.Scope().TrySet(key, value)
+}
+
 func (self *Environment) Get(key string, fallback ...interface{}) interface{} {
 	return self.Scope().Get(key, fallback...)
 }
 
+// Set several variables in the current scope at once.  Values wrapped with scripting.Constant are
+// made read-only, and cannot be changed by scripts.
 func (self *Environment) SetData(data map[string]interface{}) {
 	for k, v := range data {
 		self.Set(k, v)
@@ -479,6 +762,11 @@ func (self *Environment) evaluateBlock(block *scripting.Block) error {
 	switch block.Type() {
 	case scripting.StatementBlock:
 		for _, statement := range block.Statements() {
+			// stop evaluating if a surrounding timeout has expired
+			if err := self.Context().Err(); err != nil {
+				return err
+			}
+
 			if err := self.evaluateStatement(statement); err != nil {
 				return err
 			}
@@ -492,6 +780,32 @@ func (self *Environment) evaluateBlock(block *scripting.Block) error {
 			return scripting.NewFlowControl(scripting.FlowBreak, levels)
 		} else if levels := block.FlowContinue(); levels > 0 {
 			return scripting.NewFlowControl(scripting.FlowContinue, levels)
+		} else if expr, ok := block.FlowReturn(); ok {
+			var value interface{}
+
+			if expr != nil {
+				if v, err := expr.Value(); err == nil {
+					value = v
+				} else {
+					return err
+				}
+			}
+
+			return scripting.NewFlowReturn(value)
+		} else if expr, ok := block.FlowExit(); ok {
+			var status int64
+
+			if expr != nil {
+				if v, err := expr.Value(); err != nil {
+					return err
+				} else if status, err = stringutil.ConvertToInteger(v); err != nil {
+					return fmt.Errorf("invalid exit status: %v", err)
+				}
+			}
+
+			return &scripting.ExitError{
+				Status: int(status),
+			}
 		} else {
 			return fmt.Errorf("invalid flow control statement")
 		}
@@ -512,6 +826,37 @@ func (self *Environment) evaluateStatement(statement *scripting.Statement) error
 		_, err := self.evaluateConditional(statement.Conditional())
 		return err
 
+	case scripting.SwitchStatement:
+		return self.evaluateSwitch(statement.Switch())
+
+	case scripting.RetryStatement:
+		return self.evaluateRetry(statement.Retry())
+
+	case scripting.TimeoutStatement:
+		return self.evaluateTimeout(statement.Timeout())
+
+	case scripting.ParamStatement, scripting.UseStatement:
+		// parameters and imports are checked before the script is evaluated
+		if statement.IsTopLevel() {
+			return nil
+		} else if statement.Type() == scripting.UseStatement {
+			return errorWithSource(statement.SourceContext(), fmt.Errorf("use statements must be at the top level of a script"))
+		} else {
+			return errorWithSource(statement.SourceContext(), fmt.Errorf("param statements must be at the top level of a script"))
+		}
+
+	case scripting.DeferStatement:
+		if n := len(self.deferred); n > 0 {
+			self.deferred[n-1] = append(self.deferred[n-1], deferredBlocks{
+				blocks: statement.DeferredBlocks(),
+				scope:  self.Scope(),
+			})
+
+			return nil
+		} else {
+			return fmt.Errorf("defer can only be used while evaluating a script")
+		}
+
 	case scripting.LoopStatement:
 		return self.evaluateLoop(statement.Loop())
 
@@ -530,14 +875,20 @@ func (self *Environment) evaluateStatement(statement *scripting.Statement) error
 func (self *Environment) evaluateAssignment(assignment *scripting.Assignment, forceDeclare bool) error {
 	log.Debugf("ASSN %v", assignment)
 
-	// clear out all the left-hand side variables (if there isn't already one in this scope)
-	if assignment.Operator.ShouldPreclear() {
-		for _, lhs := range assignment.LeftHandSide {
-			if !self.Scope().IsLocal(lhs) {
-				if forceDeclare {
-					self.Scope().Declare(lhs)
-				} else {
-					self.Scope().Set(lhs, nil)
+	if assignment.Constant {
+		if assignment.Operator.String() != `=` {
+			return errorWithSource(assignment.SourceContext(), fmt.Errorf("constants must be assigned with '='"))
+		}
+	} else if assignment.Operator.ShouldPreclear() {
+		// clear out all the left-hand side variables (if there isn't already one in this scope)
+		for i := range assignment.LeftHandSide {
+			for _, lhs := range assignment.Targets(i) {
+				if !self.Scope().IsLocal(lhs) {
+					if forceDeclare {
+						self.Scope().Declare(lhs)
+					} else if err := self.Scope().TrySet(lhs, nil); err != nil {
+						return errorWithSource(assignment.SourceContext(), err)
+					}
 				}
 			}
 		}
@@ -551,12 +902,7 @@ func (self *Environment) evaluateAssignment(assignment *scripting.Assignment, fo
 			if totalLhsCount > 1 && typeutil.IsArray(rhs) {
 				for i, rhs := range sliceutil.Sliceify(rhs) {
 					if i < totalLhsCount {
-						if result, err := assignment.Operator.Evaluate(
-							self.Scope().Get(assignment.LeftHandSide[i]),
-							rhs,
-						); err == nil {
-							self.Scope().Set(assignment.LeftHandSide[i], result)
-						} else {
+						if err := self.assignTargets(assignment, i, rhs); err != nil {
 							return err
 						}
 					}
@@ -567,13 +913,12 @@ func (self *Environment) evaluateAssignment(assignment *scripting.Assignment, fo
 		}
 	}
 
-	for i, lhs := range assignment.LeftHandSide {
+	for i := range assignment.LeftHandSide {
 		if i < len(assignment.RightHandSide) {
-			if result, err := assignment.Operator.Evaluate(
-				self.Scope().Get(lhs),
-				assignment.RightHandSide[i],
-			); err == nil {
-				self.Scope().Set(lhs, result)
+			if rhs, err := assignment.RightHandSide[i].Value(); err == nil {
+				if err := self.assignTargets(assignment, i, rhs); err != nil {
+					return err
+				}
 			} else {
 				return err
 			}
@@ -583,6 +928,27 @@ func (self *Environment) evaluateAssignment(assignment *scripting.Assignment, fo
 	return nil
 }
 
+// apply the assignment operator to each of the values the nth left-hand side variable refers to
+func (self *Environment) assignTargets(assignment *scripting.Assignment, i int, rhs interface{}) error {
+	for _, lhs := range assignment.Targets(i) {
+		if result, err := assignment.Operator.Evaluate(self.Scope().Get(lhs), rhs); err == nil {
+			if assignment.Constant {
+				err = self.Scope().SetConstant(lhs, result)
+			} else {
+				err = self.Scope().TrySet(lhs, result)
+			}
+
+			if err != nil {
+				return errorWithSource(assignment.SourceContext(), err)
+			}
+		} else {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (self *Environment) evaluateDirective(directive *scripting.Directive) error {
 	switch directive.Type() {
 	case scripting.UnsetDirective:
@@ -601,6 +967,8 @@ func (self *Environment) evaluateDirective(directive *scripting.Directive) error
 func (self *Environment) evaluateCommand(command *scripting.Command, forceDeclare bool) (string, error) {
 	var modname, name = command.Name()
 
+	modname = self.resolveModuleName(modname)
+
 	// prevent the execution of disabled commands
 	if reject, _ := self.filterCommands[modname+`::`+name]; reject {
 		return ``, fmt.Errorf("Execution of the %s::%s command has been disabled", modname, name)
@@ -612,7 +980,7 @@ func (self *Environment) evaluateCommand(command *scripting.Command, forceDeclar
 
 	if first, rest, err := command.Args(); err == nil {
 		// locate the module this command belongs to
-		if module, ok := self.modules[modname]; ok {
+		if module, ok := self.Module(modname); ok {
 			// log.Debugf("CMND called %T(%v), %T(%v)", first, first, rest, rest)
 
 			// tell that module to execute the command, giving it the name and arguments
@@ -632,12 +1000,17 @@ func (self *Environment) evaluateCommand(command *scripting.Command, forceDeclar
 						evalscope.Declare(resultVar)
 					}
 
-					evalscope.Set(resultVar, result)
+					if err := evalscope.TrySet(resultVar, result); err != nil {
+						return ``, errorWithSource(ctx, err)
+					}
 
 					return resultVar, nil
 				}
 
 				return ``, nil
+			} else if roErr := new(scripting.ReadOnlyError); errors.As(err, &roErr) {
+				// point to the command that tried to change a constant
+				ctx.Error = errorWithSource(ctx, err)
 			} else {
 				ctx.Error = err
 			}
@@ -655,6 +1028,7 @@ func (self *Environment) evaluateCommand(command *scripting.Command, forceDeclar
 func (self *Environment) evaluateConditional(conditional *scripting.Conditional) (bool, error) {
 	var blocks = make([]*scripting.Block, 0)
 	var trueBranch bool
+	var err error
 	var conditionScope = scripting.NewScope(self.Scope())
 	self.pushScope(conditionScope)
 	defer self.popScope()
@@ -663,9 +1037,9 @@ func (self *Environment) evaluateConditional(conditional *scripting.Conditional)
 	case scripting.ConditionWithAssignment:
 		assignment, condition := conditional.WithAssignment()
 
-		if err := self.evaluateAssignment(assignment, true); err == nil {
+		if err = self.evaluateAssignment(assignment, true); err == nil {
 			result := condition.IsTrue()
-			blocks, trueBranch = self.evaluateConditionalGetBranch(conditional, result)
+			blocks, trueBranch, err = self.evaluateConditionalGetBranch(conditional, result)
 		} else {
 			return trueBranch, err
 		}
@@ -673,9 +1047,9 @@ func (self *Environment) evaluateConditional(conditional *scripting.Conditional)
 	case scripting.ConditionWithCommand:
 		command, condition := conditional.WithCommand()
 
-		if _, err := self.evaluateCommand(command, true); err == nil {
+		if _, err = self.evaluateCommand(command, true); err == nil {
 			result := condition.IsTrue()
-			blocks, trueBranch = self.evaluateConditionalGetBranch(conditional, result)
+			blocks, trueBranch, err = self.evaluateConditionalGetBranch(conditional, result)
 		} else {
 			return trueBranch, err
 		}
@@ -683,28 +1057,36 @@ func (self *Environment) evaluateConditional(conditional *scripting.Conditional)
 	case scripting.ConditionWithRegex:
 		expression, matchOp, rx := conditional.WithRegex()
 		result := matchOp.Evaluate(rx, expression)
-		blocks, trueBranch = self.evaluateConditionalGetBranch(conditional, result)
+
+		// make capture groups available to the statements in the branch
+		if captures := matchOp.Captures(rx, expression); captures != nil {
+			conditionScope.Declare(scripting.RegexMatchVariableName)
+
+			if err := conditionScope.TrySet(scripting.RegexMatchVariableName, captures); err != nil {
+				return false, err
+			}
+		}
+
+		blocks, trueBranch, err = self.evaluateConditionalGetBranch(conditional, result)
 
 	case scripting.ConditionWithComparator:
 		lhs, cmp, rhs := conditional.WithComparator()
 
 		result := cmp.Evaluate(lhs, rhs)
-		blocks, trueBranch = self.evaluateConditionalGetBranch(conditional, result)
+		blocks, trueBranch, err = self.evaluateConditionalGetBranch(conditional, result)
 
 	default:
 		return trueBranch, fmt.Errorf("Unrecognized Conditional type")
 	}
 
-	for _, block := range blocks {
-		if err := self.evaluateBlock(block); err != nil {
-			return trueBranch, err
-		}
+	if err != nil {
+		return trueBranch, err
 	}
 
-	return trueBranch, nil
+	return trueBranch, self.evaluateBody(blocks)
 }
 
-func (self *Environment) evaluateConditionalGetBranch(conditional *scripting.Conditional, result bool) ([]*scripting.Block, bool) {
+func (self *Environment) evaluateConditionalGetBranch(conditional *scripting.Conditional, result bool) ([]*scripting.Block, bool, error) {
 	var blocks = make([]*scripting.Block, 0)
 	var trueBranch bool
 
@@ -720,16 +1102,16 @@ func (self *Environment) evaluateConditionalGetBranch(conditional *scripting.Con
 		var tookElifBranch bool
 
 		for _, elif := range conditional.ElseIfConditions() {
+			// else-if branches are evaluated (condition and blocks) in their own scope, so
+			// there is nothing left to do here once one of them is taken
 			if t, err := self.evaluateConditional(elif); err == nil {
 				if t {
 					// log.Debugf("ELSE-IF %d branch", ei)
 					tookElifBranch = true
-					blocks = elif.IfBlocks()
 					break
 				}
 			} else {
-				log.Fatal(err)
-				return nil, false
+				return nil, false, err
 			}
 		}
 
@@ -739,12 +1121,152 @@ func (self *Environment) evaluateConditionalGetBranch(conditional *scripting.Con
 		}
 	}
 
-	return blocks, trueBranch
+	return blocks, trueBranch, nil
+}
+
+func (self *Environment) evaluateSwitch(sw *scripting.Switch) error {
+	subject, err := sw.Subject().Value()
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range sw.Cases() {
+		if ok, captures, err := c.Matches(subject); err != nil {
+			return err
+		} else if ok {
+			// like conditional branches, each case is evaluated in its own scope
+			var caseScope = scripting.NewScope(self.Scope())
+			self.pushScope(caseScope)
+			defer self.popScope()
+
+			// make capture groups available to the statements in regular expression cases
+			if captures != nil {
+				caseScope.Declare(scripting.RegexMatchVariableName)
+
+				if err := caseScope.TrySet(scripting.RegexMatchVariableName, captures); err != nil {
+					return err
+				}
+			}
+
+			return self.evaluateBody(c.Blocks())
+		}
+	}
+
+	return nil
+}
+
+func (self *Environment) evaluateRetry(retry *scripting.Retry) error {
+	attempts, err := retry.Attempts()
+
+	if err != nil {
+		return err
+	}
+
+	options, err := retry.Options()
+
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err = self.evaluateRetryAttempt(retry, attempt); err == nil {
+			return nil
+		}
+
+		// flow control, exits, and expired timeouts are never retried
+		switch err.(type) {
+		case *scripting.FlowControlErr, *scripting.ExitError:
+			return err
+		}
+
+		if self.Context().Err() != nil || attempt >= attempts || !options.ShouldRetry(err) {
+			return err
+		}
+
+		var delay = options.Delay(attempt)
+
+		log.Warningf("Attempt %d/%d failed, retrying in %v: %v", attempt, attempts, delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-self.Context().Done():
+			return err
+		}
+	}
+}
+
+// each attempt is evaluated in a fresh scope, so nothing set by a failed attempt leaks into the next
+func (self *Environment) evaluateRetryAttempt(retry *scripting.Retry, attempt int) error {
+	var attemptScope = scripting.NewScope(self.Scope())
+
+	attemptScope.Declare(scripting.RetryAttemptVariableName)
+
+	if err := attemptScope.TrySet(scripting.RetryAttemptVariableName, attempt); err != nil {
+		return err
+	}
+
+	self.pushScope(attemptScope)
+	defer self.popScope()
+
+	return self.evaluateBody(retry.Blocks())
+}
+
+func (self *Environment) evaluateTimeout(timeout *scripting.Timeout) error {
+	duration, err := timeout.Duration()
+
+	if err != nil {
+		return err
+	}
+
+	// blocks deferred within the timeout run after it, so that they aren't limited by it
+	return self.withDeferredFrame(func() error {
+		return self.evaluateTimeoutBlocks(timeout, duration)
+	})
+}
+
+func (self *Environment) evaluateTimeoutBlocks(timeout *scripting.Timeout, duration time.Duration) error {
+	var parent = self.Context()
+
+	ctx, cancel := context.WithTimeout(parent, duration)
+	defer cancel()
+
+	self.contexts = append(self.contexts, ctx)
+	self.pushScope(scripting.NewScope(self.Scope()))
+
+	defer func() {
+		self.popScope()
+		self.contexts = self.contexts[:len(self.contexts)-1]
+	}()
+
+	// commands are only interrupted if they watch utils.RuntimeContext; one that doesn't runs to
+	// completion, and the deadline is enforced once it returns
+	for _, block := range timeout.Blocks() {
+		if err := self.evaluateBlock(block); err != nil {
+			if self.timedOut(ctx, parent) {
+				return fmt.Errorf("timeout: did not complete within %v", duration)
+			}
+
+			return err
+		}
+	}
+
+	if self.timedOut(ctx, parent) {
+		return fmt.Errorf("timeout: did not complete within %v", duration)
+	}
+
+	return nil
+}
+
+// only report our own deadline; an expired outer timeout is reported by its own block
+func (self *Environment) timedOut(ctx context.Context, parent context.Context) bool {
+	return ctx.Err() == context.DeadlineExceeded && parent.Err() == nil
 }
 
 func (self *Environment) evaluateLoop(loop *scripting.Loop) error {
 	var i int
 	var sourceVar string
+	var sourceValue interface{}
 	var destVars []string
 	var loopScope = scripting.NewScope(self.Scope())
 
@@ -755,8 +1277,9 @@ func (self *Environment) evaluateLoop(loop *scripting.Loop) error {
 
 	// if we have an iterator, we have to initialize the values
 	if loop.Type() == scripting.IteratorLoop {
-		if s, d, err := self.evaluateLoopIterationStart(loop, loopScope); err == nil {
+		if s, v, d, err := self.evaluateLoopIterationStart(loop, loopScope); err == nil {
 			sourceVar = s
+			sourceValue = v
 			destVars = d
 
 			log.Debugf("Iterator initialized: %v -> %v", sourceVar, destVars)
@@ -770,69 +1293,96 @@ func (self *Environment) evaluateLoop(loop *scripting.Loop) error {
 LoopEval:
 	for loop.ShouldContinue() {
 		if loop.Type() == scripting.IteratorLoop {
-			iterVector := loopScope.Get(sourceVar)
-
-			if typeutil.IsMap(iterVector) {
-				remap := make([][]interface{}, 0)
-				keys := maputil.StringKeys(iterVector)
-				sort.Strings(keys)
-
-				for _, key := range keys {
-					remap = append(remap, []interface{}{
-						key,
-						maputil.Get(iterVector, key),
-					})
+			iterVector := sourceValue
+
+			if sourceVar != `` {
+				iterVector = loopScope.Get(sourceVar)
+			}
+
+			var iterItem interface{}
+
+			if iterator, ok := iterVector.(scripting.Iterator); ok {
+				// iterators produce their values one at a time, only as they are needed
+				if item, more, err := iterator.Next(); err != nil {
+					return err
+				} else if more {
+					iterItem = item
+				} else {
+					break
 				}
+			} else {
+				if typeutil.IsMap(iterVector) {
+					remap := make([][]interface{}, 0)
+					keys := maputil.StringKeys(iterVector)
+					sort.Strings(keys)
+
+					for _, key := range keys {
+						remap = append(remap, []interface{}{
+							key,
+							maputil.Get(iterVector, key),
+						})
+					}
 
-				iterVector = remap
+					iterVector = remap
+				}
+
+				if iterLen := sliceutil.Len(iterVector); i < iterLen {
+					if item, ok := sliceutil.At(iterVector, i); ok {
+						iterItem = item
+					} else {
+						return fmt.Errorf("Failed to retrieve iterator item %d", i)
+					}
+				} else {
+					break
+				}
 			}
 
-			if iterLen := sliceutil.Len(iterVector); i < iterLen {
-				if iterItem, ok := sliceutil.At(iterVector, i); ok {
-					var didSet bool
+			var didSet bool
 
-					if totalLhsCount := len(destVars); totalLhsCount > 1 {
-						if typeutil.IsArray(iterItem) {
-							for j, rhs := range sliceutil.Sliceify(iterItem) {
-								if j < totalLhsCount {
-									loopScope.Set(destVars[j], rhs)
-									didSet = true
-								}
+			if totalLhsCount := len(destVars); totalLhsCount > 1 {
+				if typeutil.IsArray(iterItem) {
+					for j, rhs := range sliceutil.Sliceify(iterItem) {
+						if j < totalLhsCount {
+							if err := loopScope.TrySet(destVars[j], rhs); err != nil {
+								return err
 							}
+
+							didSet = true
 						}
 					}
+				}
+			}
 
-					if !didSet {
-						loopScope.Set(destVars[0], iterItem)
-					}
-				} else {
-					return fmt.Errorf("Failed to retrieve iterator item %d", i)
+			if !didSet {
+				if err := loopScope.TrySet(destVars[0], iterItem); err != nil {
+					return err
 				}
-			} else {
-				break
 			}
 		}
 
-		loopScope.Set(`index`, loop.CurrentIndex())
+		if err := loopScope.TrySet(`index`, loop.CurrentIndex()); err != nil {
+			return err
+		}
 
-		for _, block := range loop.Blocks() {
-			if err := self.evaluateBlock(block); err != nil {
-				if fc, ok := err.(*scripting.FlowControlErr); ok {
-					if fc.Level <= 0 {
-						return fc
-					} else if fc.Level == 1 {
-						if fc.Type == scripting.FlowContinue {
-							continue LoopEval
-						} else {
-							break LoopEval
-						}
+		if err := self.evaluateBody(loop.Blocks()); err != nil {
+			if fc, ok := err.(*scripting.FlowControlErr); ok {
+				if fc.Level <= 0 {
+					return fc
+				} else if fc.Level == 1 {
+					// deferred blocks that failed on the way out of this iteration stop the loop
+					if fc.Err != nil {
+						return fc.Err
+					} else if fc.Type == scripting.FlowContinue {
+						continue LoopEval
 					} else {
-						fc.Level = fc.Level - 1
-						return fc
+						break LoopEval
 					}
 				} else {
-					return err
+					fc.Level = fc.Level - 1
+					return fc
 				}
+			} else {
+				return err
 			}
 		}
 
@@ -842,9 +1392,10 @@ LoopEval:
 	return nil
 }
 
-func (self *Environment) evaluateLoopIterationStart(loop *scripting.Loop, scope *scripting.Scope) (string, []string, error) {
+func (self *Environment) evaluateLoopIterationStart(loop *scripting.Loop, scope *scripting.Scope) (string, interface{}, []string, error) {
 	destVars, source := loop.IteratableParts()
 	var sourceVar string
+	var sourceValue interface{}
 
 	if cmd, ok := source.(*scripting.Command); ok {
 		// since we totally need the results of the command to iterate on them, if the command
@@ -856,8 +1407,23 @@ func (self *Environment) evaluateLoopIterationStart(loop *scripting.Loop, scope
 		if resultVar, err := self.evaluateCommand(cmd, true); err == nil {
 			sourceVar = resultVar
 		} else {
-			return ``, nil, err
+			return ``, nil, nil, err
+		}
+	} else if iterable, ok := source.(*scripting.MatchIterable); ok {
+		// evaluate the regular expression and store all of the matches in the loop scope
+		if matches, err := iterable.Matches(); err == nil {
+			sourceVar = scripting.DefaultIteratorMatchesVariableName
+			scope.Declare(sourceVar)
+
+			if err := scope.TrySet(sourceVar, matches); err != nil {
+				return ``, nil, nil, err
+			}
+		} else {
+			return ``, nil, nil, err
 		}
+	} else if iterable, ok := source.(*scripting.PathIterable); ok {
+		// paths with slices, wildcards, or filters are resolved once, when the loop starts
+		sourceValue = iterable.Value
 	} else if srcvar, ok := source.(string); ok {
 		sourceVar = srcvar
 	}
@@ -866,7 +1432,7 @@ func (self *Environment) evaluateLoopIterationStart(loop *scripting.Loop, scope
 		scope.Declare(v)
 	}
 
-	return sourceVar, destVars, nil
+	return sourceVar, sourceValue, destVars, nil
 }
 
 func (self *Environment) sendContextUpdate(ctx *scripting.Context, isDone bool) {
@@ -877,3 +1443,29 @@ func (self *Environment) sendContextUpdate(ctx *scripting.Context, isDone bool)
 		handler(ctx, isDone)
 	}
 }
+
+// annotate an error with the line (and source) of the statement that caused it
+func errorWithSource(ctx *scripting.Context, err error) error {
+	if err == nil || ctx == nil {
+		return err
+	}
+
+	snippet, _ := stringutil.SplitPair(strings.TrimSpace(ctx.Snippet()), "\n")
+
+	return fmt.Errorf("line %d: %w (at: %s)", ctx.Line(), err, strings.TrimSpace(snippet))
+}
+
+// trim and convert each part of a (possibly nested) module name to snake_case
+func normalizeModuleName(name string) string {
+	var parts = strings.Split(strings.TrimSpace(name), scripting.ModuleSeparator)
+
+	for i, part := range parts {
+		parts[i] = stringutil.Underscore(strings.TrimSpace(part))
+	}
+
+	if name = strings.Join(parts, scripting.ModuleSeparator); name == `` {
+		name = scripting.UnqualifiedModuleName
+	}
+
+	return name
+}
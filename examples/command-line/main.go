@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/PerformLine/friendscript"
 	"github.com/PerformLine/friendscript/commands/core"
+	"github.com/PerformLine/friendscript/scripting"
+	"github.com/PerformLine/go-stockutil/stringutil"
 )
 
 type CoreCommands struct {
@@ -23,15 +26,6 @@ func NewCoreCommands(env *friendscript.Environment) *CoreCommands {
 	return cmd
 }
 
-// Exit the program with the given status code.
-//
-// This will be available within Friendscript as the "exit" command.
-//
-func (self *CoreCommands) Exit(status int) error {
-	os.Exit(status)
-	return nil
-}
-
 // Return the list of files and subdirectories in the given directory path.
 //
 // This will be available within Friendscript as the "ls" command.
@@ -57,20 +51,34 @@ func main() {
 	environment := friendscript.NewEnvironment()
 
 	// add in our commands module, which extends the default "core" commands module
-	// by adding two new commands: "exit" and "ls".
+	// by adding a new command: "ls".
 	environment.RegisterModule(``, NewCoreCommands(environment))
 
-	if len(os.Args) > 1 {
-		for _, scriptPath := range os.Args[1:] {
+	var scripts []string
+
+	// arguments like "name=value" set the scripts' parameters, everything else is a script to run
+	for _, arg := range os.Args[1:] {
+		if name, value := stringutil.SplitPair(arg, `=`); value != `` && !strings.HasPrefix(name, `-`) {
+			environment.Set(name, value)
+		} else {
+			scripts = append(scripts, arg)
+		}
+	}
+
+	if len(scripts) > 0 {
+		for _, scriptPath := range scripts {
 			if _, err := environment.EvaluateFile(scriptPath); err == nil {
 				os.Exit(0)
+			} else if status, ok := scripting.ExitStatus(err); ok {
+				// the script ended itself with the "exit" statement
+				os.Exit(status)
 			} else {
 				fmt.Printf("script error: %v\n", err)
 				os.Exit(1)
 			}
 		}
 	} else {
-		fmt.Printf("usage: %v SCRIPT [SCRIPT ..]\n", os.Args[0])
+		fmt.Printf("usage: %v [NAME=VALUE ..] SCRIPT [SCRIPT ..]\n", os.Args[0])
 		os.Exit(127)
 	}
 }
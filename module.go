@@ -5,6 +5,7 @@ import (
 )
 
 type Module = utils.Module
+type SubmoduleProvider = utils.SubmoduleProvider
 
 func CreateModule(from interface{}) Module {
 	return utils.NewDefaultExecutor(from)
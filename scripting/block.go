@@ -79,6 +79,35 @@ func (self *Block) FlowContinue() int {
 	return self.flowControl(ruleFlowControlContinue)
 }
 
+// Return the expression given to a "return" statement (which may be nil), and whether this block
+// is a return statement at all.
+func (self *Block) FlowReturn() (*Expression, bool) {
+	return self.flowExpression(ruleFlowControlReturn)
+}
+
+// Return the expression given to an "exit" statement (which may be nil), and whether this block
+// is an exit statement at all.
+func (self *Block) FlowExit() (*Expression, bool) {
+	return self.flowExpression(ruleFlowControlExit)
+}
+
+func (self *Block) flowExpression(rule pegRule) (*Expression, bool) {
+	if self.Type() == FlowControlWord {
+		if n := self.node.directChild(rule); n != nil {
+			if exprNode := n.directChild(ruleExpression); exprNode != nil {
+				return NewExpression(&Statement{
+					node:  n,
+					block: self,
+				}, exprNode), true
+			}
+
+			return nil, true
+		}
+	}
+
+	return nil, false
+}
+
 func (self *Block) flowControl(rule pegRule) int {
 	if self.Type() == FlowControlWord {
 		if n := self.node.firstChild(rule); n != nil {
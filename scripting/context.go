@@ -2,6 +2,7 @@ package scripting
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -47,3 +48,20 @@ func (self *Context) Snippet() string {
 
 	return ``
 }
+
+// Return the (1-based) line number in the script that this context starts on, or zero if it is not known.
+func (self *Context) Line() int {
+	if self.Script != nil && self.AbsoluteStartOffset >= 0 && self.AbsoluteStartOffset <= len(self.Script.Buffer) {
+		var src = self.Script.Buffer
+		var offset = self.AbsoluteStartOffset
+
+		// statements include any leading whitespace, which shouldn't count towards the line number
+		for offset < len(src) && offset < self.AbsoluteStartOffset+self.Length && strings.ContainsRune(" \t\r\n", rune(src[offset])) {
+			offset++
+		}
+
+		return strings.Count(src[:offset], "\n") + 1
+	}
+
+	return 0
+}
@@ -0,0 +1,185 @@
+package scripting
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// The number of digits after the decimal point that are retained when dividing decimals.
+var DecimalDivisionScale = 16
+
+// A Decimal is an arbitrary-precision decimal number, suitable for representing values (such as
+// money) that cannot tolerate the rounding errors inherent in floating point arithmetic.  Decimal
+// literals are written with a "D" suffix (e.g.: 19.99D).
+type Decimal struct {
+	rat   *big.Rat
+	scale int
+}
+
+// Parse a string (e.g.: "19.99") into a Decimal.  The number of digits after the decimal point
+// determines how many digits are shown when the value is formatted.
+func ParseDecimal(in string) (Decimal, error) {
+	var raw = strings.TrimSuffix(strings.TrimSpace(in), `D`)
+	var scale int
+
+	if i := strings.Index(raw, `.`); i >= 0 {
+		scale = len(raw) - i - 1
+	}
+
+	if rat, ok := new(big.Rat).SetString(raw); ok {
+		return Decimal{
+			rat:   rat,
+			scale: scale,
+		}, nil
+	} else {
+		return Decimal{}, fmt.Errorf("invalid decimal %q", in)
+	}
+}
+
+// Convert a value into a Decimal.  Integers, floats, and strings containing numbers are supported.
+func ToDecimal(in interface{}) (Decimal, error) {
+	switch v := in.(type) {
+	case Decimal:
+		return v, nil
+	case *Decimal:
+		if v != nil {
+			return *v, nil
+		}
+	case float32:
+		return ParseDecimal(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	case float64:
+		return ParseDecimal(strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		return ParseDecimal(v)
+	default:
+		if i, ok := toInt64(in); ok {
+			return Decimal{
+				rat: new(big.Rat).SetInt64(i),
+			}, nil
+		}
+	}
+
+	return Decimal{}, fmt.Errorf("cannot convert %T to a decimal", in)
+}
+
+func (self Decimal) value() *big.Rat {
+	if self.rat == nil {
+		return new(big.Rat)
+	}
+
+	return self.rat
+}
+
+func (self Decimal) Add(other Decimal) Decimal {
+	return Decimal{
+		rat:   new(big.Rat).Add(self.value(), other.value()),
+		scale: maxInt(self.scale, other.scale),
+	}
+}
+
+func (self Decimal) Sub(other Decimal) Decimal {
+	return Decimal{
+		rat:   new(big.Rat).Sub(self.value(), other.value()),
+		scale: maxInt(self.scale, other.scale),
+	}
+}
+
+func (self Decimal) Mul(other Decimal) Decimal {
+	return Decimal{
+		rat:   new(big.Rat).Mul(self.value(), other.value()),
+		scale: self.scale + other.scale,
+	}
+}
+
+func (self Decimal) Div(other Decimal) (Decimal, error) {
+	if other.value().Sign() == 0 {
+		return Decimal{}, fmt.Errorf("cannot divide by zero")
+	}
+
+	var scale = maxInt(self.scale, other.scale)
+	var quo = new(big.Rat).Quo(self.value(), other.value())
+
+	// quotients that can't be represented exactly with the operands' scale get more digits
+	if out, err := ParseDecimal(quo.FloatString(scale)); err == nil && out.rat.Cmp(quo) != 0 {
+		scale = maxInt(scale, DecimalDivisionScale)
+	}
+
+	if out, err := ParseDecimal(quo.FloatString(scale)); err == nil {
+		return out, nil
+	} else {
+		return Decimal{}, err
+	}
+}
+
+// Return the remainder of dividing this value by another.  Like integer remainders, the result has
+// the same sign as this value.
+func (self Decimal) Mod(other Decimal) (Decimal, error) {
+	if other.value().Sign() == 0 {
+		return Decimal{}, fmt.Errorf("cannot divide by zero")
+	}
+
+	var quo = new(big.Rat).Quo(self.value(), other.value())
+	var whole = new(big.Rat).SetInt(new(big.Int).Quo(quo.Num(), quo.Denom()))
+
+	return Decimal{
+		rat:   new(big.Rat).Sub(self.value(), whole.Mul(whole, other.value())),
+		scale: maxInt(self.scale, other.scale),
+	}, nil
+}
+
+// Raise this value to the given (non-negative) integer power.
+func (self Decimal) Pow(exp int64) Decimal {
+	var base = new(big.Rat).Set(self.value())
+	var output = new(big.Rat).SetInt64(1)
+	var scale = maxInt(self.scale, DecimalDivisionScale)
+
+	if int64(self.scale)*exp < int64(scale) {
+		scale = self.scale * int(exp)
+	}
+
+	for ; exp > 0; exp >>= 1 {
+		if exp&1 == 1 {
+			output.Mul(output, base)
+		}
+
+		base.Mul(base, base)
+	}
+
+	return Decimal{
+		rat:   output,
+		scale: scale,
+	}
+}
+
+// Compare two decimals, returning -1, 0, or +1 if this value is less than, equal to, or greater
+// than the other.
+func (self Decimal) Cmp(other Decimal) int {
+	return self.value().Cmp(other.value())
+}
+
+func (self Decimal) IsZero() bool {
+	return self.value().Sign() == 0
+}
+
+func (self Decimal) Float64() float64 {
+	v, _ := self.value().Float64()
+	return v
+}
+
+func (self Decimal) String() string {
+	return self.value().FloatString(self.scale)
+}
+
+func (self Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(self.String()), nil
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -20,36 +20,49 @@ const (
 	ruleASSIGN
 	ruleTRIQUOT
 	ruleBREAK
+	ruleCASE
 	ruleCLOSE
 	ruleCOLON
 	ruleCOMMA
 	ruleCOMMENT
+	ruleCONST
 	ruleCONT
 	ruleCOUNT
 	ruleDECLARE
+	ruleDEFAULT
+	ruleDEFER
 	ruleDOT
 	ruleELSE
+	ruleEXIT
 	ruleIF
 	ruleIN
 	ruleINCLUDE
 	ruleLOOP
 	ruleNOOP
 	ruleNOT
+	ruleRETRY
+	ruleRETURN
 	ruleOPEN
+	rulePARAM
 	ruleSCOPE
 	ruleSEMI
 	ruleSHEBANG
 	ruleSKIPVAR
+	ruleSWITCH
+	ruleTIMEOUT
 	ruleUNSET
+	ruleUSE
 	ruleScalarType
 	ruleIdentifier
 	ruleFloat
+	ruleDecimal
 	ruleBoolean
 	ruleInteger
 	rulePositiveInteger
 	ruleString
 	ruleStringLiteral
 	ruleStringInterpolated
+	ruleStringRaw
 	ruleTriquote
 	ruleTriquoteBody
 	ruleNullValue
@@ -60,6 +73,13 @@ const (
 	ruleKey
 	ruleKValue
 	ruleType
+	ruleTimestamp
+	ruleTimeZone
+	ruleDuration
+	ruleDurationUnit
+	ruleByteSize
+	ruleByteSizeUnit
+	ruleNow
 	ruleExponentiate
 	ruleMultiply
 	ruleDivide
@@ -96,20 +116,42 @@ const (
 	ruleVariableNameSequence
 	ruleVariableName
 	ruleVariableIndex
+	ruleVariableSlice
+	ruleVariableSliceStart
+	ruleVariableSliceEnd
+	ruleVariableWildcard
+	ruleVariableFilter
+	ruleFilterField
 	ruleBlock
 	ruleFlowControlWord
 	ruleFlowControlBreak
 	ruleFlowControlContinue
+	ruleFlowControlReturn
+	ruleFlowControlExit
 	ruleStatementBlock
 	ruleAssignment
 	ruleAssignmentLHS
+	ruleConstant
 	ruleAssignmentRHS
 	ruleVariableSequence
 	ruleExpressionSequence
 	ruleExpression
 	ruleExpressionLHS
 	ruleExpressionRHS
+	ruleExpressionOperand
+	ruleExpressionTail
+	ruleExpressionCoalesce
+	ruleExpressionTernary
+	ruleExpressionTernaryCondition
 	ruleValueYielding
+	ruleExpressionGroup
+	ruleUse
+	ruleUseModule
+	ruleUseAlias
+	ruleParam
+	ruleParamType
+	ruleParamRequired
+	ruleParamDefault
 	ruleDirective
 	ruleDirectiveUnset
 	ruleDirectiveInclude
@@ -123,11 +165,24 @@ const (
 	ruleIfStanza
 	ruleElseIfStanza
 	ruleElseStanza
+	ruleSwitch
+	ruleSwitchCase
+	ruleSwitchCaseRegex
+	ruleSwitchCaseMembership
+	ruleSwitchCaseValues
+	ruleSwitchDefault
+	ruleDefer
+	ruleRetry
+	ruleRetryAttempts
+	ruleRetryOptions
+	ruleTimeout
+	ruleTimeoutDuration
 	ruleLoop
 	ruleLoopConditionFixedLength
 	ruleLoopConditionIterable
 	ruleLoopIterableLHS
 	ruleLoopIterableRHS
+	ruleLoopIterableMatch
 	ruleLoopConditionBounded
 	ruleLoopConditionTruthy
 	ruleConditionalExpression
@@ -151,36 +206,49 @@ var rul3s = [...]string{
 	"ASSIGN",
 	"TRIQUOT",
 	"BREAK",
+	"CASE",
 	"CLOSE",
 	"COLON",
 	"COMMA",
 	"COMMENT",
+	"CONST",
 	"CONT",
 	"COUNT",
 	"DECLARE",
+	"DEFAULT",
+	"DEFER",
 	"DOT",
 	"ELSE",
+	"EXIT",
 	"IF",
 	"IN",
 	"INCLUDE",
 	"LOOP",
 	"NOOP",
 	"NOT",
+	"RETRY",
+	"RETURN",
 	"OPEN",
+	"PARAM",
 	"SCOPE",
 	"SEMI",
 	"SHEBANG",
 	"SKIPVAR",
+	"SWITCH",
+	"TIMEOUT",
 	"UNSET",
+	"USE",
 	"ScalarType",
 	"Identifier",
 	"Float",
+	"Decimal",
 	"Boolean",
 	"Integer",
 	"PositiveInteger",
 	"String",
 	"StringLiteral",
 	"StringInterpolated",
+	"StringRaw",
 	"Triquote",
 	"TriquoteBody",
 	"NullValue",
@@ -191,6 +259,13 @@ var rul3s = [...]string{
 	"Key",
 	"KValue",
 	"Type",
+	"Timestamp",
+	"TimeZone",
+	"Duration",
+	"DurationUnit",
+	"ByteSize",
+	"ByteSizeUnit",
+	"Now",
 	"Exponentiate",
 	"Multiply",
 	"Divide",
@@ -227,20 +302,42 @@ var rul3s = [...]string{
 	"VariableNameSequence",
 	"VariableName",
 	"VariableIndex",
+	"VariableSlice",
+	"VariableSliceStart",
+	"VariableSliceEnd",
+	"VariableWildcard",
+	"VariableFilter",
+	"FilterField",
 	"Block",
 	"FlowControlWord",
 	"FlowControlBreak",
 	"FlowControlContinue",
+	"FlowControlReturn",
+	"FlowControlExit",
 	"StatementBlock",
 	"Assignment",
 	"AssignmentLHS",
+	"Constant",
 	"AssignmentRHS",
 	"VariableSequence",
 	"ExpressionSequence",
 	"Expression",
 	"ExpressionLHS",
 	"ExpressionRHS",
+	"ExpressionOperand",
+	"ExpressionTail",
+	"ExpressionCoalesce",
+	"ExpressionTernary",
+	"ExpressionTernaryCondition",
 	"ValueYielding",
+	"ExpressionGroup",
+	"Use",
+	"UseModule",
+	"UseAlias",
+	"Param",
+	"ParamType",
+	"ParamRequired",
+	"ParamDefault",
 	"Directive",
 	"DirectiveUnset",
 	"DirectiveInclude",
@@ -254,11 +351,24 @@ var rul3s = [...]string{
 	"IfStanza",
 	"ElseIfStanza",
 	"ElseStanza",
+	"Switch",
+	"SwitchCase",
+	"SwitchCaseRegex",
+	"SwitchCaseMembership",
+	"SwitchCaseValues",
+	"SwitchDefault",
+	"Defer",
+	"Retry",
+	"RetryAttempts",
+	"RetryOptions",
+	"Timeout",
+	"TimeoutDuration",
 	"Loop",
 	"LoopConditionFixedLength",
 	"LoopConditionIterable",
 	"LoopIterableLHS",
 	"LoopIterableRHS",
+	"LoopIterableMatch",
 	"LoopConditionBounded",
 	"LoopConditionTruthy",
 	"ConditionalExpression",
@@ -563,7 +673,7 @@ type Friendscript struct {
 
 	Buffer string
 	buffer []rune
-	rules  [124]func() bool
+	rules  [179]func() bool
 	Parse  func(rule ...int) error
 	Reset  func()
 	Pretty bool
@@ -925,15 +1035,12 @@ func (p *Friendscript) Init() {
 		},
 		/* 3 ASSIGN <- <(_ ('-' '>') _)> */
 		nil,
-		/* 4 TRIQUOT <- <(_ ('"' '"' '"') _)> */
+		/* 4 TRIQUOT <- <('"' '"' '"')> */
 		func() bool {
 			position33, tokenIndex33, depth33 := position, tokenIndex, depth
 			{
 				position34 := position
 				depth++
-				if !_rules[rule_]() {
-					goto l33
-				}
 				if buffer[position] != rune('"') {
 					goto l33
 				}
@@ -946,9 +1053,6 @@ func (p *Friendscript) Init() {
 					goto l33
 				}
 				position++
-				if !_rules[rule_]() {
-					goto l33
-				}
 				depth--
 				add(ruleTRIQUOT, position34)
 			}
@@ -959,33 +1063,33 @@ func (p *Friendscript) Init() {
 		},
 		/* 5 BREAK <- <(_ ('b' 'r' 'e' 'a' 'k') _)> */
 		nil,
-		/* 6 CLOSE <- <(_ '}' _)> */
+		/* 6 CASE <- <(_ ('c' 'a' 's' 'e') __)> */
+		nil,
+		/* 7 CLOSE <- <(_ '}' _)> */
 		func() bool {
-			position36, tokenIndex36, depth36 := position, tokenIndex, depth
+			position37, tokenIndex37, depth37 := position, tokenIndex, depth
 			{
-				position37 := position
+				position38 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l36
+					goto l37
 				}
 				if buffer[position] != rune('}') {
-					goto l36
+					goto l37
 				}
 				position++
 				if !_rules[rule_]() {
-					goto l36
+					goto l37
 				}
 				depth--
-				add(ruleCLOSE, position37)
+				add(ruleCLOSE, position38)
 			}
 			return true
-		l36:
-			position, tokenIndex, depth = position36, tokenIndex36, depth36
+		l37:
+			position, tokenIndex, depth = position37, tokenIndex37, depth37
 			return false
 		},
-		/* 7 COLON <- <(_ ':' _)> */
-		nil,
-		/* 8 COMMA <- <(_ ',' _)> */
+		/* 8 COLON <- <(_ ':' _)> */
 		func() bool {
 			position39, tokenIndex39, depth39 := position, tokenIndex, depth
 			{
@@ -994,7 +1098,7 @@ func (p *Friendscript) Init() {
 				if !_rules[rule_]() {
 					goto l39
 				}
-				if buffer[position] != rune(',') {
+				if buffer[position] != rune(':') {
 					goto l39
 				}
 				position++
@@ -1002,2986 +1106,5595 @@ func (p *Friendscript) Init() {
 					goto l39
 				}
 				depth--
-				add(ruleCOMMA, position40)
+				add(ruleCOLON, position40)
 			}
 			return true
 		l39:
 			position, tokenIndex, depth = position39, tokenIndex39, depth39
 			return false
 		},
-		/* 9 COMMENT <- <(_ '#' (!'\n' .)*)> */
+		/* 9 COMMA <- <(_ ',' _)> */
+		func() bool {
+			position41, tokenIndex41, depth41 := position, tokenIndex, depth
+			{
+				position42 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l41
+				}
+				if buffer[position] != rune(',') {
+					goto l41
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l41
+				}
+				depth--
+				add(ruleCOMMA, position42)
+			}
+			return true
+		l41:
+			position, tokenIndex, depth = position41, tokenIndex41, depth41
+			return false
+		},
+		/* 10 COMMENT <- <(_ '#' (!'\n' .)*)> */
+		func() bool {
+			position43, tokenIndex43, depth43 := position, tokenIndex, depth
+			{
+				position44 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l43
+				}
+				if buffer[position] != rune('#') {
+					goto l43
+				}
+				position++
+			l45:
+				{
+					position46, tokenIndex46, depth46 := position, tokenIndex, depth
+					{
+						position47, tokenIndex47, depth47 := position, tokenIndex, depth
+						if buffer[position] != rune('\n') {
+							goto l47
+						}
+						position++
+						goto l46
+					l47:
+						position, tokenIndex, depth = position47, tokenIndex47, depth47
+					}
+					if !matchDot() {
+						goto l46
+					}
+					goto l45
+				l46:
+					position, tokenIndex, depth = position46, tokenIndex46, depth46
+				}
+				depth--
+				add(ruleCOMMENT, position44)
+			}
+			return true
+		l43:
+			position, tokenIndex, depth = position43, tokenIndex43, depth43
+			return false
+		},
+		/* 11 CONST <- <(_ ('c' 'o' 'n' 's' 't') __)> */
+		nil,
+		/* 12 CONT <- <(_ ('c' 'o' 'n' 't' 'i' 'n' 'u' 'e') _)> */
 		nil,
-		/* 10 CONT <- <(_ ('c' 'o' 'n' 't' 'i' 'n' 'u' 'e') _)> */
+		/* 13 COUNT <- <(_ ('c' 'o' 'u' 'n' 't') _)> */
 		nil,
-		/* 11 COUNT <- <(_ ('c' 'o' 'u' 'n' 't') _)> */
+		/* 14 DECLARE <- <(_ ('d' 'e' 'c' 'l' 'a' 'r' 'e') __)> */
 		nil,
-		/* 12 DECLARE <- <(_ ('d' 'e' 'c' 'l' 'a' 'r' 'e') __)> */
+		/* 15 DEFAULT <- <(_ ('d' 'e' 'f' 'a' 'u' 'l' 't') _)> */
 		nil,
-		/* 13 DOT <- <'.'> */
+		/* 16 DEFER <- <(_ ('d' 'e' 'f' 'e' 'r') _)> */
 		nil,
-		/* 14 ELSE <- <(_ ('e' 'l' 's' 'e') _)> */
+		/* 17 DOT <- <'.'> */
+		func() bool {
+			position54, tokenIndex54, depth54 := position, tokenIndex, depth
+			{
+				position55 := position
+				depth++
+				if buffer[position] != rune('.') {
+					goto l54
+				}
+				position++
+				depth--
+				add(ruleDOT, position55)
+			}
+			return true
+		l54:
+			position, tokenIndex, depth = position54, tokenIndex54, depth54
+			return false
+		},
+		/* 18 ELSE <- <(_ ('e' 'l' 's' 'e') _)> */
 		func() bool {
-			position46, tokenIndex46, depth46 := position, tokenIndex, depth
+			position56, tokenIndex56, depth56 := position, tokenIndex, depth
 			{
-				position47 := position
+				position57 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l46
+					goto l56
 				}
 				if buffer[position] != rune('e') {
-					goto l46
+					goto l56
 				}
 				position++
 				if buffer[position] != rune('l') {
-					goto l46
+					goto l56
 				}
 				position++
 				if buffer[position] != rune('s') {
-					goto l46
+					goto l56
 				}
 				position++
 				if buffer[position] != rune('e') {
-					goto l46
+					goto l56
 				}
 				position++
 				if !_rules[rule_]() {
-					goto l46
+					goto l56
 				}
 				depth--
-				add(ruleELSE, position47)
+				add(ruleELSE, position57)
 			}
 			return true
-		l46:
-			position, tokenIndex, depth = position46, tokenIndex46, depth46
+		l56:
+			position, tokenIndex, depth = position56, tokenIndex56, depth56
 			return false
 		},
-		/* 15 IF <- <(_ ('i' 'f') _)> */
+		/* 19 EXIT <- <(_ ('e' 'x' 'i' 't') !([a-z] / [A-Z] / [0-9] / '_'))> */
+		nil,
+		/* 20 IF <- <(_ ('i' 'f') _)> */
+		nil,
+		/* 21 IN <- <(__ ('i' 'n') __)> */
+		nil,
+		/* 22 INCLUDE <- <(_ ('i' 'n' 'c' 'l' 'u' 'd' 'e') __)> */
 		nil,
-		/* 16 IN <- <(__ ('i' 'n') __)> */
+		/* 23 LOOP <- <(_ ('l' 'o' 'o' 'p') _)> */
 		nil,
-		/* 17 INCLUDE <- <(_ ('i' 'n' 'c' 'l' 'u' 'd' 'e') __)> */
+		/* 24 NOOP <- <SEMI> */
 		nil,
-		/* 18 LOOP <- <(_ ('l' 'o' 'o' 'p') _)> */
+		/* 25 NOT <- <(_ ('n' 'o' 't') __)> */
 		nil,
-		/* 19 NOOP <- <SEMI> */
+		/* 26 RETRY <- <(_ ('r' 'e' 't' 'r' 'y') __)> */
 		nil,
-		/* 20 NOT <- <(_ ('n' 'o' 't') __)> */
+		/* 27 RETURN <- <(_ ('r' 'e' 't' 'u' 'r' 'n') !([a-z] / [A-Z] / [0-9] / '_'))> */
 		nil,
-		/* 21 OPEN <- <(_ '{' _)> */
+		/* 28 OPEN <- <(_ '{' _)> */
 		func() bool {
-			position54, tokenIndex54, depth54 := position, tokenIndex, depth
+			position67, tokenIndex67, depth67 := position, tokenIndex, depth
 			{
-				position55 := position
+				position68 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l54
+					goto l67
 				}
 				if buffer[position] != rune('{') {
-					goto l54
+					goto l67
 				}
 				position++
 				if !_rules[rule_]() {
-					goto l54
+					goto l67
 				}
 				depth--
-				add(ruleOPEN, position55)
+				add(ruleOPEN, position68)
 			}
 			return true
-		l54:
-			position, tokenIndex, depth = position54, tokenIndex54, depth54
+		l67:
+			position, tokenIndex, depth = position67, tokenIndex67, depth67
 			return false
 		},
-		/* 22 SCOPE <- <(':' ':')> */
+		/* 29 PARAM <- <(_ ('p' 'a' 'r' 'a' 'm') __)> */
 		nil,
-		/* 23 SEMI <- <(_ ';' _)> */
+		/* 30 SCOPE <- <(':' ':')> */
+		func() bool {
+			position70, tokenIndex70, depth70 := position, tokenIndex, depth
+			{
+				position71 := position
+				depth++
+				if buffer[position] != rune(':') {
+					goto l70
+				}
+				position++
+				if buffer[position] != rune(':') {
+					goto l70
+				}
+				position++
+				depth--
+				add(ruleSCOPE, position71)
+			}
+			return true
+		l70:
+			position, tokenIndex, depth = position70, tokenIndex70, depth70
+			return false
+		},
+		/* 31 SEMI <- <(_ ';' _)> */
 		func() bool {
-			position57, tokenIndex57, depth57 := position, tokenIndex, depth
+			position72, tokenIndex72, depth72 := position, tokenIndex, depth
 			{
-				position58 := position
+				position73 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l57
+					goto l72
 				}
 				if buffer[position] != rune(';') {
-					goto l57
+					goto l72
 				}
 				position++
 				if !_rules[rule_]() {
-					goto l57
+					goto l72
 				}
 				depth--
-				add(ruleSEMI, position58)
+				add(ruleSEMI, position73)
 			}
 			return true
-		l57:
-			position, tokenIndex, depth = position57, tokenIndex57, depth57
+		l72:
+			position, tokenIndex, depth = position72, tokenIndex72, depth72
 			return false
 		},
-		/* 24 SHEBANG <- <('#' '!' (!'\n' .)+ '\n')> */
+		/* 32 SHEBANG <- <('#' '!' (!'\n' .)+ '\n')> */
+		nil,
+		/* 33 SKIPVAR <- <(_ '_' _)> */
 		nil,
-		/* 25 SKIPVAR <- <(_ '_' _)> */
+		/* 34 SWITCH <- <(_ ('s' 'w' 'i' 't' 'c' 'h') __)> */
 		nil,
-		/* 26 UNSET <- <(_ ('u' 'n' 's' 'e' 't') __)> */
+		/* 35 TIMEOUT <- <(_ ('t' 'i' 'm' 'e' 'o' 'u' 't') __)> */
 		nil,
-		/* 27 ScalarType <- <(Boolean / Float / Integer / String / NullValue)> */
+		/* 36 UNSET <- <(_ ('u' 'n' 's' 'e' 't') __)> */
 		nil,
-		/* 28 Identifier <- <(([a-z] / [A-Z] / '_') ([a-z] / [A-Z] / ([0-9] / [0-9]) / '_')*)> */
+		/* 37 USE <- <(_ ('u' 's' 'e') __)> */
+		nil,
+		/* 38 ScalarType <- <(Boolean / Decimal / Float / Integer / String / NullValue)> */
+		nil,
+		/* 39 Identifier <- <(([a-z] / [A-Z] / '_') ([a-z] / [A-Z] / ([0-9] / [0-9]) / '_')*)> */
 		func() bool {
-			position63, tokenIndex63, depth63 := position, tokenIndex, depth
+			position81, tokenIndex81, depth81 := position, tokenIndex, depth
 			{
-				position64 := position
+				position82 := position
 				depth++
 				{
-					position65, tokenIndex65, depth65 := position, tokenIndex, depth
+					position83, tokenIndex83, depth83 := position, tokenIndex, depth
 					if c := buffer[position]; c < rune('a') || c > rune('z') {
-						goto l66
+						goto l84
 					}
 					position++
-					goto l65
-				l66:
-					position, tokenIndex, depth = position65, tokenIndex65, depth65
+					goto l83
+				l84:
+					position, tokenIndex, depth = position83, tokenIndex83, depth83
 					if c := buffer[position]; c < rune('A') || c > rune('Z') {
-						goto l67
+						goto l85
 					}
 					position++
-					goto l65
-				l67:
-					position, tokenIndex, depth = position65, tokenIndex65, depth65
+					goto l83
+				l85:
+					position, tokenIndex, depth = position83, tokenIndex83, depth83
 					if buffer[position] != rune('_') {
-						goto l63
+						goto l81
 					}
 					position++
 				}
-			l65:
-			l68:
+			l83:
+			l86:
 				{
-					position69, tokenIndex69, depth69 := position, tokenIndex, depth
+					position87, tokenIndex87, depth87 := position, tokenIndex, depth
 					{
-						position70, tokenIndex70, depth70 := position, tokenIndex, depth
+						position88, tokenIndex88, depth88 := position, tokenIndex, depth
 						if c := buffer[position]; c < rune('a') || c > rune('z') {
-							goto l71
+							goto l89
 						}
 						position++
-						goto l70
-					l71:
-						position, tokenIndex, depth = position70, tokenIndex70, depth70
+						goto l88
+					l89:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						if c := buffer[position]; c < rune('A') || c > rune('Z') {
-							goto l72
+							goto l90
 						}
 						position++
-						goto l70
-					l72:
-						position, tokenIndex, depth = position70, tokenIndex70, depth70
+						goto l88
+					l90:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						{
-							position74, tokenIndex74, depth74 := position, tokenIndex, depth
+							position92, tokenIndex92, depth92 := position, tokenIndex, depth
 							if c := buffer[position]; c < rune('0') || c > rune('9') {
-								goto l75
+								goto l93
 							}
 							position++
-							goto l74
-						l75:
-							position, tokenIndex, depth = position74, tokenIndex74, depth74
+							goto l92
+						l93:
+							position, tokenIndex, depth = position92, tokenIndex92, depth92
 							if c := buffer[position]; c < rune('0') || c > rune('9') {
-								goto l73
+								goto l91
 							}
 							position++
 						}
-					l74:
-						goto l70
-					l73:
-						position, tokenIndex, depth = position70, tokenIndex70, depth70
+					l92:
+						goto l88
+					l91:
+						position, tokenIndex, depth = position88, tokenIndex88, depth88
 						if buffer[position] != rune('_') {
-							goto l69
+							goto l87
 						}
 						position++
 					}
-				l70:
-					goto l68
-				l69:
-					position, tokenIndex, depth = position69, tokenIndex69, depth69
+				l88:
+					goto l86
+				l87:
+					position, tokenIndex, depth = position87, tokenIndex87, depth87
 				}
 				depth--
-				add(ruleIdentifier, position64)
+				add(ruleIdentifier, position82)
 			}
 			return true
-		l63:
-			position, tokenIndex, depth = position63, tokenIndex63, depth63
+		l81:
+			position, tokenIndex, depth = position81, tokenIndex81, depth81
 			return false
 		},
-		/* 29 Float <- <(Integer ('.' [0-9]+)?)> */
+		/* 40 Float <- <(Integer '.' [0-9]+)> */
+		nil,
+		/* 41 Decimal <- <(Integer ('.' [0-9]+)? 'D' !([a-z] / [A-Z] / ([0-9] / [0-9]) / '_'))> */
 		nil,
-		/* 30 Boolean <- <(('t' 'r' 'u' 'e') / ('f' 'a' 'l' 's' 'e'))> */
+		/* 42 Boolean <- <(('t' 'r' 'u' 'e') / ('f' 'a' 'l' 's' 'e'))> */
 		nil,
-		/* 31 Integer <- <('-'? PositiveInteger)> */
+		/* 43 Integer <- <('-'? PositiveInteger)> */
 		func() bool {
-			position78, tokenIndex78, depth78 := position, tokenIndex, depth
+			position97, tokenIndex97, depth97 := position, tokenIndex, depth
 			{
-				position79 := position
+				position98 := position
 				depth++
 				{
-					position80, tokenIndex80, depth80 := position, tokenIndex, depth
+					position99, tokenIndex99, depth99 := position, tokenIndex, depth
 					if buffer[position] != rune('-') {
-						goto l80
+						goto l99
 					}
 					position++
-					goto l81
-				l80:
-					position, tokenIndex, depth = position80, tokenIndex80, depth80
+					goto l100
+				l99:
+					position, tokenIndex, depth = position99, tokenIndex99, depth99
 				}
-			l81:
+			l100:
 				if !_rules[rulePositiveInteger]() {
-					goto l78
+					goto l97
 				}
 				depth--
-				add(ruleInteger, position79)
+				add(ruleInteger, position98)
 			}
 			return true
-		l78:
-			position, tokenIndex, depth = position78, tokenIndex78, depth78
+		l97:
+			position, tokenIndex, depth = position97, tokenIndex97, depth97
 			return false
 		},
-		/* 32 PositiveInteger <- <[0-9]+> */
+		/* 44 PositiveInteger <- <[0-9]+> */
 		func() bool {
-			position82, tokenIndex82, depth82 := position, tokenIndex, depth
+			position101, tokenIndex101, depth101 := position, tokenIndex, depth
 			{
-				position83 := position
+				position102 := position
 				depth++
 				if c := buffer[position]; c < rune('0') || c > rune('9') {
-					goto l82
+					goto l101
 				}
 				position++
-			l84:
+			l103:
 				{
-					position85, tokenIndex85, depth85 := position, tokenIndex, depth
+					position104, tokenIndex104, depth104 := position, tokenIndex, depth
 					if c := buffer[position]; c < rune('0') || c > rune('9') {
-						goto l85
+						goto l104
 					}
 					position++
-					goto l84
-				l85:
-					position, tokenIndex, depth = position85, tokenIndex85, depth85
+					goto l103
+				l104:
+					position, tokenIndex, depth = position104, tokenIndex104, depth104
 				}
 				depth--
-				add(rulePositiveInteger, position83)
+				add(rulePositiveInteger, position102)
 			}
 			return true
-		l82:
-			position, tokenIndex, depth = position82, tokenIndex82, depth82
+		l101:
+			position, tokenIndex, depth = position101, tokenIndex101, depth101
 			return false
 		},
-		/* 33 String <- <(Triquote / StringLiteral / StringInterpolated)> */
+		/* 45 String <- <(Triquote / StringRaw / StringLiteral / StringInterpolated)> */
 		func() bool {
-			position86, tokenIndex86, depth86 := position, tokenIndex, depth
+			position105, tokenIndex105, depth105 := position, tokenIndex, depth
 			{
-				position87 := position
+				position106 := position
 				depth++
 				{
-					position88, tokenIndex88, depth88 := position, tokenIndex, depth
+					position107, tokenIndex107, depth107 := position, tokenIndex, depth
 					{
-						position90 := position
+						position109 := position
 						depth++
 						if !_rules[ruleTRIQUOT]() {
-							goto l89
+							goto l108
 						}
 						{
-							position91 := position
+							position110 := position
 							depth++
-						l92:
+						l111:
 							{
-								position93, tokenIndex93, depth93 := position, tokenIndex, depth
+								position112, tokenIndex112, depth112 := position, tokenIndex, depth
 								{
-									position94, tokenIndex94, depth94 := position, tokenIndex, depth
+									position113, tokenIndex113, depth113 := position, tokenIndex, depth
 									if !_rules[ruleTRIQUOT]() {
-										goto l94
+										goto l113
 									}
-									goto l93
-								l94:
-									position, tokenIndex, depth = position94, tokenIndex94, depth94
+									goto l112
+								l113:
+									position, tokenIndex, depth = position113, tokenIndex113, depth113
 								}
 								if !matchDot() {
-									goto l93
+									goto l112
 								}
-								goto l92
-							l93:
-								position, tokenIndex, depth = position93, tokenIndex93, depth93
+								goto l111
+							l112:
+								position, tokenIndex, depth = position112, tokenIndex112, depth112
 							}
 							depth--
-							add(ruleTriquoteBody, position91)
+							add(ruleTriquoteBody, position110)
 						}
 						if !_rules[ruleTRIQUOT]() {
-							goto l89
+							goto l108
 						}
 						depth--
-						add(ruleTriquote, position90)
+						add(ruleTriquote, position109)
+					}
+					goto l107
+				l108:
+					position, tokenIndex, depth = position107, tokenIndex107, depth107
+					if !_rules[ruleStringRaw]() {
+						goto l114
 					}
-					goto l88
-				l89:
-					position, tokenIndex, depth = position88, tokenIndex88, depth88
+					goto l107
+				l114:
+					position, tokenIndex, depth = position107, tokenIndex107, depth107
 					if !_rules[ruleStringLiteral]() {
-						goto l95
+						goto l115
 					}
-					goto l88
-				l95:
-					position, tokenIndex, depth = position88, tokenIndex88, depth88
+					goto l107
+				l115:
+					position, tokenIndex, depth = position107, tokenIndex107, depth107
 					if !_rules[ruleStringInterpolated]() {
-						goto l86
+						goto l105
 					}
 				}
-			l88:
+			l107:
 				depth--
-				add(ruleString, position87)
+				add(ruleString, position106)
 			}
 			return true
-		l86:
-			position, tokenIndex, depth = position86, tokenIndex86, depth86
+		l105:
+			position, tokenIndex, depth = position105, tokenIndex105, depth105
 			return false
 		},
-		/* 34 StringLiteral <- <('\'' (!'\'' .)* '\'')> */
+		/* 46 StringLiteral <- <('\'' (!'\'' .)* '\'')> */
 		func() bool {
-			position96, tokenIndex96, depth96 := position, tokenIndex, depth
+			position116, tokenIndex116, depth116 := position, tokenIndex, depth
 			{
-				position97 := position
+				position117 := position
 				depth++
 				if buffer[position] != rune('\'') {
-					goto l96
+					goto l116
 				}
 				position++
-			l98:
+			l118:
 				{
-					position99, tokenIndex99, depth99 := position, tokenIndex, depth
+					position119, tokenIndex119, depth119 := position, tokenIndex, depth
 					{
-						position100, tokenIndex100, depth100 := position, tokenIndex, depth
+						position120, tokenIndex120, depth120 := position, tokenIndex, depth
 						if buffer[position] != rune('\'') {
-							goto l100
+							goto l120
 						}
 						position++
-						goto l99
-					l100:
-						position, tokenIndex, depth = position100, tokenIndex100, depth100
+						goto l119
+					l120:
+						position, tokenIndex, depth = position120, tokenIndex120, depth120
 					}
 					if !matchDot() {
-						goto l99
+						goto l119
 					}
-					goto l98
-				l99:
-					position, tokenIndex, depth = position99, tokenIndex99, depth99
+					goto l118
+				l119:
+					position, tokenIndex, depth = position119, tokenIndex119, depth119
 				}
 				if buffer[position] != rune('\'') {
-					goto l96
+					goto l116
 				}
 				position++
 				depth--
-				add(ruleStringLiteral, position97)
+				add(ruleStringLiteral, position117)
 			}
 			return true
-		l96:
-			position, tokenIndex, depth = position96, tokenIndex96, depth96
+		l116:
+			position, tokenIndex, depth = position116, tokenIndex116, depth116
 			return false
 		},
-		/* 35 StringInterpolated <- <('"' (!'"' .)* '"')> */
+		/* 47 StringInterpolated <- <('"' (('\\' .) / (!('"' / '\\') .))* '"')> */
 		func() bool {
-			position101, tokenIndex101, depth101 := position, tokenIndex, depth
+			position121, tokenIndex121, depth121 := position, tokenIndex, depth
 			{
-				position102 := position
+				position122 := position
 				depth++
 				if buffer[position] != rune('"') {
-					goto l101
+					goto l121
 				}
 				position++
-			l103:
+			l123:
 				{
-					position104, tokenIndex104, depth104 := position, tokenIndex, depth
+					position124, tokenIndex124, depth124 := position, tokenIndex, depth
 					{
-						position105, tokenIndex105, depth105 := position, tokenIndex, depth
-						if buffer[position] != rune('"') {
-							goto l105
+						position125, tokenIndex125, depth125 := position, tokenIndex, depth
+						if buffer[position] != rune('\\') {
+							goto l126
 						}
 						position++
-						goto l104
-					l105:
-						position, tokenIndex, depth = position105, tokenIndex105, depth105
+						if !matchDot() {
+							goto l126
+						}
+						goto l125
+					l126:
+						position, tokenIndex, depth = position125, tokenIndex125, depth125
+						{
+							position127, tokenIndex127, depth127 := position, tokenIndex, depth
+							{
+								position128, tokenIndex128, depth128 := position, tokenIndex, depth
+								if buffer[position] != rune('"') {
+									goto l129
+								}
+								position++
+								goto l128
+							l129:
+								position, tokenIndex, depth = position128, tokenIndex128, depth128
+								if buffer[position] != rune('\\') {
+									goto l127
+								}
+								position++
+							}
+						l128:
+							goto l124
+						l127:
+							position, tokenIndex, depth = position127, tokenIndex127, depth127
+						}
+						if !matchDot() {
+							goto l124
+						}
+					}
+				l125:
+					goto l123
+				l124:
+					position, tokenIndex, depth = position124, tokenIndex124, depth124
+				}
+				if buffer[position] != rune('"') {
+					goto l121
+				}
+				position++
+				depth--
+				add(ruleStringInterpolated, position122)
+			}
+			return true
+		l121:
+			position, tokenIndex, depth = position121, tokenIndex121, depth121
+			return false
+		},
+		/* 48 StringRaw <- <('`' (!'`' .)* '`')> */
+		func() bool {
+			position130, tokenIndex130, depth130 := position, tokenIndex, depth
+			{
+				position131 := position
+				depth++
+				if buffer[position] != rune('`') {
+					goto l130
+				}
+				position++
+			l132:
+				{
+					position133, tokenIndex133, depth133 := position, tokenIndex, depth
+					{
+						position134, tokenIndex134, depth134 := position, tokenIndex, depth
+						if buffer[position] != rune('`') {
+							goto l134
+						}
+						position++
+						goto l133
+					l134:
+						position, tokenIndex, depth = position134, tokenIndex134, depth134
 					}
 					if !matchDot() {
-						goto l104
+						goto l133
 					}
-					goto l103
-				l104:
-					position, tokenIndex, depth = position104, tokenIndex104, depth104
+					goto l132
+				l133:
+					position, tokenIndex, depth = position133, tokenIndex133, depth133
 				}
-				if buffer[position] != rune('"') {
-					goto l101
+				if buffer[position] != rune('`') {
+					goto l130
 				}
 				position++
 				depth--
-				add(ruleStringInterpolated, position102)
+				add(ruleStringRaw, position131)
 			}
 			return true
-		l101:
-			position, tokenIndex, depth = position101, tokenIndex101, depth101
+		l130:
+			position, tokenIndex, depth = position130, tokenIndex130, depth130
 			return false
 		},
-		/* 36 Triquote <- <(TRIQUOT TriquoteBody TRIQUOT)> */
+		/* 49 Triquote <- <(TRIQUOT TriquoteBody TRIQUOT)> */
 		nil,
-		/* 37 TriquoteBody <- <(!TRIQUOT .)*> */
+		/* 50 TriquoteBody <- <(!TRIQUOT .)*> */
 		nil,
-		/* 38 NullValue <- <('n' 'u' 'l' 'l')> */
+		/* 51 NullValue <- <('n' 'u' 'l' 'l')> */
 		nil,
-		/* 39 Object <- <(OPEN (_ KeyValuePair _)* CLOSE)> */
+		/* 52 Object <- <(OPEN (_ KeyValuePair _)* CLOSE)> */
 		func() bool {
-			position109, tokenIndex109, depth109 := position, tokenIndex, depth
+			position138, tokenIndex138, depth138 := position, tokenIndex, depth
 			{
-				position110 := position
+				position139 := position
 				depth++
 				if !_rules[ruleOPEN]() {
-					goto l109
+					goto l138
 				}
-			l111:
+			l140:
 				{
-					position112, tokenIndex112, depth112 := position, tokenIndex, depth
+					position141, tokenIndex141, depth141 := position, tokenIndex, depth
 					if !_rules[rule_]() {
-						goto l112
+						goto l141
 					}
 					{
-						position113 := position
+						position142 := position
 						depth++
 						{
-							position114 := position
+							position143 := position
 							depth++
 							{
-								position115, tokenIndex115, depth115 := position, tokenIndex, depth
+								position144, tokenIndex144, depth144 := position, tokenIndex, depth
 								if !_rules[ruleIdentifier]() {
-									goto l116
+									goto l145
 								}
-								goto l115
-							l116:
-								position, tokenIndex, depth = position115, tokenIndex115, depth115
+								goto l144
+							l145:
+								position, tokenIndex, depth = position144, tokenIndex144, depth144
+								if !_rules[ruleStringRaw]() {
+									goto l146
+								}
+								goto l144
+							l146:
+								position, tokenIndex, depth = position144, tokenIndex144, depth144
 								if !_rules[ruleStringLiteral]() {
-									goto l117
+									goto l147
 								}
-								goto l115
-							l117:
-								position, tokenIndex, depth = position115, tokenIndex115, depth115
+								goto l144
+							l147:
+								position, tokenIndex, depth = position144, tokenIndex144, depth144
 								if !_rules[ruleStringInterpolated]() {
-									goto l112
+									goto l141
 								}
 							}
-						l115:
+						l144:
 							depth--
-							add(ruleKey, position114)
+							add(ruleKey, position143)
 						}
-						{
-							position118 := position
-							depth++
-							if !_rules[rule_]() {
-								goto l112
-							}
-							if buffer[position] != rune(':') {
-								goto l112
-							}
-							position++
-							if !_rules[rule_]() {
-								goto l112
-							}
-							depth--
-							add(ruleCOLON, position118)
+						if !_rules[ruleCOLON]() {
+							goto l141
 						}
 						{
-							position119 := position
+							position148 := position
 							depth++
 							{
-								position120, tokenIndex120, depth120 := position, tokenIndex, depth
+								position149, tokenIndex149, depth149 := position, tokenIndex, depth
 								if !_rules[ruleArray]() {
-									goto l121
+									goto l150
 								}
-								goto l120
-							l121:
-								position, tokenIndex, depth = position120, tokenIndex120, depth120
+								goto l149
+							l150:
+								position, tokenIndex, depth = position149, tokenIndex149, depth149
 								if !_rules[ruleObject]() {
-									goto l122
+									goto l151
 								}
-								goto l120
-							l122:
-								position, tokenIndex, depth = position120, tokenIndex120, depth120
+								goto l149
+							l151:
+								position, tokenIndex, depth = position149, tokenIndex149, depth149
 								if !_rules[ruleExpression]() {
-									goto l112
+									goto l141
 								}
 							}
-						l120:
+						l149:
 							depth--
-							add(ruleKValue, position119)
+							add(ruleKValue, position148)
 						}
 						{
-							position123, tokenIndex123, depth123 := position, tokenIndex, depth
+							position152, tokenIndex152, depth152 := position, tokenIndex, depth
 							if !_rules[ruleCOMMA]() {
-								goto l123
+								goto l152
 							}
-							goto l124
-						l123:
-							position, tokenIndex, depth = position123, tokenIndex123, depth123
+							goto l153
+						l152:
+							position, tokenIndex, depth = position152, tokenIndex152, depth152
 						}
-					l124:
+					l153:
 						depth--
-						add(ruleKeyValuePair, position113)
+						add(ruleKeyValuePair, position142)
 					}
 					if !_rules[rule_]() {
-						goto l112
+						goto l141
 					}
-					goto l111
-				l112:
-					position, tokenIndex, depth = position112, tokenIndex112, depth112
+					goto l140
+				l141:
+					position, tokenIndex, depth = position141, tokenIndex141, depth141
 				}
 				if !_rules[ruleCLOSE]() {
-					goto l109
+					goto l138
 				}
 				depth--
-				add(ruleObject, position110)
+				add(ruleObject, position139)
 			}
 			return true
-		l109:
-			position, tokenIndex, depth = position109, tokenIndex109, depth109
+		l138:
+			position, tokenIndex, depth = position138, tokenIndex138, depth138
 			return false
 		},
-		/* 40 Array <- <('[' _ ExpressionSequence COMMA? ']')> */
+		/* 53 Array <- <('[' _ ExpressionSequence COMMA? ']')> */
 		func() bool {
-			position125, tokenIndex125, depth125 := position, tokenIndex, depth
+			position154, tokenIndex154, depth154 := position, tokenIndex, depth
 			{
-				position126 := position
+				position155 := position
 				depth++
 				if buffer[position] != rune('[') {
-					goto l125
+					goto l154
 				}
 				position++
 				if !_rules[rule_]() {
-					goto l125
+					goto l154
 				}
 				if !_rules[ruleExpressionSequence]() {
-					goto l125
+					goto l154
 				}
 				{
-					position127, tokenIndex127, depth127 := position, tokenIndex, depth
+					position156, tokenIndex156, depth156 := position, tokenIndex, depth
 					if !_rules[ruleCOMMA]() {
-						goto l127
+						goto l156
 					}
-					goto l128
-				l127:
-					position, tokenIndex, depth = position127, tokenIndex127, depth127
+					goto l157
+				l156:
+					position, tokenIndex, depth = position156, tokenIndex156, depth156
 				}
-			l128:
+			l157:
 				if buffer[position] != rune(']') {
-					goto l125
+					goto l154
 				}
 				position++
 				depth--
-				add(ruleArray, position126)
+				add(ruleArray, position155)
 			}
 			return true
-		l125:
-			position, tokenIndex, depth = position125, tokenIndex125, depth125
+		l154:
+			position, tokenIndex, depth = position154, tokenIndex154, depth154
 			return false
 		},
-		/* 41 RegularExpression <- <('/' (!'/' .)+ '/' ('i' / 'l' / 'm' / 's' / 'u')*)> */
+		/* 54 RegularExpression <- <('/' (!'/' .)+ '/' ('g' / 'i' / 'l' / 'm' / 's' / 'u')*)> */
 		func() bool {
-			position129, tokenIndex129, depth129 := position, tokenIndex, depth
+			position158, tokenIndex158, depth158 := position, tokenIndex, depth
 			{
-				position130 := position
+				position159 := position
 				depth++
 				if buffer[position] != rune('/') {
-					goto l129
+					goto l158
 				}
 				position++
 				{
-					position133, tokenIndex133, depth133 := position, tokenIndex, depth
+					position162, tokenIndex162, depth162 := position, tokenIndex, depth
 					if buffer[position] != rune('/') {
-						goto l133
+						goto l162
 					}
 					position++
-					goto l129
-				l133:
-					position, tokenIndex, depth = position133, tokenIndex133, depth133
+					goto l158
+				l162:
+					position, tokenIndex, depth = position162, tokenIndex162, depth162
 				}
 				if !matchDot() {
-					goto l129
+					goto l158
 				}
-			l131:
+			l160:
 				{
-					position132, tokenIndex132, depth132 := position, tokenIndex, depth
+					position161, tokenIndex161, depth161 := position, tokenIndex, depth
 					{
-						position134, tokenIndex134, depth134 := position, tokenIndex, depth
+						position163, tokenIndex163, depth163 := position, tokenIndex, depth
 						if buffer[position] != rune('/') {
-							goto l134
+							goto l163
 						}
 						position++
-						goto l132
-					l134:
-						position, tokenIndex, depth = position134, tokenIndex134, depth134
+						goto l161
+					l163:
+						position, tokenIndex, depth = position163, tokenIndex163, depth163
 					}
 					if !matchDot() {
-						goto l132
+						goto l161
 					}
-					goto l131
-				l132:
-					position, tokenIndex, depth = position132, tokenIndex132, depth132
+					goto l160
+				l161:
+					position, tokenIndex, depth = position161, tokenIndex161, depth161
 				}
 				if buffer[position] != rune('/') {
-					goto l129
+					goto l158
 				}
 				position++
-			l135:
+			l164:
 				{
-					position136, tokenIndex136, depth136 := position, tokenIndex, depth
+					position165, tokenIndex165, depth165 := position, tokenIndex, depth
 					{
-						position137, tokenIndex137, depth137 := position, tokenIndex, depth
+						position166, tokenIndex166, depth166 := position, tokenIndex, depth
+						if buffer[position] != rune('g') {
+							goto l167
+						}
+						position++
+						goto l166
+					l167:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
 						if buffer[position] != rune('i') {
-							goto l138
+							goto l168
 						}
 						position++
-						goto l137
-					l138:
-						position, tokenIndex, depth = position137, tokenIndex137, depth137
+						goto l166
+					l168:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
 						if buffer[position] != rune('l') {
-							goto l139
+							goto l169
 						}
 						position++
-						goto l137
-					l139:
-						position, tokenIndex, depth = position137, tokenIndex137, depth137
+						goto l166
+					l169:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
 						if buffer[position] != rune('m') {
-							goto l140
+							goto l170
 						}
 						position++
-						goto l137
-					l140:
-						position, tokenIndex, depth = position137, tokenIndex137, depth137
+						goto l166
+					l170:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
 						if buffer[position] != rune('s') {
-							goto l141
+							goto l171
 						}
 						position++
-						goto l137
-					l141:
-						position, tokenIndex, depth = position137, tokenIndex137, depth137
+						goto l166
+					l171:
+						position, tokenIndex, depth = position166, tokenIndex166, depth166
 						if buffer[position] != rune('u') {
-							goto l136
+							goto l165
 						}
 						position++
 					}
-				l137:
-					goto l135
-				l136:
-					position, tokenIndex, depth = position136, tokenIndex136, depth136
+				l166:
+					goto l164
+				l165:
+					position, tokenIndex, depth = position165, tokenIndex165, depth165
 				}
 				depth--
-				add(ruleRegularExpression, position130)
+				add(ruleRegularExpression, position159)
 			}
 			return true
-		l129:
-			position, tokenIndex, depth = position129, tokenIndex129, depth129
+		l158:
+			position, tokenIndex, depth = position158, tokenIndex158, depth158
 			return false
 		},
-		/* 42 KeyValuePair <- <(Key COLON KValue COMMA?)> */
+		/* 55 KeyValuePair <- <(Key COLON KValue COMMA?)> */
 		nil,
-		/* 43 Key <- <(Identifier / StringLiteral / StringInterpolated)> */
+		/* 56 Key <- <(Identifier / StringRaw / StringLiteral / StringInterpolated)> */
 		nil,
-		/* 44 KValue <- <(Array / Object / Expression)> */
+		/* 57 KValue <- <(Array / Object / Expression)> */
 		nil,
-		/* 45 Type <- <(Array / Object / RegularExpression / ScalarType)> */
+		/* 58 Type <- <(Array / Object / RegularExpression / Timestamp / Duration / ByteSize / Now / ScalarType)> */
 		func() bool {
-			position145, tokenIndex145, depth145 := position, tokenIndex, depth
+			position175, tokenIndex175, depth175 := position, tokenIndex, depth
 			{
-				position146 := position
+				position176 := position
 				depth++
 				{
-					position147, tokenIndex147, depth147 := position, tokenIndex, depth
+					position177, tokenIndex177, depth177 := position, tokenIndex, depth
 					if !_rules[ruleArray]() {
-						goto l148
+						goto l178
 					}
-					goto l147
-				l148:
-					position, tokenIndex, depth = position147, tokenIndex147, depth147
+					goto l177
+				l178:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
 					if !_rules[ruleObject]() {
-						goto l149
+						goto l179
 					}
-					goto l147
-				l149:
-					position, tokenIndex, depth = position147, tokenIndex147, depth147
+					goto l177
+				l179:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
 					if !_rules[ruleRegularExpression]() {
-						goto l150
+						goto l180
 					}
-					goto l147
-				l150:
-					position, tokenIndex, depth = position147, tokenIndex147, depth147
+					goto l177
+				l180:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
 					{
-						position151 := position
+						position182 := position
 						depth++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if buffer[position] != rune('-') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if buffer[position] != rune('-') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l181
+						}
+						position++
 						{
-							position152, tokenIndex152, depth152 := position, tokenIndex, depth
+							position183, tokenIndex183, depth183 := position, tokenIndex, depth
+							if buffer[position] != rune('T') {
+								goto l183
+							}
+							position++
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l183
+							}
+							position++
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l183
+							}
+							position++
+							if buffer[position] != rune(':') {
+								goto l183
+							}
+							position++
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l183
+							}
+							position++
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l183
+							}
+							position++
 							{
-								position154 := position
-								depth++
+								position185, tokenIndex185, depth185 := position, tokenIndex, depth
+								if buffer[position] != rune(':') {
+									goto l185
+								}
+								position++
+								if c := buffer[position]; c < rune('0') || c > rune('9') {
+									goto l185
+								}
+								position++
+								if c := buffer[position]; c < rune('0') || c > rune('9') {
+									goto l185
+								}
+								position++
 								{
-									position155, tokenIndex155, depth155 := position, tokenIndex, depth
-									if buffer[position] != rune('t') {
-										goto l156
+									position187, tokenIndex187, depth187 := position, tokenIndex, depth
+									if buffer[position] != rune('.') {
+										goto l187
 									}
 									position++
-									if buffer[position] != rune('r') {
-										goto l156
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l187
 									}
 									position++
-									if buffer[position] != rune('u') {
-										goto l156
+								l189:
+									{
+										position190, tokenIndex190, depth190 := position, tokenIndex, depth
+										if c := buffer[position]; c < rune('0') || c > rune('9') {
+											goto l190
+										}
+										position++
+										goto l189
+									l190:
+										position, tokenIndex, depth = position190, tokenIndex190, depth190
+									}
+									goto l188
+								l187:
+									position, tokenIndex, depth = position187, tokenIndex187, depth187
+								}
+							l188:
+								goto l186
+							l185:
+								position, tokenIndex, depth = position185, tokenIndex185, depth185
+							}
+						l186:
+							{
+								position191, tokenIndex191, depth191 := position, tokenIndex, depth
+								{
+									position193 := position
+									depth++
+									{
+										position194, tokenIndex194, depth194 := position, tokenIndex, depth
+										if buffer[position] != rune('Z') {
+											goto l195
+										}
+										position++
+										goto l194
+									l195:
+										position, tokenIndex, depth = position194, tokenIndex194, depth194
+										{
+											position196, tokenIndex196, depth196 := position, tokenIndex, depth
+											if buffer[position] != rune('+') {
+												goto l197
+											}
+											position++
+											goto l196
+										l197:
+											position, tokenIndex, depth = position196, tokenIndex196, depth196
+											if buffer[position] != rune('-') {
+												goto l191
+											}
+											position++
+										}
+									l196:
+										if c := buffer[position]; c < rune('0') || c > rune('9') {
+											goto l191
+										}
+										position++
+										if c := buffer[position]; c < rune('0') || c > rune('9') {
+											goto l191
+										}
+										position++
+										{
+											position198, tokenIndex198, depth198 := position, tokenIndex, depth
+											if buffer[position] != rune(':') {
+												goto l198
+											}
+											position++
+											goto l199
+										l198:
+											position, tokenIndex, depth = position198, tokenIndex198, depth198
+										}
+									l199:
+										if c := buffer[position]; c < rune('0') || c > rune('9') {
+											goto l191
+										}
+										position++
+										if c := buffer[position]; c < rune('0') || c > rune('9') {
+											goto l191
+										}
+										position++
+									}
+								l194:
+									depth--
+									add(ruleTimeZone, position193)
+								}
+								goto l192
+							l191:
+								position, tokenIndex, depth = position191, tokenIndex191, depth191
+							}
+						l192:
+							goto l184
+						l183:
+							position, tokenIndex, depth = position183, tokenIndex183, depth183
+						}
+					l184:
+						depth--
+						add(ruleTimestamp, position182)
+					}
+					goto l177
+				l181:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
+					if !_rules[ruleDuration]() {
+						goto l200
+					}
+					goto l177
+				l200:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
+					{
+						position202 := position
+						depth++
+						if !_rules[rulePositiveInteger]() {
+							goto l201
+						}
+						{
+							position203, tokenIndex203, depth203 := position, tokenIndex, depth
+							if buffer[position] != rune('.') {
+								goto l203
+							}
+							position++
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l203
+							}
+							position++
+						l205:
+							{
+								position206, tokenIndex206, depth206 := position, tokenIndex, depth
+								if c := buffer[position]; c < rune('0') || c > rune('9') {
+									goto l206
+								}
+								position++
+								goto l205
+							l206:
+								position, tokenIndex, depth = position206, tokenIndex206, depth206
+							}
+							goto l204
+						l203:
+							position, tokenIndex, depth = position203, tokenIndex203, depth203
+						}
+					l204:
+						{
+							position207 := position
+							depth++
+							{
+								position208, tokenIndex208, depth208 := position, tokenIndex, depth
+								{
+									position210, tokenIndex210, depth210 := position, tokenIndex, depth
+									if buffer[position] != rune('K') {
+										goto l211
+									}
+									position++
+									goto l210
+								l211:
+									position, tokenIndex, depth = position210, tokenIndex210, depth210
+									if buffer[position] != rune('M') {
+										goto l212
+									}
+									position++
+									goto l210
+								l212:
+									position, tokenIndex, depth = position210, tokenIndex210, depth210
+									if buffer[position] != rune('G') {
+										goto l213
+									}
+									position++
+									goto l210
+								l213:
+									position, tokenIndex, depth = position210, tokenIndex210, depth210
+									if buffer[position] != rune('T') {
+										goto l214
+									}
+									position++
+									goto l210
+								l214:
+									position, tokenIndex, depth = position210, tokenIndex210, depth210
+									if buffer[position] != rune('P') {
+										goto l209
+									}
+									position++
+								}
+							l210:
+								if buffer[position] != rune('i') {
+									goto l209
+								}
+								position++
+								if buffer[position] != rune('B') {
+									goto l209
+								}
+								position++
+								goto l208
+							l209:
+								position, tokenIndex, depth = position208, tokenIndex208, depth208
+								{
+									position216, tokenIndex216, depth216 := position, tokenIndex, depth
+									if buffer[position] != rune('k') {
+										goto l217
+									}
+									position++
+									goto l216
+								l217:
+									position, tokenIndex, depth = position216, tokenIndex216, depth216
+									if buffer[position] != rune('K') {
+										goto l218
+									}
+									position++
+									goto l216
+								l218:
+									position, tokenIndex, depth = position216, tokenIndex216, depth216
+									if buffer[position] != rune('M') {
+										goto l219
+									}
+									position++
+									goto l216
+								l219:
+									position, tokenIndex, depth = position216, tokenIndex216, depth216
+									if buffer[position] != rune('G') {
+										goto l220
+									}
+									position++
+									goto l216
+								l220:
+									position, tokenIndex, depth = position216, tokenIndex216, depth216
+									if buffer[position] != rune('T') {
+										goto l221
+									}
+									position++
+									goto l216
+								l221:
+									position, tokenIndex, depth = position216, tokenIndex216, depth216
+									if buffer[position] != rune('P') {
+										goto l215
+									}
+									position++
+								}
+							l216:
+								if buffer[position] != rune('B') {
+									goto l215
+								}
+								position++
+								goto l208
+							l215:
+								position, tokenIndex, depth = position208, tokenIndex208, depth208
+								if buffer[position] != rune('B') {
+									goto l201
+								}
+								position++
+							}
+						l208:
+							depth--
+							add(ruleByteSizeUnit, position207)
+						}
+						{
+							position222, tokenIndex222, depth222 := position, tokenIndex, depth
+							{
+								position223, tokenIndex223, depth223 := position, tokenIndex, depth
+								if c := buffer[position]; c < rune('a') || c > rune('z') {
+									goto l224
+								}
+								position++
+								goto l223
+							l224:
+								position, tokenIndex, depth = position223, tokenIndex223, depth223
+								if c := buffer[position]; c < rune('A') || c > rune('Z') {
+									goto l225
+								}
+								position++
+								goto l223
+							l225:
+								position, tokenIndex, depth = position223, tokenIndex223, depth223
+								{
+									position227, tokenIndex227, depth227 := position, tokenIndex, depth
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l228
+									}
+									position++
+									goto l227
+								l228:
+									position, tokenIndex, depth = position227, tokenIndex227, depth227
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l226
+									}
+									position++
+								}
+							l227:
+								goto l223
+							l226:
+								position, tokenIndex, depth = position223, tokenIndex223, depth223
+								if buffer[position] != rune('_') {
+									goto l222
+								}
+								position++
+							}
+						l223:
+							goto l201
+						l222:
+							position, tokenIndex, depth = position222, tokenIndex222, depth222
+						}
+						depth--
+						add(ruleByteSize, position202)
+					}
+					goto l177
+				l201:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
+					{
+						position230 := position
+						depth++
+						if buffer[position] != rune('n') {
+							goto l229
+						}
+						position++
+						if buffer[position] != rune('o') {
+							goto l229
+						}
+						position++
+						if buffer[position] != rune('w') {
+							goto l229
+						}
+						position++
+						{
+							position231, tokenIndex231, depth231 := position, tokenIndex, depth
+							{
+								position232, tokenIndex232, depth232 := position, tokenIndex, depth
+								if c := buffer[position]; c < rune('a') || c > rune('z') {
+									goto l233
+								}
+								position++
+								goto l232
+							l233:
+								position, tokenIndex, depth = position232, tokenIndex232, depth232
+								if c := buffer[position]; c < rune('A') || c > rune('Z') {
+									goto l234
+								}
+								position++
+								goto l232
+							l234:
+								position, tokenIndex, depth = position232, tokenIndex232, depth232
+								{
+									position236, tokenIndex236, depth236 := position, tokenIndex, depth
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l237
+									}
+									position++
+									goto l236
+								l237:
+									position, tokenIndex, depth = position236, tokenIndex236, depth236
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l235
+									}
+									position++
+								}
+							l236:
+								goto l232
+							l235:
+								position, tokenIndex, depth = position232, tokenIndex232, depth232
+								if buffer[position] != rune('_') {
+									goto l231
+								}
+								position++
+							}
+						l232:
+							goto l229
+						l231:
+							position, tokenIndex, depth = position231, tokenIndex231, depth231
+						}
+						depth--
+						add(ruleNow, position230)
+					}
+					goto l177
+				l229:
+					position, tokenIndex, depth = position177, tokenIndex177, depth177
+					{
+						position238 := position
+						depth++
+						{
+							position239, tokenIndex239, depth239 := position, tokenIndex, depth
+							{
+								position241 := position
+								depth++
+								{
+									position242, tokenIndex242, depth242 := position, tokenIndex, depth
+									if buffer[position] != rune('t') {
+										goto l243
+									}
+									position++
+									if buffer[position] != rune('r') {
+										goto l243
+									}
+									position++
+									if buffer[position] != rune('u') {
+										goto l243
 									}
 									position++
 									if buffer[position] != rune('e') {
-										goto l156
+										goto l243
 									}
 									position++
-									goto l155
-								l156:
-									position, tokenIndex, depth = position155, tokenIndex155, depth155
+									goto l242
+								l243:
+									position, tokenIndex, depth = position242, tokenIndex242, depth242
 									if buffer[position] != rune('f') {
-										goto l153
+										goto l240
 									}
 									position++
 									if buffer[position] != rune('a') {
-										goto l153
+										goto l240
 									}
 									position++
 									if buffer[position] != rune('l') {
-										goto l153
+										goto l240
 									}
 									position++
 									if buffer[position] != rune('s') {
-										goto l153
+										goto l240
 									}
 									position++
 									if buffer[position] != rune('e') {
-										goto l153
+										goto l240
 									}
 									position++
 								}
-							l155:
+							l242:
 								depth--
-								add(ruleBoolean, position154)
+								add(ruleBoolean, position241)
 							}
-							goto l152
-						l153:
-							position, tokenIndex, depth = position152, tokenIndex152, depth152
+							goto l239
+						l240:
+							position, tokenIndex, depth = position239, tokenIndex239, depth239
 							{
-								position158 := position
+								position245 := position
 								depth++
 								if !_rules[ruleInteger]() {
-									goto l157
+									goto l244
 								}
 								{
-									position159, tokenIndex159, depth159 := position, tokenIndex, depth
+									position246, tokenIndex246, depth246 := position, tokenIndex, depth
 									if buffer[position] != rune('.') {
-										goto l159
+										goto l246
 									}
 									position++
 									if c := buffer[position]; c < rune('0') || c > rune('9') {
-										goto l159
+										goto l246
 									}
 									position++
-								l161:
+								l248:
 									{
-										position162, tokenIndex162, depth162 := position, tokenIndex, depth
+										position249, tokenIndex249, depth249 := position, tokenIndex, depth
 										if c := buffer[position]; c < rune('0') || c > rune('9') {
-											goto l162
+											goto l249
+										}
+										position++
+										goto l248
+									l249:
+										position, tokenIndex, depth = position249, tokenIndex249, depth249
+									}
+									goto l247
+								l246:
+									position, tokenIndex, depth = position246, tokenIndex246, depth246
+								}
+							l247:
+								if buffer[position] != rune('D') {
+									goto l244
+								}
+								position++
+								{
+									position250, tokenIndex250, depth250 := position, tokenIndex, depth
+									{
+										position251, tokenIndex251, depth251 := position, tokenIndex, depth
+										if c := buffer[position]; c < rune('a') || c > rune('z') {
+											goto l252
+										}
+										position++
+										goto l251
+									l252:
+										position, tokenIndex, depth = position251, tokenIndex251, depth251
+										if c := buffer[position]; c < rune('A') || c > rune('Z') {
+											goto l253
+										}
+										position++
+										goto l251
+									l253:
+										position, tokenIndex, depth = position251, tokenIndex251, depth251
+										{
+											position255, tokenIndex255, depth255 := position, tokenIndex, depth
+											if c := buffer[position]; c < rune('0') || c > rune('9') {
+												goto l256
+											}
+											position++
+											goto l255
+										l256:
+											position, tokenIndex, depth = position255, tokenIndex255, depth255
+											if c := buffer[position]; c < rune('0') || c > rune('9') {
+												goto l254
+											}
+											position++
+										}
+									l255:
+										goto l251
+									l254:
+										position, tokenIndex, depth = position251, tokenIndex251, depth251
+										if buffer[position] != rune('_') {
+											goto l250
 										}
 										position++
-										goto l161
-									l162:
-										position, tokenIndex, depth = position162, tokenIndex162, depth162
 									}
-									goto l160
-								l159:
-									position, tokenIndex, depth = position159, tokenIndex159, depth159
+								l251:
+									goto l244
+								l250:
+									position, tokenIndex, depth = position250, tokenIndex250, depth250
 								}
-							l160:
 								depth--
-								add(ruleFloat, position158)
+								add(ruleDecimal, position245)
 							}
-							goto l152
-						l157:
-							position, tokenIndex, depth = position152, tokenIndex152, depth152
+							goto l239
+						l244:
+							position, tokenIndex, depth = position239, tokenIndex239, depth239
+							{
+								position258 := position
+								depth++
+								if !_rules[ruleInteger]() {
+									goto l257
+								}
+								if buffer[position] != rune('.') {
+									goto l257
+								}
+								position++
+								if c := buffer[position]; c < rune('0') || c > rune('9') {
+									goto l257
+								}
+								position++
+							l259:
+								{
+									position260, tokenIndex260, depth260 := position, tokenIndex, depth
+									if c := buffer[position]; c < rune('0') || c > rune('9') {
+										goto l260
+									}
+									position++
+									goto l259
+								l260:
+									position, tokenIndex, depth = position260, tokenIndex260, depth260
+								}
+								depth--
+								add(ruleFloat, position258)
+							}
+							goto l239
+						l257:
+							position, tokenIndex, depth = position239, tokenIndex239, depth239
 							if !_rules[ruleInteger]() {
-								goto l163
+								goto l261
 							}
-							goto l152
-						l163:
-							position, tokenIndex, depth = position152, tokenIndex152, depth152
+							goto l239
+						l261:
+							position, tokenIndex, depth = position239, tokenIndex239, depth239
 							if !_rules[ruleString]() {
-								goto l164
+								goto l262
 							}
-							goto l152
-						l164:
-							position, tokenIndex, depth = position152, tokenIndex152, depth152
+							goto l239
+						l262:
+							position, tokenIndex, depth = position239, tokenIndex239, depth239
 							{
-								position165 := position
+								position263 := position
 								depth++
 								if buffer[position] != rune('n') {
-									goto l145
+									goto l175
 								}
 								position++
 								if buffer[position] != rune('u') {
-									goto l145
+									goto l175
 								}
 								position++
 								if buffer[position] != rune('l') {
-									goto l145
+									goto l175
 								}
 								position++
 								if buffer[position] != rune('l') {
-									goto l145
+									goto l175
 								}
 								position++
 								depth--
-								add(ruleNullValue, position165)
+								add(ruleNullValue, position263)
 							}
 						}
-					l152:
+					l239:
 						depth--
-						add(ruleScalarType, position151)
+						add(ruleScalarType, position238)
 					}
 				}
-			l147:
+			l177:
 				depth--
-				add(ruleType, position146)
+				add(ruleType, position176)
 			}
 			return true
-		l145:
-			position, tokenIndex, depth = position145, tokenIndex145, depth145
+		l175:
+			position, tokenIndex, depth = position175, tokenIndex175, depth175
 			return false
 		},
-		/* 46 Exponentiate <- <(_ ('*' '*') _)> */
-		nil,
-		/* 47 Multiply <- <(_ '*' _)> */
-		nil,
-		/* 48 Divide <- <(_ '/' _)> */
-		nil,
-		/* 49 Modulus <- <(_ '%' _)> */
-		nil,
-		/* 50 Add <- <(_ '+' _)> */
-		nil,
-		/* 51 Subtract <- <(_ '-' _)> */
-		nil,
-		/* 52 BitwiseAnd <- <(_ '&' _)> */
-		nil,
-		/* 53 BitwiseOr <- <(_ '|' _)> */
-		nil,
-		/* 54 BitwiseNot <- <(_ '~' _)> */
-		nil,
-		/* 55 BitwiseXor <- <(_ '^' _)> */
-		nil,
-		/* 56 MatchOperator <- <(Match / Unmatch)> */
-		nil,
-		/* 57 Unmatch <- <(_ ('!' '~') _)> */
-		nil,
-		/* 58 Match <- <(_ ('=' '~') _)> */
-		nil,
-		/* 59 Operator <- <(_ (Exponentiate / Multiply / Divide / Modulus / Add / Subtract / BitwiseAnd / BitwiseOr / BitwiseNot / BitwiseXor) _)> */
+		/* 59 Timestamp <- <([0-9] [0-9] [0-9] [0-9] '-' [0-9] [0-9] '-' [0-9] [0-9] ('T' [0-9] [0-9] ':' [0-9] [0-9] (':' [0-9] [0-9] ('.' [0-9]+)?)? TimeZone?)?)> */
 		nil,
-		/* 60 AssignmentOperator <- <(_ (AssignEq / StarEq / DivEq / PlusEq / MinusEq / AndEq / OrEq / Append) _)> */
+		/* 60 TimeZone <- <('Z' / (('+' / '-') [0-9] [0-9] ':'? [0-9] [0-9]))> */
 		nil,
-		/* 61 AssignEq <- <(_ '=' _)> */
-		nil,
-		/* 62 StarEq <- <(_ ('*' '=') _)> */
-		nil,
-		/* 63 DivEq <- <(_ ('/' '=') _)> */
-		nil,
-		/* 64 PlusEq <- <(_ ('+' '=') _)> */
-		nil,
-		/* 65 MinusEq <- <(_ ('-' '=') _)> */
-		nil,
-		/* 66 AndEq <- <(_ ('&' '=') _)> */
-		nil,
-		/* 67 OrEq <- <(_ ('|' '=') _)> */
-		nil,
-		/* 68 Append <- <(_ ('<' '<') _)> */
-		nil,
-		/* 69 ComparisonOperator <- <(_ (Equality / NonEquality / GreaterEqual / LessEqual / GreaterThan / LessThan / Membership / NonMembership) _)> */
-		nil,
-		/* 70 Equality <- <(_ ('=' '=') _)> */
-		nil,
-		/* 71 NonEquality <- <(_ ('!' '=') _)> */
-		nil,
-		/* 72 GreaterThan <- <(_ '>' _)> */
-		nil,
-		/* 73 GreaterEqual <- <(_ ('>' '=') _)> */
-		nil,
-		/* 74 LessEqual <- <(_ ('<' '=') _)> */
-		nil,
-		/* 75 LessThan <- <(_ '<' _)> */
-		nil,
-		/* 76 Membership <- <(_ ('i' 'n') _)> */
-		nil,
-		/* 77 NonMembership <- <(_ ('n' 'o' 't') __ ('i' 'n') _)> */
-		nil,
-		/* 78 Variable <- <(('$' VariableNameSequence) / SKIPVAR)> */
+		/* 61 Duration <- <('-'? (PositiveInteger ('.' [0-9]+)? DurationUnit)+ !([a-z] / [A-Z] / ([0-9] / [0-9]) / '_'))> */
 		func() bool {
-			position198, tokenIndex198, depth198 := position, tokenIndex, depth
+			position266, tokenIndex266, depth266 := position, tokenIndex, depth
 			{
-				position199 := position
+				position267 := position
 				depth++
 				{
-					position200, tokenIndex200, depth200 := position, tokenIndex, depth
-					if buffer[position] != rune('$') {
-						goto l201
+					position268, tokenIndex268, depth268 := position, tokenIndex, depth
+					if buffer[position] != rune('-') {
+						goto l268
+					}
+					position++
+					goto l269
+				l268:
+					position, tokenIndex, depth = position268, tokenIndex268, depth268
+				}
+			l269:
+				if !_rules[rulePositiveInteger]() {
+					goto l266
+				}
+				{
+					position272, tokenIndex272, depth272 := position, tokenIndex, depth
+					if buffer[position] != rune('.') {
+						goto l272
+					}
+					position++
+					if c := buffer[position]; c < rune('0') || c > rune('9') {
+						goto l272
 					}
 					position++
+				l274:
 					{
-						position202 := position
-						depth++
-					l203:
-						{
-							position204, tokenIndex204, depth204 := position, tokenIndex, depth
-							if !_rules[ruleVariableName]() {
-								goto l204
-							}
-							{
-								position205 := position
-								depth++
-								if buffer[position] != rune('.') {
-									goto l204
-								}
-								position++
-								depth--
-								add(ruleDOT, position205)
+						position275, tokenIndex275, depth275 := position, tokenIndex, depth
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l275
+						}
+						position++
+						goto l274
+					l275:
+						position, tokenIndex, depth = position275, tokenIndex275, depth275
+					}
+					goto l273
+				l272:
+					position, tokenIndex, depth = position272, tokenIndex272, depth272
+				}
+			l273:
+				{
+					position276 := position
+					depth++
+					{
+						position277, tokenIndex277, depth277 := position, tokenIndex, depth
+						if buffer[position] != rune('n') {
+							goto l278
+						}
+						position++
+						if buffer[position] != rune('s') {
+							goto l278
+						}
+						position++
+						goto l277
+					l278:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('u') {
+							goto l279
+						}
+						position++
+						if buffer[position] != rune('s') {
+							goto l279
+						}
+						position++
+						goto l277
+					l279:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('m') {
+							goto l280
+						}
+						position++
+						if buffer[position] != rune('s') {
+							goto l280
+						}
+						position++
+						goto l277
+					l280:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('s') {
+							goto l281
+						}
+						position++
+						goto l277
+					l281:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('m') {
+							goto l282
+						}
+						position++
+						goto l277
+					l282:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('h') {
+							goto l283
+						}
+						position++
+						goto l277
+					l283:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('d') {
+							goto l284
+						}
+						position++
+						goto l277
+					l284:
+						position, tokenIndex, depth = position277, tokenIndex277, depth277
+						if buffer[position] != rune('w') {
+							goto l266
+						}
+						position++
+					}
+				l277:
+					depth--
+					add(ruleDurationUnit, position276)
+				}
+			l270:
+				{
+					position271, tokenIndex271, depth271 := position, tokenIndex, depth
+					if !_rules[rulePositiveInteger]() {
+						goto l271
+					}
+					{
+						position285, tokenIndex285, depth285 := position, tokenIndex, depth
+						if buffer[position] != rune('.') {
+							goto l285
+						}
+						position++
+						if c := buffer[position]; c < rune('0') || c > rune('9') {
+							goto l285
+						}
+						position++
+					l287:
+						{
+							position288, tokenIndex288, depth288 := position, tokenIndex, depth
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l288
 							}
-							goto l203
-						l204:
-							position, tokenIndex, depth = position204, tokenIndex204, depth204
+							position++
+							goto l287
+						l288:
+							position, tokenIndex, depth = position288, tokenIndex288, depth288
 						}
-						if !_rules[ruleVariableName]() {
-							goto l201
+						goto l286
+					l285:
+						position, tokenIndex, depth = position285, tokenIndex285, depth285
+					}
+				l286:
+					{
+						position289 := position
+						depth++
+						{
+							position290, tokenIndex290, depth290 := position, tokenIndex, depth
+							if buffer[position] != rune('n') {
+								goto l291
+							}
+							position++
+							if buffer[position] != rune('s') {
+								goto l291
+							}
+							position++
+							goto l290
+						l291:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('u') {
+								goto l292
+							}
+							position++
+							if buffer[position] != rune('s') {
+								goto l292
+							}
+							position++
+							goto l290
+						l292:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('m') {
+								goto l293
+							}
+							position++
+							if buffer[position] != rune('s') {
+								goto l293
+							}
+							position++
+							goto l290
+						l293:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('s') {
+								goto l294
+							}
+							position++
+							goto l290
+						l294:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('m') {
+								goto l295
+							}
+							position++
+							goto l290
+						l295:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('h') {
+								goto l296
+							}
+							position++
+							goto l290
+						l296:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('d') {
+								goto l297
+							}
+							position++
+							goto l290
+						l297:
+							position, tokenIndex, depth = position290, tokenIndex290, depth290
+							if buffer[position] != rune('w') {
+								goto l271
+							}
+							position++
 						}
+					l290:
 						depth--
-						add(ruleVariableNameSequence, position202)
+						add(ruleDurationUnit, position289)
 					}
-					goto l200
-				l201:
-					position, tokenIndex, depth = position200, tokenIndex200, depth200
+					goto l270
+				l271:
+					position, tokenIndex, depth = position271, tokenIndex271, depth271
+				}
+				{
+					position298, tokenIndex298, depth298 := position, tokenIndex, depth
 					{
-						position206 := position
-						depth++
-						if !_rules[rule_]() {
-							goto l198
+						position299, tokenIndex299, depth299 := position, tokenIndex, depth
+						if c := buffer[position]; c < rune('a') || c > rune('z') {
+							goto l300
 						}
-						if buffer[position] != rune('_') {
-							goto l198
+						position++
+						goto l299
+					l300:
+						position, tokenIndex, depth = position299, tokenIndex299, depth299
+						if c := buffer[position]; c < rune('A') || c > rune('Z') {
+							goto l301
 						}
 						position++
-						if !_rules[rule_]() {
-							goto l198
+						goto l299
+					l301:
+						position, tokenIndex, depth = position299, tokenIndex299, depth299
+						{
+							position303, tokenIndex303, depth303 := position, tokenIndex, depth
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l304
+							}
+							position++
+							goto l303
+						l304:
+							position, tokenIndex, depth = position303, tokenIndex303, depth303
+							if c := buffer[position]; c < rune('0') || c > rune('9') {
+								goto l302
+							}
+							position++
 						}
-						depth--
-						add(ruleSKIPVAR, position206)
+					l303:
+						goto l299
+					l302:
+						position, tokenIndex, depth = position299, tokenIndex299, depth299
+						if buffer[position] != rune('_') {
+							goto l298
+						}
+						position++
 					}
+				l299:
+					goto l266
+				l298:
+					position, tokenIndex, depth = position298, tokenIndex298, depth298
 				}
-			l200:
 				depth--
-				add(ruleVariable, position199)
+				add(ruleDuration, position267)
 			}
 			return true
-		l198:
-			position, tokenIndex, depth = position198, tokenIndex198, depth198
+		l266:
+			position, tokenIndex, depth = position266, tokenIndex266, depth266
 			return false
 		},
-		/* 79 VariableNameSequence <- <((VariableName DOT)* VariableName)> */
+		/* 62 DurationUnit <- <(('n' 's') / ('u' 's') / ('m' 's') / 's' / 'm' / 'h' / 'd' / 'w')> */
+		nil,
+		/* 63 ByteSize <- <(PositiveInteger ('.' [0-9]+)? ByteSizeUnit !([a-z] / [A-Z] / ([0-9] / [0-9]) / '_'))> */
+		nil,
+		/* 64 ByteSizeUnit <- <((('K' / 'M' / 'G' / 'T' / 'P') ('i' 'B')) / (('k' / 'K' / 'M' / 'G' / 'T' / 'P') 'B') / 'B')> */
+		nil,
+		/* 65 Now <- <('n' 'o' 'w' !([a-z] / [A-Z] / ([0-9] / [0-9]) / '_'))> */
 		nil,
-		/* 80 VariableName <- <(Identifier ('[' _ VariableIndex _ ']')?)> */
+		/* 66 Exponentiate <- <(_ ('*' '*') _)> */
+		nil,
+		/* 67 Multiply <- <(_ '*' _)> */
+		nil,
+		/* 68 Divide <- <(_ '/' _)> */
+		nil,
+		/* 69 Modulus <- <(_ '%' _)> */
+		nil,
+		/* 70 Add <- <(_ '+' _)> */
+		nil,
+		/* 71 Subtract <- <(_ '-' _)> */
+		nil,
+		/* 72 BitwiseAnd <- <(_ '&' _)> */
+		nil,
+		/* 73 BitwiseOr <- <(_ '|' _)> */
+		nil,
+		/* 74 BitwiseNot <- <(_ '~' _)> */
+		nil,
+		/* 75 BitwiseXor <- <(_ '^' _)> */
+		nil,
+		/* 76 MatchOperator <- <(Match / Unmatch)> */
 		func() bool {
-			position208, tokenIndex208, depth208 := position, tokenIndex, depth
+			position319, tokenIndex319, depth319 := position, tokenIndex, depth
 			{
-				position209 := position
+				position320 := position
 				depth++
-				if !_rules[ruleIdentifier]() {
-					goto l208
-				}
 				{
-					position210, tokenIndex210, depth210 := position, tokenIndex, depth
-					if buffer[position] != rune('[') {
-						goto l210
-					}
-					position++
-					if !_rules[rule_]() {
-						goto l210
+					position321, tokenIndex321, depth321 := position, tokenIndex, depth
+					if !_rules[ruleMatch]() {
+						goto l322
 					}
+					goto l321
+				l322:
+					position, tokenIndex, depth = position321, tokenIndex321, depth321
 					{
-						position212 := position
+						position323 := position
 						depth++
-						if !_rules[ruleExpression]() {
-							goto l210
+						if !_rules[rule_]() {
+							goto l319
+						}
+						if buffer[position] != rune('!') {
+							goto l319
+						}
+						position++
+						if buffer[position] != rune('~') {
+							goto l319
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l319
 						}
 						depth--
-						add(ruleVariableIndex, position212)
-					}
-					if !_rules[rule_]() {
-						goto l210
+						add(ruleUnmatch, position323)
 					}
-					if buffer[position] != rune(']') {
-						goto l210
-					}
-					position++
-					goto l211
-				l210:
-					position, tokenIndex, depth = position210, tokenIndex210, depth210
 				}
-			l211:
+			l321:
 				depth--
-				add(ruleVariableName, position209)
+				add(ruleMatchOperator, position320)
 			}
 			return true
-		l208:
-			position, tokenIndex, depth = position208, tokenIndex208, depth208
+		l319:
+			position, tokenIndex, depth = position319, tokenIndex319, depth319
 			return false
 		},
-		/* 81 VariableIndex <- <Expression> */
+		/* 77 Unmatch <- <(_ ('!' '~') _)> */
 		nil,
-		/* 82 Block <- <(_ (COMMENT / FlowControlWord / StatementBlock) SEMI? _)> */
+		/* 78 Match <- <(_ ('=' '~') _)> */
+		func() bool {
+			position325, tokenIndex325, depth325 := position, tokenIndex, depth
+			{
+				position326 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l325
+				}
+				if buffer[position] != rune('=') {
+					goto l325
+				}
+				position++
+				if buffer[position] != rune('~') {
+					goto l325
+				}
+				position++
+				if !_rules[rule_]() {
+					goto l325
+				}
+				depth--
+				add(ruleMatch, position326)
+			}
+			return true
+		l325:
+			position, tokenIndex, depth = position325, tokenIndex325, depth325
+			return false
+		},
+		/* 79 Operator <- <(_ (Exponentiate / Multiply / Divide / Modulus / Add / Subtract / BitwiseAnd / BitwiseOr / BitwiseNot / BitwiseXor) _)> */
 		func() bool {
-			position214, tokenIndex214, depth214 := position, tokenIndex, depth
+			position327, tokenIndex327, depth327 := position, tokenIndex, depth
 			{
-				position215 := position
+				position328 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l214
+					goto l327
 				}
 				{
-					position216, tokenIndex216, depth216 := position, tokenIndex, depth
+					position329, tokenIndex329, depth329 := position, tokenIndex, depth
 					{
-						position218 := position
+						position331 := position
 						depth++
 						if !_rules[rule_]() {
-							goto l217
+							goto l330
 						}
-						if buffer[position] != rune('#') {
-							goto l217
+						if buffer[position] != rune('*') {
+							goto l330
 						}
 						position++
-					l219:
-						{
-							position220, tokenIndex220, depth220 := position, tokenIndex, depth
-							{
-								position221, tokenIndex221, depth221 := position, tokenIndex, depth
-								if buffer[position] != rune('\n') {
-									goto l221
-								}
-								position++
-								goto l220
-							l221:
-								position, tokenIndex, depth = position221, tokenIndex221, depth221
-							}
-							if !matchDot() {
-								goto l220
-							}
-							goto l219
-						l220:
-							position, tokenIndex, depth = position220, tokenIndex220, depth220
+						if buffer[position] != rune('*') {
+							goto l330
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l330
 						}
 						depth--
-						add(ruleCOMMENT, position218)
+						add(ruleExponentiate, position331)
 					}
-					goto l216
-				l217:
-					position, tokenIndex, depth = position216, tokenIndex216, depth216
+					goto l329
+				l330:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
 					{
-						position223 := position
+						position333 := position
 						depth++
-						{
-							position224, tokenIndex224, depth224 := position, tokenIndex, depth
-							{
-								position226 := position
-								depth++
-								{
-									position227 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l225
-									}
-									if buffer[position] != rune('b') {
-										goto l225
-									}
-									position++
-									if buffer[position] != rune('r') {
-										goto l225
-									}
-									position++
-									if buffer[position] != rune('e') {
-										goto l225
-									}
-									position++
-									if buffer[position] != rune('a') {
-										goto l225
-									}
-									position++
-									if buffer[position] != rune('k') {
-										goto l225
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l225
-									}
-									depth--
-									add(ruleBREAK, position227)
-								}
-								{
-									position228, tokenIndex228, depth228 := position, tokenIndex, depth
-									if !_rules[rulePositiveInteger]() {
-										goto l228
+						if !_rules[rule_]() {
+							goto l332
+						}
+						if buffer[position] != rune('*') {
+							goto l332
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l332
+						}
+						depth--
+						add(ruleMultiply, position333)
+					}
+					goto l329
+				l332:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position335 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l334
+						}
+						if buffer[position] != rune('/') {
+							goto l334
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l334
+						}
+						depth--
+						add(ruleDivide, position335)
+					}
+					goto l329
+				l334:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position337 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l336
+						}
+						if buffer[position] != rune('%') {
+							goto l336
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l336
+						}
+						depth--
+						add(ruleModulus, position337)
+					}
+					goto l329
+				l336:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position339 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l338
+						}
+						if buffer[position] != rune('+') {
+							goto l338
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l338
+						}
+						depth--
+						add(ruleAdd, position339)
+					}
+					goto l329
+				l338:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position341 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l340
+						}
+						if buffer[position] != rune('-') {
+							goto l340
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l340
+						}
+						depth--
+						add(ruleSubtract, position341)
+					}
+					goto l329
+				l340:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position343 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l342
+						}
+						if buffer[position] != rune('&') {
+							goto l342
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l342
+						}
+						depth--
+						add(ruleBitwiseAnd, position343)
+					}
+					goto l329
+				l342:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position345 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l344
+						}
+						if buffer[position] != rune('|') {
+							goto l344
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l344
+						}
+						depth--
+						add(ruleBitwiseOr, position345)
+					}
+					goto l329
+				l344:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position347 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l346
+						}
+						if buffer[position] != rune('~') {
+							goto l346
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l346
+						}
+						depth--
+						add(ruleBitwiseNot, position347)
+					}
+					goto l329
+				l346:
+					position, tokenIndex, depth = position329, tokenIndex329, depth329
+					{
+						position348 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l327
+						}
+						if buffer[position] != rune('^') {
+							goto l327
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l327
+						}
+						depth--
+						add(ruleBitwiseXor, position348)
+					}
+				}
+			l329:
+				if !_rules[rule_]() {
+					goto l327
+				}
+				depth--
+				add(ruleOperator, position328)
+			}
+			return true
+		l327:
+			position, tokenIndex, depth = position327, tokenIndex327, depth327
+			return false
+		},
+		/* 80 AssignmentOperator <- <(_ (AssignEq / StarEq / DivEq / PlusEq / MinusEq / AndEq / OrEq / Append) _)> */
+		nil,
+		/* 81 AssignEq <- <(_ '=' _)> */
+		nil,
+		/* 82 StarEq <- <(_ ('*' '=') _)> */
+		nil,
+		/* 83 DivEq <- <(_ ('/' '=') _)> */
+		nil,
+		/* 84 PlusEq <- <(_ ('+' '=') _)> */
+		nil,
+		/* 85 MinusEq <- <(_ ('-' '=') _)> */
+		nil,
+		/* 86 AndEq <- <(_ ('&' '=') _)> */
+		nil,
+		/* 87 OrEq <- <(_ ('|' '=') _)> */
+		nil,
+		/* 88 Append <- <(_ ('<' '<') _)> */
+		nil,
+		/* 89 ComparisonOperator <- <(_ (Equality / NonEquality / GreaterEqual / LessEqual / GreaterThan / LessThan / Membership / NonMembership) _)> */
+		func() bool {
+			position358, tokenIndex358, depth358 := position, tokenIndex, depth
+			{
+				position359 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l358
+				}
+				{
+					position360, tokenIndex360, depth360 := position, tokenIndex, depth
+					{
+						position362 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l361
+						}
+						if buffer[position] != rune('=') {
+							goto l361
+						}
+						position++
+						if buffer[position] != rune('=') {
+							goto l361
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l361
+						}
+						depth--
+						add(ruleEquality, position362)
+					}
+					goto l360
+				l361:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position364 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l363
+						}
+						if buffer[position] != rune('!') {
+							goto l363
+						}
+						position++
+						if buffer[position] != rune('=') {
+							goto l363
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l363
+						}
+						depth--
+						add(ruleNonEquality, position364)
+					}
+					goto l360
+				l363:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position366 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l365
+						}
+						if buffer[position] != rune('>') {
+							goto l365
+						}
+						position++
+						if buffer[position] != rune('=') {
+							goto l365
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l365
+						}
+						depth--
+						add(ruleGreaterEqual, position366)
+					}
+					goto l360
+				l365:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position368 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l367
+						}
+						if buffer[position] != rune('<') {
+							goto l367
+						}
+						position++
+						if buffer[position] != rune('=') {
+							goto l367
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l367
+						}
+						depth--
+						add(ruleLessEqual, position368)
+					}
+					goto l360
+				l367:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position370 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l369
+						}
+						if buffer[position] != rune('>') {
+							goto l369
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l369
+						}
+						depth--
+						add(ruleGreaterThan, position370)
+					}
+					goto l360
+				l369:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position372 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l371
+						}
+						if buffer[position] != rune('<') {
+							goto l371
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l371
+						}
+						depth--
+						add(ruleLessThan, position372)
+					}
+					goto l360
+				l371:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position374 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l373
+						}
+						if buffer[position] != rune('i') {
+							goto l373
+						}
+						position++
+						if buffer[position] != rune('n') {
+							goto l373
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l373
+						}
+						depth--
+						add(ruleMembership, position374)
+					}
+					goto l360
+				l373:
+					position, tokenIndex, depth = position360, tokenIndex360, depth360
+					{
+						position375 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l358
+						}
+						if buffer[position] != rune('n') {
+							goto l358
+						}
+						position++
+						if buffer[position] != rune('o') {
+							goto l358
+						}
+						position++
+						if buffer[position] != rune('t') {
+							goto l358
+						}
+						position++
+						if !_rules[rule__]() {
+							goto l358
+						}
+						if buffer[position] != rune('i') {
+							goto l358
+						}
+						position++
+						if buffer[position] != rune('n') {
+							goto l358
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l358
+						}
+						depth--
+						add(ruleNonMembership, position375)
+					}
+				}
+			l360:
+				if !_rules[rule_]() {
+					goto l358
+				}
+				depth--
+				add(ruleComparisonOperator, position359)
+			}
+			return true
+		l358:
+			position, tokenIndex, depth = position358, tokenIndex358, depth358
+			return false
+		},
+		/* 90 Equality <- <(_ ('=' '=') _)> */
+		nil,
+		/* 91 NonEquality <- <(_ ('!' '=') _)> */
+		nil,
+		/* 92 GreaterThan <- <(_ '>' _)> */
+		nil,
+		/* 93 GreaterEqual <- <(_ ('>' '=') _)> */
+		nil,
+		/* 94 LessEqual <- <(_ ('<' '=') _)> */
+		nil,
+		/* 95 LessThan <- <(_ '<' _)> */
+		nil,
+		/* 96 Membership <- <(_ ('i' 'n') _)> */
+		nil,
+		/* 97 NonMembership <- <(_ ('n' 'o' 't') __ ('i' 'n') _)> */
+		nil,
+		/* 98 Variable <- <(('$' VariableNameSequence) / SKIPVAR)> */
+		func() bool {
+			position384, tokenIndex384, depth384 := position, tokenIndex, depth
+			{
+				position385 := position
+				depth++
+				{
+					position386, tokenIndex386, depth386 := position, tokenIndex, depth
+					if buffer[position] != rune('$') {
+						goto l387
+					}
+					position++
+					{
+						position388 := position
+						depth++
+					l389:
+						{
+							position390, tokenIndex390, depth390 := position, tokenIndex, depth
+							if !_rules[ruleVariableName]() {
+								goto l390
+							}
+							if !_rules[ruleDOT]() {
+								goto l390
+							}
+							goto l389
+						l390:
+							position, tokenIndex, depth = position390, tokenIndex390, depth390
+						}
+						if !_rules[ruleVariableName]() {
+							goto l387
+						}
+						depth--
+						add(ruleVariableNameSequence, position388)
+					}
+					goto l386
+				l387:
+					position, tokenIndex, depth = position386, tokenIndex386, depth386
+					{
+						position391 := position
+						depth++
+						if !_rules[rule_]() {
+							goto l384
+						}
+						if buffer[position] != rune('_') {
+							goto l384
+						}
+						position++
+						if !_rules[rule_]() {
+							goto l384
+						}
+						depth--
+						add(ruleSKIPVAR, position391)
+					}
+				}
+			l386:
+				depth--
+				add(ruleVariable, position385)
+			}
+			return true
+		l384:
+			position, tokenIndex, depth = position384, tokenIndex384, depth384
+			return false
+		},
+		/* 99 VariableNameSequence <- <((VariableName DOT)* VariableName)> */
+		nil,
+		/* 100 VariableName <- <(Identifier ('[' _ VariableIndex _ ']')*)> */
+		func() bool {
+			position393, tokenIndex393, depth393 := position, tokenIndex, depth
+			{
+				position394 := position
+				depth++
+				if !_rules[ruleIdentifier]() {
+					goto l393
+				}
+			l395:
+				{
+					position396, tokenIndex396, depth396 := position, tokenIndex, depth
+					if buffer[position] != rune('[') {
+						goto l396
+					}
+					position++
+					if !_rules[rule_]() {
+						goto l396
+					}
+					{
+						position397 := position
+						depth++
+						{
+							position398, tokenIndex398, depth398 := position, tokenIndex, depth
+							{
+								position400 := position
+								depth++
+								{
+									position401, tokenIndex401, depth401 := position, tokenIndex, depth
+									{
+										position403 := position
+										depth++
+										if !_rules[ruleExpression]() {
+											goto l401
+										}
+										depth--
+										add(ruleVariableSliceStart, position403)
+									}
+									goto l402
+								l401:
+									position, tokenIndex, depth = position401, tokenIndex401, depth401
+								}
+							l402:
+								if !_rules[ruleCOLON]() {
+									goto l399
+								}
+								{
+									position404, tokenIndex404, depth404 := position, tokenIndex, depth
+									{
+										position406 := position
+										depth++
+										if !_rules[ruleExpression]() {
+											goto l404
+										}
+										depth--
+										add(ruleVariableSliceEnd, position406)
+									}
+									goto l405
+								l404:
+									position, tokenIndex, depth = position404, tokenIndex404, depth404
+								}
+							l405:
+								depth--
+								add(ruleVariableSlice, position400)
+							}
+							goto l398
+						l399:
+							position, tokenIndex, depth = position398, tokenIndex398, depth398
+							{
+								position408 := position
+								depth++
+								if buffer[position] != rune('*') {
+									goto l407
+								}
+								position++
+								depth--
+								add(ruleVariableWildcard, position408)
+							}
+							goto l398
+						l407:
+							position, tokenIndex, depth = position398, tokenIndex398, depth398
+							{
+								position410 := position
+								depth++
+								if buffer[position] != rune('?') {
+									goto l409
+								}
+								position++
+								if !_rules[rule_]() {
+									goto l409
+								}
+								{
+									position411 := position
+									depth++
+									{
+										position412, tokenIndex412, depth412 := position, tokenIndex, depth
+										if buffer[position] != rune('@') {
+											goto l413
+										}
+										position++
+										goto l412
+									l413:
+										position, tokenIndex, depth = position412, tokenIndex412, depth412
+										if !_rules[ruleIdentifier]() {
+											goto l409
+										}
+									}
+								l412:
+								l414:
+									{
+										position415, tokenIndex415, depth415 := position, tokenIndex, depth
+										if !_rules[ruleDOT]() {
+											goto l415
+										}
+										if !_rules[ruleIdentifier]() {
+											goto l415
+										}
+										goto l414
+									l415:
+										position, tokenIndex, depth = position415, tokenIndex415, depth415
+									}
+									depth--
+									add(ruleFilterField, position411)
+								}
+								{
+									position416, tokenIndex416, depth416 := position, tokenIndex, depth
+									{
+										position418, tokenIndex418, depth418 := position, tokenIndex, depth
+										if !_rules[ruleMatchOperator]() {
+											goto l419
+										}
+										if !_rules[ruleRegularExpression]() {
+											goto l419
+										}
+										goto l418
+									l419:
+										position, tokenIndex, depth = position418, tokenIndex418, depth418
+										if !_rules[ruleComparisonOperator]() {
+											goto l416
+										}
+										if !_rules[ruleExpression]() {
+											goto l416
+										}
+									}
+								l418:
+									goto l417
+								l416:
+									position, tokenIndex, depth = position416, tokenIndex416, depth416
+								}
+							l417:
+								depth--
+								add(ruleVariableFilter, position410)
+							}
+							goto l398
+						l409:
+							position, tokenIndex, depth = position398, tokenIndex398, depth398
+							if !_rules[ruleExpression]() {
+								goto l396
+							}
+						}
+					l398:
+						depth--
+						add(ruleVariableIndex, position397)
+					}
+					if !_rules[rule_]() {
+						goto l396
+					}
+					if buffer[position] != rune(']') {
+						goto l396
+					}
+					position++
+					goto l395
+				l396:
+					position, tokenIndex, depth = position396, tokenIndex396, depth396
+				}
+				depth--
+				add(ruleVariableName, position394)
+			}
+			return true
+		l393:
+			position, tokenIndex, depth = position393, tokenIndex393, depth393
+			return false
+		},
+		/* 101 VariableIndex <- <(VariableSlice / VariableWildcard / VariableFilter / Expression)> */
+		nil,
+		/* 102 VariableSlice <- <(VariableSliceStart? COLON VariableSliceEnd?)> */
+		nil,
+		/* 103 VariableSliceStart <- <Expression> */
+		nil,
+		/* 104 VariableSliceEnd <- <Expression> */
+		nil,
+		/* 105 VariableWildcard <- <'*'> */
+		nil,
+		/* 106 VariableFilter <- <('?' _ FilterField ((MatchOperator RegularExpression) / (ComparisonOperator Expression))?)> */
+		nil,
+		/* 107 FilterField <- <(('@' / Identifier) (DOT Identifier)*)> */
+		nil,
+		/* 108 Block <- <(_ (COMMENT / FlowControlWord / StatementBlock) SEMI? _)> */
+		func() bool {
+			position427, tokenIndex427, depth427 := position, tokenIndex, depth
+			{
+				position428 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l427
+				}
+				{
+					position429, tokenIndex429, depth429 := position, tokenIndex, depth
+					if !_rules[ruleCOMMENT]() {
+						goto l430
+					}
+					goto l429
+				l430:
+					position, tokenIndex, depth = position429, tokenIndex429, depth429
+					{
+						position432 := position
+						depth++
+						{
+							position433, tokenIndex433, depth433 := position, tokenIndex, depth
+							{
+								position435 := position
+								depth++
+								{
+									position436 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l434
+									}
+									if buffer[position] != rune('b') {
+										goto l434
+									}
+									position++
+									if buffer[position] != rune('r') {
+										goto l434
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l434
+									}
+									position++
+									if buffer[position] != rune('a') {
+										goto l434
+									}
+									position++
+									if buffer[position] != rune('k') {
+										goto l434
+									}
+									position++
+									if !_rules[rule_]() {
+										goto l434
+									}
+									depth--
+									add(ruleBREAK, position436)
+								}
+								{
+									position437, tokenIndex437, depth437 := position, tokenIndex, depth
+									if !_rules[rulePositiveInteger]() {
+										goto l437
+									}
+									goto l438
+								l437:
+									position, tokenIndex, depth = position437, tokenIndex437, depth437
+								}
+							l438:
+								depth--
+								add(ruleFlowControlBreak, position435)
+							}
+							goto l433
+						l434:
+							position, tokenIndex, depth = position433, tokenIndex433, depth433
+							{
+								position440 := position
+								depth++
+								{
+									position441 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l439
+									}
+									if buffer[position] != rune('c') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('o') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('n') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('t') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('i') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('n') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('u') {
+										goto l439
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l439
+									}
+									position++
+									if !_rules[rule_]() {
+										goto l439
+									}
+									depth--
+									add(ruleCONT, position441)
+								}
+								{
+									position442, tokenIndex442, depth442 := position, tokenIndex, depth
+									if !_rules[rulePositiveInteger]() {
+										goto l442
+									}
+									goto l443
+								l442:
+									position, tokenIndex, depth = position442, tokenIndex442, depth442
+								}
+							l443:
+								depth--
+								add(ruleFlowControlContinue, position440)
+							}
+							goto l433
+						l439:
+							position, tokenIndex, depth = position433, tokenIndex433, depth433
+							{
+								position445 := position
+								depth++
+								{
+									position446 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l444
+									}
+									if buffer[position] != rune('r') {
+										goto l444
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l444
+									}
+									position++
+									if buffer[position] != rune('t') {
+										goto l444
+									}
+									position++
+									if buffer[position] != rune('u') {
+										goto l444
+									}
+									position++
+									if buffer[position] != rune('r') {
+										goto l444
+									}
+									position++
+									if buffer[position] != rune('n') {
+										goto l444
+									}
+									position++
+									{
+										position447, tokenIndex447, depth447 := position, tokenIndex, depth
+										{
+											position448, tokenIndex448, depth448 := position, tokenIndex, depth
+											if c := buffer[position]; c < rune('a') || c > rune('z') {
+												goto l449
+											}
+											position++
+											goto l448
+										l449:
+											position, tokenIndex, depth = position448, tokenIndex448, depth448
+											if c := buffer[position]; c < rune('A') || c > rune('Z') {
+												goto l450
+											}
+											position++
+											goto l448
+										l450:
+											position, tokenIndex, depth = position448, tokenIndex448, depth448
+											if c := buffer[position]; c < rune('0') || c > rune('9') {
+												goto l451
+											}
+											position++
+											goto l448
+										l451:
+											position, tokenIndex, depth = position448, tokenIndex448, depth448
+											if buffer[position] != rune('_') {
+												goto l447
+											}
+											position++
+										}
+									l448:
+										goto l444
+									l447:
+										position, tokenIndex, depth = position447, tokenIndex447, depth447
+									}
+									depth--
+									add(ruleRETURN, position446)
+								}
+								{
+									position452, tokenIndex452, depth452 := position, tokenIndex, depth
+									{
+										position456, tokenIndex456, depth456 := position, tokenIndex, depth
+										if buffer[position] != rune(' ') {
+											goto l457
+										}
+										position++
+										goto l456
+									l457:
+										position, tokenIndex, depth = position456, tokenIndex456, depth456
+										if buffer[position] != rune('\t') {
+											goto l452
+										}
+										position++
+									}
+								l456:
+								l454:
+									{
+										position455, tokenIndex455, depth455 := position, tokenIndex, depth
+										{
+											position458, tokenIndex458, depth458 := position, tokenIndex, depth
+											if buffer[position] != rune(' ') {
+												goto l459
+											}
+											position++
+											goto l458
+										l459:
+											position, tokenIndex, depth = position458, tokenIndex458, depth458
+											if buffer[position] != rune('\t') {
+												goto l455
+											}
+											position++
+										}
+									l458:
+										goto l454
+									l455:
+										position, tokenIndex, depth = position455, tokenIndex455, depth455
+									}
+									{
+										position460, tokenIndex460, depth460 := position, tokenIndex, depth
+										{
+											position461, tokenIndex461, depth461 := position, tokenIndex, depth
+											if buffer[position] != rune('\r') {
+												goto l462
+											}
+											position++
+											goto l461
+										l462:
+											position, tokenIndex, depth = position461, tokenIndex461, depth461
+											if buffer[position] != rune('\n') {
+												goto l463
+											}
+											position++
+											goto l461
+										l463:
+											position, tokenIndex, depth = position461, tokenIndex461, depth461
+											if buffer[position] != rune('#') {
+												goto l464
+											}
+											position++
+											goto l461
+										l464:
+											position, tokenIndex, depth = position461, tokenIndex461, depth461
+											if buffer[position] != rune(';') {
+												goto l465
+											}
+											position++
+											goto l461
+										l465:
+											position, tokenIndex, depth = position461, tokenIndex461, depth461
+											if buffer[position] != rune('}') {
+												goto l460
+											}
+											position++
+										}
+									l461:
+										goto l452
+									l460:
+										position, tokenIndex, depth = position460, tokenIndex460, depth460
+									}
+									if !_rules[ruleExpression]() {
+										goto l452
+									}
+									goto l453
+								l452:
+									position, tokenIndex, depth = position452, tokenIndex452, depth452
+								}
+							l453:
+								depth--
+								add(ruleFlowControlReturn, position445)
+							}
+							goto l433
+						l444:
+							position, tokenIndex, depth = position433, tokenIndex433, depth433
+							{
+								position466 := position
+								depth++
+								{
+									position467 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l431
+									}
+									if buffer[position] != rune('e') {
+										goto l431
+									}
+									position++
+									if buffer[position] != rune('x') {
+										goto l431
+									}
+									position++
+									if buffer[position] != rune('i') {
+										goto l431
+									}
+									position++
+									if buffer[position] != rune('t') {
+										goto l431
+									}
+									position++
+									{
+										position468, tokenIndex468, depth468 := position, tokenIndex, depth
+										{
+											position469, tokenIndex469, depth469 := position, tokenIndex, depth
+											if c := buffer[position]; c < rune('a') || c > rune('z') {
+												goto l470
+											}
+											position++
+											goto l469
+										l470:
+											position, tokenIndex, depth = position469, tokenIndex469, depth469
+											if c := buffer[position]; c < rune('A') || c > rune('Z') {
+												goto l471
+											}
+											position++
+											goto l469
+										l471:
+											position, tokenIndex, depth = position469, tokenIndex469, depth469
+											if c := buffer[position]; c < rune('0') || c > rune('9') {
+												goto l472
+											}
+											position++
+											goto l469
+										l472:
+											position, tokenIndex, depth = position469, tokenIndex469, depth469
+											if buffer[position] != rune('_') {
+												goto l468
+											}
+											position++
+										}
+									l469:
+										goto l431
+									l468:
+										position, tokenIndex, depth = position468, tokenIndex468, depth468
+									}
+									depth--
+									add(ruleEXIT, position467)
+								}
+								{
+									position473, tokenIndex473, depth473 := position, tokenIndex, depth
+									{
+										position477, tokenIndex477, depth477 := position, tokenIndex, depth
+										if buffer[position] != rune(' ') {
+											goto l478
+										}
+										position++
+										goto l477
+									l478:
+										position, tokenIndex, depth = position477, tokenIndex477, depth477
+										if buffer[position] != rune('\t') {
+											goto l473
+										}
+										position++
+									}
+								l477:
+								l475:
+									{
+										position476, tokenIndex476, depth476 := position, tokenIndex, depth
+										{
+											position479, tokenIndex479, depth479 := position, tokenIndex, depth
+											if buffer[position] != rune(' ') {
+												goto l480
+											}
+											position++
+											goto l479
+										l480:
+											position, tokenIndex, depth = position479, tokenIndex479, depth479
+											if buffer[position] != rune('\t') {
+												goto l476
+											}
+											position++
+										}
+									l479:
+										goto l475
+									l476:
+										position, tokenIndex, depth = position476, tokenIndex476, depth476
+									}
+									{
+										position481, tokenIndex481, depth481 := position, tokenIndex, depth
+										{
+											position482, tokenIndex482, depth482 := position, tokenIndex, depth
+											if buffer[position] != rune('\r') {
+												goto l483
+											}
+											position++
+											goto l482
+										l483:
+											position, tokenIndex, depth = position482, tokenIndex482, depth482
+											if buffer[position] != rune('\n') {
+												goto l484
+											}
+											position++
+											goto l482
+										l484:
+											position, tokenIndex, depth = position482, tokenIndex482, depth482
+											if buffer[position] != rune('#') {
+												goto l485
+											}
+											position++
+											goto l482
+										l485:
+											position, tokenIndex, depth = position482, tokenIndex482, depth482
+											if buffer[position] != rune(';') {
+												goto l486
+											}
+											position++
+											goto l482
+										l486:
+											position, tokenIndex, depth = position482, tokenIndex482, depth482
+											if buffer[position] != rune('}') {
+												goto l481
+											}
+											position++
+										}
+									l482:
+										goto l473
+									l481:
+										position, tokenIndex, depth = position481, tokenIndex481, depth481
+									}
+									if !_rules[ruleExpression]() {
+										goto l473
+									}
+									goto l474
+								l473:
+									position, tokenIndex, depth = position473, tokenIndex473, depth473
+								}
+							l474:
+								depth--
+								add(ruleFlowControlExit, position466)
+							}
+						}
+					l433:
+						depth--
+						add(ruleFlowControlWord, position432)
+					}
+					goto l429
+				l431:
+					position, tokenIndex, depth = position429, tokenIndex429, depth429
+					{
+						position487 := position
+						depth++
+						{
+							position488, tokenIndex488, depth488 := position, tokenIndex, depth
+							{
+								position490 := position
+								depth++
+								if !_rules[ruleSEMI]() {
+									goto l489
+								}
+								depth--
+								add(ruleNOOP, position490)
+							}
+							goto l488
+						l489:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position492 := position
+								depth++
+								{
+									position493 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l491
+									}
+									if buffer[position] != rune('u') {
+										goto l491
+									}
+									position++
+									if buffer[position] != rune('s') {
+										goto l491
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l491
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l491
+									}
+									depth--
+									add(ruleUSE, position493)
+								}
+								{
+									position494 := position
+									depth++
+								l495:
+									{
+										position496, tokenIndex496, depth496 := position, tokenIndex, depth
+										if !_rules[ruleIdentifier]() {
+											goto l496
+										}
+										if !_rules[ruleSCOPE]() {
+											goto l496
+										}
+										goto l495
+									l496:
+										position, tokenIndex, depth = position496, tokenIndex496, depth496
+									}
+									if !_rules[ruleIdentifier]() {
+										goto l491
+									}
+									depth--
+									add(ruleUseModule, position494)
+								}
+								{
+									position497, tokenIndex497, depth497 := position, tokenIndex, depth
+									if !_rules[rule__]() {
+										goto l497
+									}
+									if buffer[position] != rune('a') {
+										goto l497
+									}
+									position++
+									if buffer[position] != rune('s') {
+										goto l497
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l497
+									}
+									{
+										position499 := position
+										depth++
+										if !_rules[ruleIdentifier]() {
+											goto l497
+										}
+										depth--
+										add(ruleUseAlias, position499)
+									}
+									goto l498
+								l497:
+									position, tokenIndex, depth = position497, tokenIndex497, depth497
+								}
+							l498:
+								depth--
+								add(ruleUse, position492)
+							}
+							goto l488
+						l491:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position501 := position
+								depth++
+								{
+									position502 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l500
+									}
+									if buffer[position] != rune('p') {
+										goto l500
+									}
+									position++
+									if buffer[position] != rune('a') {
+										goto l500
+									}
+									position++
+									if buffer[position] != rune('r') {
+										goto l500
+									}
+									position++
+									if buffer[position] != rune('a') {
+										goto l500
+									}
+									position++
+									if buffer[position] != rune('m') {
+										goto l500
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l500
+									}
+									depth--
+									add(rulePARAM, position502)
+								}
+								if !_rules[ruleVariable]() {
+									goto l500
+								}
+								{
+									position503, tokenIndex503, depth503 := position, tokenIndex, depth
+									if !_rules[ruleCOLON]() {
+										goto l503
+									}
+									{
+										position505 := position
+										depth++
+										if !_rules[ruleIdentifier]() {
+											goto l503
+										}
+										depth--
+										add(ruleParamType, position505)
+									}
+									goto l504
+								l503:
+									position, tokenIndex, depth = position503, tokenIndex503, depth503
+								}
+							l504:
+								{
+									position506, tokenIndex506, depth506 := position, tokenIndex, depth
+									{
+										position508, tokenIndex508, depth508 := position, tokenIndex, depth
+										if !_rules[rule__]() {
+											goto l509
+										}
+										{
+											position510 := position
+											depth++
+											if buffer[position] != rune('r') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('e') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('q') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('u') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('i') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('r') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('e') {
+												goto l509
+											}
+											position++
+											if buffer[position] != rune('d') {
+												goto l509
+											}
+											position++
+											{
+												position511, tokenIndex511, depth511 := position, tokenIndex, depth
+												{
+													position512, tokenIndex512, depth512 := position, tokenIndex, depth
+													if c := buffer[position]; c < rune('a') || c > rune('z') {
+														goto l513
+													}
+													position++
+													goto l512
+												l513:
+													position, tokenIndex, depth = position512, tokenIndex512, depth512
+													if c := buffer[position]; c < rune('A') || c > rune('Z') {
+														goto l514
+													}
+													position++
+													goto l512
+												l514:
+													position, tokenIndex, depth = position512, tokenIndex512, depth512
+													if c := buffer[position]; c < rune('0') || c > rune('9') {
+														goto l515
+													}
+													position++
+													goto l512
+												l515:
+													position, tokenIndex, depth = position512, tokenIndex512, depth512
+													if buffer[position] != rune('_') {
+														goto l511
+													}
+													position++
+												}
+											l512:
+												goto l509
+											l511:
+												position, tokenIndex, depth = position511, tokenIndex511, depth511
+											}
+											depth--
+											add(ruleParamRequired, position510)
+										}
+										goto l508
+									l509:
+										position, tokenIndex, depth = position508, tokenIndex508, depth508
+										{
+											position516 := position
+											depth++
+											if !_rules[rule_]() {
+												goto l506
+											}
+											if buffer[position] != rune('=') {
+												goto l506
+											}
+											position++
+											{
+												position517, tokenIndex517, depth517 := position, tokenIndex, depth
+												{
+													position518, tokenIndex518, depth518 := position, tokenIndex, depth
+													if buffer[position] != rune('=') {
+														goto l519
+													}
+													position++
+													goto l518
+												l519:
+													position, tokenIndex, depth = position518, tokenIndex518, depth518
+													if buffer[position] != rune('~') {
+														goto l517
+													}
+													position++
+												}
+											l518:
+												goto l506
+											l517:
+												position, tokenIndex, depth = position517, tokenIndex517, depth517
+											}
+											if !_rules[rule_]() {
+												goto l506
+											}
+											if !_rules[ruleExpression]() {
+												goto l506
+											}
+											depth--
+											add(ruleParamDefault, position516)
+										}
 									}
-									goto l229
-								l228:
-									position, tokenIndex, depth = position228, tokenIndex228, depth228
+								l508:
+									goto l507
+								l506:
+									position, tokenIndex, depth = position506, tokenIndex506, depth506
 								}
-							l229:
+							l507:
 								depth--
-								add(ruleFlowControlBreak, position226)
+								add(ruleParam, position501)
 							}
-							goto l224
-						l225:
-							position, tokenIndex, depth = position224, tokenIndex224, depth224
+							goto l488
+						l500:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							{
-								position230 := position
+								position521 := position
 								depth++
 								{
-									position231 := position
+									position522 := position
 									depth++
 									if !_rules[rule_]() {
-										goto l222
+										goto l520
 									}
 									if buffer[position] != rune('c') {
-										goto l222
+										goto l520
 									}
 									position++
 									if buffer[position] != rune('o') {
-										goto l222
-									}
-									position++
-									if buffer[position] != rune('n') {
-										goto l222
-									}
-									position++
-									if buffer[position] != rune('t') {
-										goto l222
-									}
-									position++
-									if buffer[position] != rune('i') {
-										goto l222
+										goto l520
 									}
 									position++
 									if buffer[position] != rune('n') {
-										goto l222
+										goto l520
 									}
 									position++
-									if buffer[position] != rune('u') {
-										goto l222
+									if buffer[position] != rune('s') {
+										goto l520
 									}
 									position++
-									if buffer[position] != rune('e') {
-										goto l222
+									if buffer[position] != rune('t') {
+										goto l520
 									}
 									position++
-									if !_rules[rule_]() {
-										goto l222
+									if !_rules[rule__]() {
+										goto l520
 									}
 									depth--
-									add(ruleCONT, position231)
+									add(ruleCONST, position522)
 								}
-								{
-									position232, tokenIndex232, depth232 := position, tokenIndex, depth
-									if !_rules[rulePositiveInteger]() {
-										goto l232
-									}
-									goto l233
-								l232:
-									position, tokenIndex, depth = position232, tokenIndex232, depth232
-								}
-							l233:
-								depth--
-								add(ruleFlowControlContinue, position230)
-							}
-						}
-					l224:
-						depth--
-						add(ruleFlowControlWord, position223)
-					}
-					goto l216
-				l222:
-					position, tokenIndex, depth = position216, tokenIndex216, depth216
-					{
-						position234 := position
-						depth++
-						{
-							position235, tokenIndex235, depth235 := position, tokenIndex, depth
-							{
-								position237 := position
-								depth++
-								if !_rules[ruleSEMI]() {
-									goto l236
+								if !_rules[ruleAssignment]() {
+									goto l520
 								}
 								depth--
-								add(ruleNOOP, position237)
+								add(ruleConstant, position521)
 							}
-							goto l235
-						l236:
-							position, tokenIndex, depth = position235, tokenIndex235, depth235
+							goto l488
+						l520:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							if !_rules[ruleAssignment]() {
-								goto l238
+								goto l523
 							}
-							goto l235
-						l238:
-							position, tokenIndex, depth = position235, tokenIndex235, depth235
+							goto l488
+						l523:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							{
-								position240 := position
+								position525 := position
 								depth++
 								{
-									position241, tokenIndex241, depth241 := position, tokenIndex, depth
+									position526, tokenIndex526, depth526 := position, tokenIndex, depth
 									{
-										position243 := position
+										position528 := position
 										depth++
 										{
-											position244 := position
+											position529 := position
 											depth++
 											if !_rules[rule_]() {
-												goto l242
+												goto l527
 											}
 											if buffer[position] != rune('u') {
-												goto l242
+												goto l527
 											}
 											position++
 											if buffer[position] != rune('n') {
-												goto l242
+												goto l527
 											}
 											position++
 											if buffer[position] != rune('s') {
-												goto l242
+												goto l527
 											}
 											position++
 											if buffer[position] != rune('e') {
-												goto l242
+												goto l527
 											}
 											position++
 											if buffer[position] != rune('t') {
-												goto l242
+												goto l527
 											}
 											position++
 											if !_rules[rule__]() {
-												goto l242
+												goto l527
 											}
 											depth--
-											add(ruleUNSET, position244)
+											add(ruleUNSET, position529)
 										}
 										if !_rules[ruleVariableSequence]() {
-											goto l242
+											goto l527
 										}
 										depth--
-										add(ruleDirectiveUnset, position243)
+										add(ruleDirectiveUnset, position528)
 									}
-									goto l241
-								l242:
-									position, tokenIndex, depth = position241, tokenIndex241, depth241
+									goto l526
+								l527:
+									position, tokenIndex, depth = position526, tokenIndex526, depth526
 									{
-										position246 := position
+										position531 := position
 										depth++
 										{
-											position247 := position
+											position532 := position
 											depth++
 											if !_rules[rule_]() {
-												goto l245
+												goto l530
 											}
 											if buffer[position] != rune('i') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('n') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('c') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('l') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('u') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('d') {
-												goto l245
+												goto l530
 											}
 											position++
 											if buffer[position] != rune('e') {
-												goto l245
+												goto l530
 											}
 											position++
 											if !_rules[rule__]() {
-												goto l245
+												goto l530
 											}
 											depth--
-											add(ruleINCLUDE, position247)
+											add(ruleINCLUDE, position532)
 										}
 										if !_rules[ruleString]() {
-											goto l245
+											goto l530
 										}
 										depth--
-										add(ruleDirectiveInclude, position246)
+										add(ruleDirectiveInclude, position531)
 									}
-									goto l241
-								l245:
-									position, tokenIndex, depth = position241, tokenIndex241, depth241
+									goto l526
+								l530:
+									position, tokenIndex, depth = position526, tokenIndex526, depth526
 									{
-										position248 := position
+										position533 := position
 										depth++
 										{
-											position249 := position
+											position534 := position
 											depth++
 											if !_rules[rule_]() {
-												goto l239
+												goto l524
 											}
 											if buffer[position] != rune('d') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('e') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('c') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('l') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('a') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('r') {
-												goto l239
+												goto l524
 											}
 											position++
 											if buffer[position] != rune('e') {
-												goto l239
+												goto l524
 											}
 											position++
 											if !_rules[rule__]() {
-												goto l239
+												goto l524
 											}
 											depth--
-											add(ruleDECLARE, position249)
+											add(ruleDECLARE, position534)
 										}
 										if !_rules[ruleVariableSequence]() {
-											goto l239
+											goto l524
 										}
 										depth--
-										add(ruleDirectiveDeclare, position248)
+										add(ruleDirectiveDeclare, position533)
 									}
 								}
-							l241:
+							l526:
 								depth--
-								add(ruleDirective, position240)
+								add(ruleDirective, position525)
 							}
-							goto l235
-						l239:
-							position, tokenIndex, depth = position235, tokenIndex235, depth235
+							goto l488
+						l524:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							{
-								position251 := position
+								position536 := position
 								depth++
 								if !_rules[ruleIfStanza]() {
-									goto l250
+									goto l535
 								}
-							l252:
+							l537:
 								{
-									position253, tokenIndex253, depth253 := position, tokenIndex, depth
+									position538, tokenIndex538, depth538 := position, tokenIndex, depth
 									{
-										position254 := position
+										position539 := position
 										depth++
 										if !_rules[ruleELSE]() {
-											goto l253
+											goto l538
 										}
 										if !_rules[ruleIfStanza]() {
-											goto l253
+											goto l538
 										}
 										depth--
-										add(ruleElseIfStanza, position254)
+										add(ruleElseIfStanza, position539)
 									}
-									goto l252
-								l253:
-									position, tokenIndex, depth = position253, tokenIndex253, depth253
+									goto l537
+								l538:
+									position, tokenIndex, depth = position538, tokenIndex538, depth538
 								}
 								{
-									position255, tokenIndex255, depth255 := position, tokenIndex, depth
+									position540, tokenIndex540, depth540 := position, tokenIndex, depth
 									{
-										position257 := position
+										position542 := position
 										depth++
 										if !_rules[ruleELSE]() {
-											goto l255
+											goto l540
 										}
 										if !_rules[ruleOPEN]() {
-											goto l255
+											goto l540
 										}
-									l258:
+									l543:
 										{
-											position259, tokenIndex259, depth259 := position, tokenIndex, depth
+											position544, tokenIndex544, depth544 := position, tokenIndex, depth
 											if !_rules[ruleBlock]() {
-												goto l259
+												goto l544
 											}
-											goto l258
-										l259:
-											position, tokenIndex, depth = position259, tokenIndex259, depth259
+											goto l543
+										l544:
+											position, tokenIndex, depth = position544, tokenIndex544, depth544
 										}
 										if !_rules[ruleCLOSE]() {
-											goto l255
+											goto l540
 										}
 										depth--
-										add(ruleElseStanza, position257)
+										add(ruleElseStanza, position542)
 									}
-									goto l256
-								l255:
-									position, tokenIndex, depth = position255, tokenIndex255, depth255
+									goto l541
+								l540:
+									position, tokenIndex, depth = position540, tokenIndex540, depth540
 								}
-							l256:
+							l541:
 								depth--
-								add(ruleConditional, position251)
+								add(ruleConditional, position536)
 							}
-							goto l235
-						l250:
-							position, tokenIndex, depth = position235, tokenIndex235, depth235
+							goto l488
+						l535:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							{
-								position261 := position
+								position546 := position
 								depth++
 								{
-									position262 := position
+									position547 := position
 									depth++
 									if !_rules[rule_]() {
-										goto l260
+										goto l545
+									}
+									if buffer[position] != rune('s') {
+										goto l545
+									}
+									position++
+									if buffer[position] != rune('w') {
+										goto l545
+									}
+									position++
+									if buffer[position] != rune('i') {
+										goto l545
+									}
+									position++
+									if buffer[position] != rune('t') {
+										goto l545
+									}
+									position++
+									if buffer[position] != rune('c') {
+										goto l545
+									}
+									position++
+									if buffer[position] != rune('h') {
+										goto l545
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l545
+									}
+									depth--
+									add(ruleSWITCH, position547)
+								}
+								if !_rules[ruleExpression]() {
+									goto l545
+								}
+								if !_rules[ruleOPEN]() {
+									goto l545
+								}
+							l548:
+								{
+									position549, tokenIndex549, depth549 := position, tokenIndex, depth
+									if !_rules[ruleCOMMENT]() {
+										goto l549
+									}
+									if !_rules[rule_]() {
+										goto l549
+									}
+									goto l548
+								l549:
+									position, tokenIndex, depth = position549, tokenIndex549, depth549
+								}
+							l550:
+								{
+									position551, tokenIndex551, depth551 := position, tokenIndex, depth
+									{
+										position552 := position
+										depth++
+										{
+											position553 := position
+											depth++
+											if !_rules[rule_]() {
+												goto l551
+											}
+											if buffer[position] != rune('c') {
+												goto l551
+											}
+											position++
+											if buffer[position] != rune('a') {
+												goto l551
+											}
+											position++
+											if buffer[position] != rune('s') {
+												goto l551
+											}
+											position++
+											if buffer[position] != rune('e') {
+												goto l551
+											}
+											position++
+											if !_rules[rule__]() {
+												goto l551
+											}
+											depth--
+											add(ruleCASE, position553)
+										}
+										{
+											position554, tokenIndex554, depth554 := position, tokenIndex, depth
+											{
+												position556 := position
+												depth++
+												if !_rules[ruleRegularExpression]() {
+													goto l555
+												}
+												depth--
+												add(ruleSwitchCaseRegex, position556)
+											}
+											goto l554
+										l555:
+											position, tokenIndex, depth = position554, tokenIndex554, depth554
+											{
+												position558 := position
+												depth++
+												if buffer[position] != rune('i') {
+													goto l557
+												}
+												position++
+												if buffer[position] != rune('n') {
+													goto l557
+												}
+												position++
+												if !_rules[rule__]() {
+													goto l557
+												}
+												if !_rules[ruleExpression]() {
+													goto l557
+												}
+												depth--
+												add(ruleSwitchCaseMembership, position558)
+											}
+											goto l554
+										l557:
+											position, tokenIndex, depth = position554, tokenIndex554, depth554
+											{
+												position559 := position
+												depth++
+												if !_rules[ruleExpressionSequence]() {
+													goto l551
+												}
+												depth--
+												add(ruleSwitchCaseValues, position559)
+											}
+										}
+									l554:
+										if !_rules[ruleOPEN]() {
+											goto l551
+										}
+									l560:
+										{
+											position561, tokenIndex561, depth561 := position, tokenIndex, depth
+											if !_rules[ruleBlock]() {
+												goto l561
+											}
+											goto l560
+										l561:
+											position, tokenIndex, depth = position561, tokenIndex561, depth561
+										}
+										if !_rules[ruleCLOSE]() {
+											goto l551
+										}
+										depth--
+										add(ruleSwitchCase, position552)
+									}
+								l562:
+									{
+										position563, tokenIndex563, depth563 := position, tokenIndex, depth
+										if !_rules[ruleCOMMENT]() {
+											goto l563
+										}
+										if !_rules[rule_]() {
+											goto l563
+										}
+										goto l562
+									l563:
+										position, tokenIndex, depth = position563, tokenIndex563, depth563
+									}
+									goto l550
+								l551:
+									position, tokenIndex, depth = position551, tokenIndex551, depth551
+								}
+								{
+									position564, tokenIndex564, depth564 := position, tokenIndex, depth
+									{
+										position566 := position
+										depth++
+										{
+											position567 := position
+											depth++
+											if !_rules[rule_]() {
+												goto l564
+											}
+											if buffer[position] != rune('d') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('e') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('f') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('a') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('u') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('l') {
+												goto l564
+											}
+											position++
+											if buffer[position] != rune('t') {
+												goto l564
+											}
+											position++
+											if !_rules[rule_]() {
+												goto l564
+											}
+											depth--
+											add(ruleDEFAULT, position567)
+										}
+										if !_rules[ruleOPEN]() {
+											goto l564
+										}
+									l568:
+										{
+											position569, tokenIndex569, depth569 := position, tokenIndex, depth
+											if !_rules[ruleBlock]() {
+												goto l569
+											}
+											goto l568
+										l569:
+											position, tokenIndex, depth = position569, tokenIndex569, depth569
+										}
+										if !_rules[ruleCLOSE]() {
+											goto l564
+										}
+										depth--
+										add(ruleSwitchDefault, position566)
+									}
+								l570:
+									{
+										position571, tokenIndex571, depth571 := position, tokenIndex, depth
+										if !_rules[ruleCOMMENT]() {
+											goto l571
+										}
+										if !_rules[rule_]() {
+											goto l571
+										}
+										goto l570
+									l571:
+										position, tokenIndex, depth = position571, tokenIndex571, depth571
+									}
+									goto l565
+								l564:
+									position, tokenIndex, depth = position564, tokenIndex564, depth564
+								}
+							l565:
+								if !_rules[ruleCLOSE]() {
+									goto l545
+								}
+								depth--
+								add(ruleSwitch, position546)
+							}
+							goto l488
+						l545:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position573 := position
+								depth++
+								{
+									position574 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l572
 									}
 									if buffer[position] != rune('l') {
-										goto l260
+										goto l572
 									}
 									position++
 									if buffer[position] != rune('o') {
-										goto l260
+										goto l572
 									}
 									position++
 									if buffer[position] != rune('o') {
-										goto l260
+										goto l572
 									}
 									position++
 									if buffer[position] != rune('p') {
-										goto l260
+										goto l572
 									}
 									position++
 									if !_rules[rule_]() {
-										goto l260
+										goto l572
 									}
 									depth--
-									add(ruleLOOP, position262)
+									add(ruleLOOP, position574)
 								}
 								{
-									position263, tokenIndex263, depth263 := position, tokenIndex, depth
+									position575, tokenIndex575, depth575 := position, tokenIndex, depth
 									if !_rules[ruleOPEN]() {
-										goto l264
+										goto l576
 									}
-								l265:
+								l577:
 									{
-										position266, tokenIndex266, depth266 := position, tokenIndex, depth
+										position578, tokenIndex578, depth578 := position, tokenIndex, depth
 										if !_rules[ruleBlock]() {
-											goto l266
+											goto l578
 										}
-										goto l265
-									l266:
-										position, tokenIndex, depth = position266, tokenIndex266, depth266
+										goto l577
+									l578:
+										position, tokenIndex, depth = position578, tokenIndex578, depth578
 									}
 									if !_rules[ruleCLOSE]() {
-										goto l264
+										goto l576
 									}
-									goto l263
-								l264:
-									position, tokenIndex, depth = position263, tokenIndex263, depth263
+									goto l575
+								l576:
+									position, tokenIndex, depth = position575, tokenIndex575, depth575
 									{
-										position268 := position
+										position580 := position
 										depth++
 										{
-											position269 := position
+											position581 := position
 											depth++
 											if !_rules[rule_]() {
-												goto l267
+												goto l579
 											}
 											if buffer[position] != rune('c') {
-												goto l267
+												goto l579
 											}
 											position++
 											if buffer[position] != rune('o') {
-												goto l267
+												goto l579
 											}
 											position++
 											if buffer[position] != rune('u') {
-												goto l267
+												goto l579
 											}
 											position++
 											if buffer[position] != rune('n') {
-												goto l267
+												goto l579
 											}
 											position++
 											if buffer[position] != rune('t') {
-												goto l267
+												goto l579
 											}
 											position++
 											if !_rules[rule_]() {
-												goto l267
+												goto l579
 											}
 											depth--
-											add(ruleCOUNT, position269)
+											add(ruleCOUNT, position581)
 										}
 										{
-											position270, tokenIndex270, depth270 := position, tokenIndex, depth
+											position582, tokenIndex582, depth582 := position, tokenIndex, depth
 											if !_rules[ruleInteger]() {
-												goto l271
+												goto l583
 											}
-											goto l270
-										l271:
-											position, tokenIndex, depth = position270, tokenIndex270, depth270
+											goto l582
+										l583:
+											position, tokenIndex, depth = position582, tokenIndex582, depth582
 											if !_rules[ruleVariable]() {
-												goto l267
+												goto l579
 											}
 										}
-									l270:
+									l582:
 										depth--
-										add(ruleLoopConditionFixedLength, position268)
+										add(ruleLoopConditionFixedLength, position580)
 									}
 									if !_rules[ruleOPEN]() {
-										goto l267
+										goto l579
 									}
-								l272:
+								l584:
 									{
-										position273, tokenIndex273, depth273 := position, tokenIndex, depth
+										position585, tokenIndex585, depth585 := position, tokenIndex, depth
 										if !_rules[ruleBlock]() {
-											goto l273
+											goto l585
 										}
-										goto l272
-									l273:
-										position, tokenIndex, depth = position273, tokenIndex273, depth273
+										goto l584
+									l585:
+										position, tokenIndex, depth = position585, tokenIndex585, depth585
 									}
 									if !_rules[ruleCLOSE]() {
-										goto l267
+										goto l579
 									}
-									goto l263
-								l267:
-									position, tokenIndex, depth = position263, tokenIndex263, depth263
+									goto l575
+								l579:
+									position, tokenIndex, depth = position575, tokenIndex575, depth575
 									{
-										position275 := position
+										position587 := position
 										depth++
 										{
-											position276 := position
+											position588 := position
 											depth++
 											if !_rules[ruleVariableSequence]() {
-												goto l274
+												goto l586
 											}
 											depth--
-											add(ruleLoopIterableLHS, position276)
+											add(ruleLoopIterableLHS, position588)
 										}
 										{
-											position277 := position
+											position589 := position
 											depth++
 											if !_rules[rule__]() {
-												goto l274
+												goto l586
 											}
 											if buffer[position] != rune('i') {
-												goto l274
+												goto l586
 											}
 											position++
 											if buffer[position] != rune('n') {
-												goto l274
+												goto l586
 											}
 											position++
 											if !_rules[rule__]() {
-												goto l274
+												goto l586
 											}
 											depth--
-											add(ruleIN, position277)
+											add(ruleIN, position589)
 										}
 										{
-											position278 := position
+											position590 := position
 											depth++
 											{
-												position279, tokenIndex279, depth279 := position, tokenIndex, depth
+												position591, tokenIndex591, depth591 := position, tokenIndex, depth
+												{
+													position593 := position
+													depth++
+													if !_rules[ruleExpression]() {
+														goto l592
+													}
+													if !_rules[ruleMatch]() {
+														goto l592
+													}
+													if !_rules[ruleRegularExpression]() {
+														goto l592
+													}
+													depth--
+													add(ruleLoopIterableMatch, position593)
+												}
+												goto l591
+											l592:
+												position, tokenIndex, depth = position591, tokenIndex591, depth591
 												if !_rules[ruleCommand]() {
-													goto l280
+													goto l594
 												}
-												goto l279
-											l280:
-												position, tokenIndex, depth = position279, tokenIndex279, depth279
+												goto l591
+											l594:
+												position, tokenIndex, depth = position591, tokenIndex591, depth591
 												if !_rules[ruleVariable]() {
-													goto l274
+													goto l586
 												}
 											}
-										l279:
+										l591:
 											depth--
-											add(ruleLoopIterableRHS, position278)
+											add(ruleLoopIterableRHS, position590)
+										}
+										depth--
+										add(ruleLoopConditionIterable, position587)
+									}
+									if !_rules[ruleOPEN]() {
+										goto l586
+									}
+								l595:
+									{
+										position596, tokenIndex596, depth596 := position, tokenIndex, depth
+										if !_rules[ruleBlock]() {
+											goto l596
+										}
+										goto l595
+									l596:
+										position, tokenIndex, depth = position596, tokenIndex596, depth596
+									}
+									if !_rules[ruleCLOSE]() {
+										goto l586
+									}
+									goto l575
+								l586:
+									position, tokenIndex, depth = position575, tokenIndex575, depth575
+									{
+										position598 := position
+										depth++
+										if !_rules[ruleCommand]() {
+											goto l597
+										}
+										if !_rules[ruleSEMI]() {
+											goto l597
+										}
+										if !_rules[ruleConditionalExpression]() {
+											goto l597
+										}
+										if !_rules[ruleSEMI]() {
+											goto l597
+										}
+										if !_rules[ruleCommand]() {
+											goto l597
+										}
+										depth--
+										add(ruleLoopConditionBounded, position598)
+									}
+									if !_rules[ruleOPEN]() {
+										goto l597
+									}
+								l599:
+									{
+										position600, tokenIndex600, depth600 := position, tokenIndex, depth
+										if !_rules[ruleBlock]() {
+											goto l600
+										}
+										goto l599
+									l600:
+										position, tokenIndex, depth = position600, tokenIndex600, depth600
+									}
+									if !_rules[ruleCLOSE]() {
+										goto l597
+									}
+									goto l575
+								l597:
+									position, tokenIndex, depth = position575, tokenIndex575, depth575
+									{
+										position601 := position
+										depth++
+										if !_rules[ruleConditionalExpression]() {
+											goto l572
 										}
 										depth--
-										add(ruleLoopConditionIterable, position275)
+										add(ruleLoopConditionTruthy, position601)
+									}
+									if !_rules[ruleOPEN]() {
+										goto l572
+									}
+								l602:
+									{
+										position603, tokenIndex603, depth603 := position, tokenIndex, depth
+										if !_rules[ruleBlock]() {
+											goto l603
+										}
+										goto l602
+									l603:
+										position, tokenIndex, depth = position603, tokenIndex603, depth603
+									}
+									if !_rules[ruleCLOSE]() {
+										goto l572
+									}
+								}
+							l575:
+								depth--
+								add(ruleLoop, position573)
+							}
+							goto l488
+						l572:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position605 := position
+								depth++
+								{
+									position606 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l604
+									}
+									if buffer[position] != rune('d') {
+										goto l604
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l604
+									}
+									position++
+									if buffer[position] != rune('f') {
+										goto l604
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l604
+									}
+									position++
+									if buffer[position] != rune('r') {
+										goto l604
+									}
+									position++
+									if !_rules[rule_]() {
+										goto l604
+									}
+									depth--
+									add(ruleDEFER, position606)
+								}
+								if !_rules[ruleOPEN]() {
+									goto l604
+								}
+							l607:
+								{
+									position608, tokenIndex608, depth608 := position, tokenIndex, depth
+									if !_rules[ruleBlock]() {
+										goto l608
+									}
+									goto l607
+								l608:
+									position, tokenIndex, depth = position608, tokenIndex608, depth608
+								}
+								if !_rules[ruleCLOSE]() {
+									goto l604
+								}
+								depth--
+								add(ruleDefer, position605)
+							}
+							goto l488
+						l604:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position610 := position
+								depth++
+								{
+									position611 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l609
 									}
-									if !_rules[ruleOPEN]() {
-										goto l274
+									if buffer[position] != rune('r') {
+										goto l609
 									}
-								l281:
-									{
-										position282, tokenIndex282, depth282 := position, tokenIndex, depth
-										if !_rules[ruleBlock]() {
-											goto l282
-										}
-										goto l281
-									l282:
-										position, tokenIndex, depth = position282, tokenIndex282, depth282
+									position++
+									if buffer[position] != rune('e') {
+										goto l609
 									}
-									if !_rules[ruleCLOSE]() {
-										goto l274
+									position++
+									if buffer[position] != rune('t') {
+										goto l609
 									}
-									goto l263
-								l274:
-									position, tokenIndex, depth = position263, tokenIndex263, depth263
-									{
-										position284 := position
-										depth++
-										if !_rules[ruleCommand]() {
-											goto l283
-										}
-										if !_rules[ruleSEMI]() {
-											goto l283
-										}
-										if !_rules[ruleConditionalExpression]() {
-											goto l283
-										}
-										if !_rules[ruleSEMI]() {
-											goto l283
-										}
-										if !_rules[ruleCommand]() {
-											goto l283
-										}
-										depth--
-										add(ruleLoopConditionBounded, position284)
+									position++
+									if buffer[position] != rune('r') {
+										goto l609
 									}
-									if !_rules[ruleOPEN]() {
-										goto l283
+									position++
+									if buffer[position] != rune('y') {
+										goto l609
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l609
 									}
-								l285:
+									depth--
+									add(ruleRETRY, position611)
+								}
+								{
+									position612 := position
+									depth++
 									{
-										position286, tokenIndex286, depth286 := position, tokenIndex, depth
-										if !_rules[ruleBlock]() {
-											goto l286
+										position613, tokenIndex613, depth613 := position, tokenIndex, depth
+										if !_rules[ruleInteger]() {
+											goto l614
+										}
+										goto l613
+									l614:
+										position, tokenIndex, depth = position613, tokenIndex613, depth613
+										if !_rules[ruleVariable]() {
+											goto l609
 										}
-										goto l285
-									l286:
-										position, tokenIndex, depth = position286, tokenIndex286, depth286
 									}
-									if !_rules[ruleCLOSE]() {
-										goto l283
+								l613:
+									depth--
+									add(ruleRetryAttempts, position612)
+								}
+								{
+									position615, tokenIndex615, depth615 := position, tokenIndex, depth
+									if !_rules[rule_]() {
+										goto l615
 									}
-									goto l263
-								l283:
-									position, tokenIndex, depth = position263, tokenIndex263, depth263
 									{
-										position287 := position
+										position617 := position
 										depth++
-										if !_rules[ruleConditionalExpression]() {
-											goto l260
+										if !_rules[ruleObject]() {
+											goto l615
 										}
 										depth--
-										add(ruleLoopConditionTruthy, position287)
+										add(ruleRetryOptions, position617)
 									}
-									if !_rules[ruleOPEN]() {
-										goto l260
+									goto l616
+								l615:
+									position, tokenIndex, depth = position615, tokenIndex615, depth615
+								}
+							l616:
+								if !_rules[ruleOPEN]() {
+									goto l609
+								}
+							l618:
+								{
+									position619, tokenIndex619, depth619 := position, tokenIndex, depth
+									if !_rules[ruleBlock]() {
+										goto l619
+									}
+									goto l618
+								l619:
+									position, tokenIndex, depth = position619, tokenIndex619, depth619
+								}
+								if !_rules[ruleCLOSE]() {
+									goto l609
+								}
+								depth--
+								add(ruleRetry, position610)
+							}
+							goto l488
+						l609:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
+							{
+								position621 := position
+								depth++
+								{
+									position622 := position
+									depth++
+									if !_rules[rule_]() {
+										goto l620
 									}
-								l288:
+									if buffer[position] != rune('t') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('i') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('m') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('e') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('o') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('u') {
+										goto l620
+									}
+									position++
+									if buffer[position] != rune('t') {
+										goto l620
+									}
+									position++
+									if !_rules[rule__]() {
+										goto l620
+									}
+									depth--
+									add(ruleTIMEOUT, position622)
+								}
+								{
+									position623 := position
+									depth++
 									{
-										position289, tokenIndex289, depth289 := position, tokenIndex, depth
-										if !_rules[ruleBlock]() {
-											goto l289
+										position624, tokenIndex624, depth624 := position, tokenIndex, depth
+										if !_rules[ruleDuration]() {
+											goto l625
+										}
+										goto l624
+									l625:
+										position, tokenIndex, depth = position624, tokenIndex624, depth624
+										if !_rules[ruleVariable]() {
+											goto l626
+										}
+										goto l624
+									l626:
+										position, tokenIndex, depth = position624, tokenIndex624, depth624
+										if !_rules[ruleInteger]() {
+											goto l620
 										}
-										goto l288
-									l289:
-										position, tokenIndex, depth = position289, tokenIndex289, depth289
 									}
-									if !_rules[ruleCLOSE]() {
-										goto l260
+								l624:
+									depth--
+									add(ruleTimeoutDuration, position623)
+								}
+								if !_rules[ruleOPEN]() {
+									goto l620
+								}
+							l627:
+								{
+									position628, tokenIndex628, depth628 := position, tokenIndex, depth
+									if !_rules[ruleBlock]() {
+										goto l628
 									}
+									goto l627
+								l628:
+									position, tokenIndex, depth = position628, tokenIndex628, depth628
+								}
+								if !_rules[ruleCLOSE]() {
+									goto l620
 								}
-							l263:
 								depth--
-								add(ruleLoop, position261)
+								add(ruleTimeout, position621)
 							}
-							goto l235
-						l260:
-							position, tokenIndex, depth = position235, tokenIndex235, depth235
+							goto l488
+						l620:
+							position, tokenIndex, depth = position488, tokenIndex488, depth488
 							if !_rules[ruleCommand]() {
-								goto l214
+								goto l427
 							}
 						}
-					l235:
+					l488:
 						depth--
-						add(ruleStatementBlock, position234)
+						add(ruleStatementBlock, position487)
 					}
 				}
-			l216:
+			l429:
 				{
-					position290, tokenIndex290, depth290 := position, tokenIndex, depth
+					position629, tokenIndex629, depth629 := position, tokenIndex, depth
 					if !_rules[ruleSEMI]() {
-						goto l290
+						goto l629
 					}
-					goto l291
-				l290:
-					position, tokenIndex, depth = position290, tokenIndex290, depth290
+					goto l630
+				l629:
+					position, tokenIndex, depth = position629, tokenIndex629, depth629
 				}
-			l291:
+			l630:
 				if !_rules[rule_]() {
-					goto l214
+					goto l427
 				}
 				depth--
-				add(ruleBlock, position215)
+				add(ruleBlock, position428)
 			}
 			return true
-		l214:
-			position, tokenIndex, depth = position214, tokenIndex214, depth214
+		l427:
+			position, tokenIndex, depth = position427, tokenIndex427, depth427
 			return false
 		},
-		/* 83 FlowControlWord <- <(FlowControlBreak / FlowControlContinue)> */
+		/* 109 FlowControlWord <- <(FlowControlBreak / FlowControlContinue / FlowControlReturn / FlowControlExit)> */
+		nil,
+		/* 110 FlowControlBreak <- <(BREAK PositiveInteger?)> */
 		nil,
-		/* 84 FlowControlBreak <- <(BREAK PositiveInteger?)> */
+		/* 111 FlowControlContinue <- <(CONT PositiveInteger?)> */
 		nil,
-		/* 85 FlowControlContinue <- <(CONT PositiveInteger?)> */
+		/* 112 FlowControlReturn <- <(RETURN ((' ' / '\t')+ !('\r' / '\n' / '#' / ';' / '}') Expression)?)> */
 		nil,
-		/* 86 StatementBlock <- <(NOOP / Assignment / Directive / Conditional / Loop / Command)> */
+		/* 113 FlowControlExit <- <(EXIT ((' ' / '\t')+ !('\r' / '\n' / '#' / ';' / '}') Expression)?)> */
 		nil,
-		/* 87 Assignment <- <(AssignmentLHS AssignmentOperator AssignmentRHS)> */
+		/* 114 StatementBlock <- <(NOOP / Use / Param / Constant / Assignment / Directive / Conditional / Switch / Loop / Defer / Retry / Timeout / Command)> */
+		nil,
+		/* 115 Assignment <- <(AssignmentLHS AssignmentOperator AssignmentRHS)> */
 		func() bool {
-			position296, tokenIndex296, depth296 := position, tokenIndex, depth
+			position637, tokenIndex637, depth637 := position, tokenIndex, depth
 			{
-				position297 := position
+				position638 := position
 				depth++
 				{
-					position298 := position
+					position639 := position
 					depth++
 					if !_rules[ruleVariableSequence]() {
-						goto l296
+						goto l637
 					}
 					depth--
-					add(ruleAssignmentLHS, position298)
+					add(ruleAssignmentLHS, position639)
 				}
 				{
-					position299 := position
+					position640 := position
 					depth++
 					if !_rules[rule_]() {
-						goto l296
+						goto l637
 					}
 					{
-						position300, tokenIndex300, depth300 := position, tokenIndex, depth
+						position641, tokenIndex641, depth641 := position, tokenIndex, depth
 						{
-							position302 := position
+							position643 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l301
+								goto l642
 							}
 							if buffer[position] != rune('=') {
-								goto l301
+								goto l642
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l301
+								goto l642
 							}
 							depth--
-							add(ruleAssignEq, position302)
+							add(ruleAssignEq, position643)
 						}
-						goto l300
-					l301:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l642:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position304 := position
+							position645 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l303
+								goto l644
 							}
 							if buffer[position] != rune('*') {
-								goto l303
+								goto l644
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l303
+								goto l644
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l303
+								goto l644
 							}
 							depth--
-							add(ruleStarEq, position304)
+							add(ruleStarEq, position645)
 						}
-						goto l300
-					l303:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l644:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position306 := position
+							position647 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l305
+								goto l646
 							}
 							if buffer[position] != rune('/') {
-								goto l305
+								goto l646
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l305
+								goto l646
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l305
+								goto l646
 							}
 							depth--
-							add(ruleDivEq, position306)
+							add(ruleDivEq, position647)
 						}
-						goto l300
-					l305:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l646:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position308 := position
+							position649 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l307
+								goto l648
 							}
 							if buffer[position] != rune('+') {
-								goto l307
+								goto l648
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l307
+								goto l648
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l307
+								goto l648
 							}
 							depth--
-							add(rulePlusEq, position308)
+							add(rulePlusEq, position649)
 						}
-						goto l300
-					l307:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l648:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position310 := position
+							position651 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l309
+								goto l650
 							}
 							if buffer[position] != rune('-') {
-								goto l309
+								goto l650
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l309
+								goto l650
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l309
+								goto l650
 							}
 							depth--
-							add(ruleMinusEq, position310)
+							add(ruleMinusEq, position651)
 						}
-						goto l300
-					l309:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l650:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position312 := position
+							position653 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l311
+								goto l652
 							}
 							if buffer[position] != rune('&') {
-								goto l311
+								goto l652
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l311
+								goto l652
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l311
+								goto l652
 							}
 							depth--
-							add(ruleAndEq, position312)
+							add(ruleAndEq, position653)
 						}
-						goto l300
-					l311:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l652:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position314 := position
+							position655 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l313
+								goto l654
 							}
 							if buffer[position] != rune('|') {
-								goto l313
+								goto l654
 							}
 							position++
 							if buffer[position] != rune('=') {
-								goto l313
+								goto l654
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l313
+								goto l654
 							}
 							depth--
-							add(ruleOrEq, position314)
+							add(ruleOrEq, position655)
 						}
-						goto l300
-					l313:
-						position, tokenIndex, depth = position300, tokenIndex300, depth300
+						goto l641
+					l654:
+						position, tokenIndex, depth = position641, tokenIndex641, depth641
 						{
-							position315 := position
+							position656 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l296
+								goto l637
 							}
 							if buffer[position] != rune('<') {
-								goto l296
+								goto l637
 							}
 							position++
 							if buffer[position] != rune('<') {
-								goto l296
+								goto l637
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l296
+								goto l637
 							}
 							depth--
-							add(ruleAppend, position315)
+							add(ruleAppend, position656)
 						}
 					}
-				l300:
+				l641:
 					if !_rules[rule_]() {
-						goto l296
+						goto l637
 					}
 					depth--
-					add(ruleAssignmentOperator, position299)
+					add(ruleAssignmentOperator, position640)
 				}
 				{
-					position316 := position
+					position657 := position
 					depth++
 					if !_rules[ruleExpressionSequence]() {
-						goto l296
+						goto l637
 					}
 					depth--
-					add(ruleAssignmentRHS, position316)
+					add(ruleAssignmentRHS, position657)
 				}
 				depth--
-				add(ruleAssignment, position297)
+				add(ruleAssignment, position638)
 			}
 			return true
-		l296:
-			position, tokenIndex, depth = position296, tokenIndex296, depth296
+		l637:
+			position, tokenIndex, depth = position637, tokenIndex637, depth637
 			return false
 		},
-		/* 88 AssignmentLHS <- <VariableSequence> */
+		/* 116 AssignmentLHS <- <VariableSequence> */
+		nil,
+		/* 117 Constant <- <(CONST Assignment)> */
 		nil,
-		/* 89 AssignmentRHS <- <ExpressionSequence> */
+		/* 118 AssignmentRHS <- <ExpressionSequence> */
 		nil,
-		/* 90 VariableSequence <- <((Variable COMMA)* Variable)> */
+		/* 119 VariableSequence <- <((Variable COMMA)* Variable)> */
 		func() bool {
-			position319, tokenIndex319, depth319 := position, tokenIndex, depth
+			position661, tokenIndex661, depth661 := position, tokenIndex, depth
 			{
-				position320 := position
+				position662 := position
 				depth++
-			l321:
+			l663:
 				{
-					position322, tokenIndex322, depth322 := position, tokenIndex, depth
+					position664, tokenIndex664, depth664 := position, tokenIndex, depth
 					if !_rules[ruleVariable]() {
-						goto l322
+						goto l664
 					}
 					if !_rules[ruleCOMMA]() {
-						goto l322
+						goto l664
 					}
-					goto l321
-				l322:
-					position, tokenIndex, depth = position322, tokenIndex322, depth322
+					goto l663
+				l664:
+					position, tokenIndex, depth = position664, tokenIndex664, depth664
 				}
 				if !_rules[ruleVariable]() {
-					goto l319
+					goto l661
 				}
 				depth--
-				add(ruleVariableSequence, position320)
+				add(ruleVariableSequence, position662)
 			}
 			return true
-		l319:
-			position, tokenIndex, depth = position319, tokenIndex319, depth319
+		l661:
+			position, tokenIndex, depth = position661, tokenIndex661, depth661
 			return false
 		},
-		/* 91 ExpressionSequence <- <((Expression COMMA)* Expression)> */
+		/* 120 ExpressionSequence <- <((Expression COMMA)* Expression)> */
 		func() bool {
-			position323, tokenIndex323, depth323 := position, tokenIndex, depth
+			position665, tokenIndex665, depth665 := position, tokenIndex, depth
 			{
-				position324 := position
+				position666 := position
 				depth++
-			l325:
+			l667:
 				{
-					position326, tokenIndex326, depth326 := position, tokenIndex, depth
+					position668, tokenIndex668, depth668 := position, tokenIndex, depth
 					if !_rules[ruleExpression]() {
-						goto l326
+						goto l668
 					}
 					if !_rules[ruleCOMMA]() {
-						goto l326
+						goto l668
 					}
-					goto l325
-				l326:
-					position, tokenIndex, depth = position326, tokenIndex326, depth326
+					goto l667
+				l668:
+					position, tokenIndex, depth = position668, tokenIndex668, depth668
 				}
 				if !_rules[ruleExpression]() {
-					goto l323
+					goto l665
 				}
 				depth--
-				add(ruleExpressionSequence, position324)
+				add(ruleExpressionSequence, position666)
 			}
 			return true
-		l323:
-			position, tokenIndex, depth = position323, tokenIndex323, depth323
+		l665:
+			position, tokenIndex, depth = position665, tokenIndex665, depth665
 			return false
 		},
-		/* 92 Expression <- <(_ ExpressionLHS ExpressionRHS? _)> */
+		/* 121 Expression <- <(_ ExpressionLHS ExpressionRHS? ExpressionTail? _)> */
 		func() bool {
-			position327, tokenIndex327, depth327 := position, tokenIndex, depth
+			position669, tokenIndex669, depth669 := position, tokenIndex, depth
 			{
-				position328 := position
+				position670 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l327
+					goto l669
+				}
+				if !_rules[ruleExpressionLHS]() {
+					goto l669
 				}
 				{
-					position329 := position
-					depth++
-					{
-						position330 := position
-						depth++
-						{
-							position331, tokenIndex331, depth331 := position, tokenIndex, depth
-							if !_rules[ruleType]() {
-								goto l332
-							}
-							goto l331
-						l332:
-							position, tokenIndex, depth = position331, tokenIndex331, depth331
-							if !_rules[ruleVariable]() {
-								goto l327
-							}
-						}
-					l331:
-						depth--
-						add(ruleValueYielding, position330)
+					position671, tokenIndex671, depth671 := position, tokenIndex, depth
+					if !_rules[ruleExpressionRHS]() {
+						goto l671
 					}
-					depth--
-					add(ruleExpressionLHS, position329)
+					goto l672
+				l671:
+					position, tokenIndex, depth = position671, tokenIndex671, depth671
 				}
+			l672:
 				{
-					position333, tokenIndex333, depth333 := position, tokenIndex, depth
+					position673, tokenIndex673, depth673 := position, tokenIndex, depth
 					{
-						position335 := position
+						position675 := position
 						depth++
 						{
-							position336 := position
-							depth++
-							if !_rules[rule_]() {
-								goto l333
-							}
+							position676, tokenIndex676, depth676 := position, tokenIndex, depth
 							{
-								position337, tokenIndex337, depth337 := position, tokenIndex, depth
-								{
-									position339 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l338
-									}
-									if buffer[position] != rune('*') {
-										goto l338
-									}
-									position++
-									if buffer[position] != rune('*') {
-										goto l338
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l338
-									}
-									depth--
-									add(ruleExponentiate, position339)
+								position678 := position
+								depth++
+								if !_rules[rule_]() {
+									goto l677
 								}
-								goto l337
-							l338:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position341 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l340
-									}
-									if buffer[position] != rune('*') {
-										goto l340
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l340
-									}
-									depth--
-									add(ruleMultiply, position341)
+								if buffer[position] != rune('?') {
+									goto l677
 								}
-								goto l337
-							l340:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position343 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l342
-									}
-									if buffer[position] != rune('/') {
-										goto l342
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l342
-									}
-									depth--
-									add(ruleDivide, position343)
+								position++
+								if buffer[position] != rune('?') {
+									goto l677
 								}
-								goto l337
-							l342:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position345 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l344
-									}
-									if buffer[position] != rune('%') {
-										goto l344
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l344
-									}
-									depth--
-									add(ruleModulus, position345)
+								position++
+								if !_rules[rule_]() {
+									goto l677
 								}
-								goto l337
-							l344:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position347 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l346
-									}
-									if buffer[position] != rune('+') {
-										goto l346
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l346
-									}
-									depth--
-									add(ruleAdd, position347)
+								if !_rules[ruleExpression]() {
+									goto l677
 								}
-								goto l337
-							l346:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
+								depth--
+								add(ruleExpressionCoalesce, position678)
+							}
+							goto l676
+						l677:
+							position, tokenIndex, depth = position676, tokenIndex676, depth676
+							{
+								position679 := position
+								depth++
 								{
-									position349 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l348
-									}
-									if buffer[position] != rune('-') {
-										goto l348
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l348
+									position680, tokenIndex680, depth680 := position, tokenIndex, depth
+									{
+										position682 := position
+										depth++
+										{
+											position683, tokenIndex683, depth683 := position, tokenIndex, depth
+											if !_rules[ruleComparisonOperator]() {
+												goto l684
+											}
+											if !_rules[ruleExpressionOperand]() {
+												goto l684
+											}
+											goto l683
+										l684:
+											position, tokenIndex, depth = position683, tokenIndex683, depth683
+											if !_rules[ruleMatchOperator]() {
+												goto l680
+											}
+											if !_rules[ruleRegularExpression]() {
+												goto l680
+											}
+										}
+									l683:
+										depth--
+										add(ruleExpressionTernaryCondition, position682)
 									}
-									depth--
-									add(ruleSubtract, position349)
+									goto l681
+								l680:
+									position, tokenIndex, depth = position680, tokenIndex680, depth680
 								}
-								goto l337
-							l348:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position351 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l350
-									}
-									if buffer[position] != rune('&') {
-										goto l350
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l350
-									}
-									depth--
-									add(ruleBitwiseAnd, position351)
+							l681:
+								if !_rules[rule_]() {
+									goto l673
 								}
-								goto l337
-							l350:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position353 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l352
-									}
-									if buffer[position] != rune('|') {
-										goto l352
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l352
-									}
-									depth--
-									add(ruleBitwiseOr, position353)
+								if buffer[position] != rune('?') {
+									goto l673
 								}
-								goto l337
-							l352:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
+								position++
 								{
-									position355 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l354
-									}
-									if buffer[position] != rune('~') {
-										goto l354
+									position685, tokenIndex685, depth685 := position, tokenIndex, depth
+									if buffer[position] != rune('?') {
+										goto l685
 									}
 									position++
-									if !_rules[rule_]() {
-										goto l354
-									}
-									depth--
-									add(ruleBitwiseNot, position355)
+									goto l673
+								l685:
+									position, tokenIndex, depth = position685, tokenIndex685, depth685
 								}
-								goto l337
-							l354:
-								position, tokenIndex, depth = position337, tokenIndex337, depth337
-								{
-									position356 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l333
-									}
-									if buffer[position] != rune('^') {
-										goto l333
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l333
-									}
-									depth--
-									add(ruleBitwiseXor, position356)
+								if !_rules[rule_]() {
+									goto l673
 								}
+								if !_rules[ruleExpression]() {
+									goto l673
+								}
+								if !_rules[ruleCOLON]() {
+									goto l673
+								}
+								if !_rules[ruleExpression]() {
+									goto l673
+								}
+								depth--
+								add(ruleExpressionTernary, position679)
+							}
+						}
+					l676:
+						depth--
+						add(ruleExpressionTail, position675)
+					}
+					goto l674
+				l673:
+					position, tokenIndex, depth = position673, tokenIndex673, depth673
+				}
+			l674:
+				if !_rules[rule_]() {
+					goto l669
+				}
+				depth--
+				add(ruleExpression, position670)
+			}
+			return true
+		l669:
+			position, tokenIndex, depth = position669, tokenIndex669, depth669
+			return false
+		},
+		/* 122 ExpressionLHS <- <ValueYielding> */
+		func() bool {
+			position686, tokenIndex686, depth686 := position, tokenIndex, depth
+			{
+				position687 := position
+				depth++
+				{
+					position688 := position
+					depth++
+					{
+						position689, tokenIndex689, depth689 := position, tokenIndex, depth
+						if !_rules[ruleType]() {
+							goto l690
+						}
+						goto l689
+					l690:
+						position, tokenIndex, depth = position689, tokenIndex689, depth689
+						if !_rules[ruleVariable]() {
+							goto l691
+						}
+						goto l689
+					l691:
+						position, tokenIndex, depth = position689, tokenIndex689, depth689
+						{
+							position692 := position
+							depth++
+							if buffer[position] != rune('(') {
+								goto l686
+							}
+							position++
+							if !_rules[rule_]() {
+								goto l686
+							}
+							if !_rules[ruleExpression]() {
+								goto l686
 							}
-						l337:
 							if !_rules[rule_]() {
-								goto l333
+								goto l686
 							}
+							if buffer[position] != rune(')') {
+								goto l686
+							}
+							position++
 							depth--
-							add(ruleOperator, position336)
+							add(ruleExpressionGroup, position692)
 						}
-						if !_rules[ruleExpression]() {
-							goto l333
-						}
-						depth--
-						add(ruleExpressionRHS, position335)
 					}
-					goto l334
-				l333:
-					position, tokenIndex, depth = position333, tokenIndex333, depth333
+				l689:
+					depth--
+					add(ruleValueYielding, position688)
+				}
+				depth--
+				add(ruleExpressionLHS, position687)
+			}
+			return true
+		l686:
+			position, tokenIndex, depth = position686, tokenIndex686, depth686
+			return false
+		},
+		/* 123 ExpressionRHS <- <(Operator ExpressionOperand)> */
+		func() bool {
+			position693, tokenIndex693, depth693 := position, tokenIndex, depth
+			{
+				position694 := position
+				depth++
+				if !_rules[ruleOperator]() {
+					goto l693
+				}
+				if !_rules[ruleExpressionOperand]() {
+					goto l693
+				}
+				depth--
+				add(ruleExpressionRHS, position694)
+			}
+			return true
+		l693:
+			position, tokenIndex, depth = position693, tokenIndex693, depth693
+			return false
+		},
+		/* 124 ExpressionOperand <- <(_ ExpressionLHS ExpressionRHS? _)> */
+		func() bool {
+			position695, tokenIndex695, depth695 := position, tokenIndex, depth
+			{
+				position696 := position
+				depth++
+				if !_rules[rule_]() {
+					goto l695
+				}
+				if !_rules[ruleExpressionLHS]() {
+					goto l695
+				}
+				{
+					position697, tokenIndex697, depth697 := position, tokenIndex, depth
+					if !_rules[ruleExpressionRHS]() {
+						goto l697
+					}
+					goto l698
+				l697:
+					position, tokenIndex, depth = position697, tokenIndex697, depth697
 				}
-			l334:
+			l698:
 				if !_rules[rule_]() {
-					goto l327
+					goto l695
 				}
 				depth--
-				add(ruleExpression, position328)
+				add(ruleExpressionOperand, position696)
 			}
 			return true
-		l327:
-			position, tokenIndex, depth = position327, tokenIndex327, depth327
+		l695:
+			position, tokenIndex, depth = position695, tokenIndex695, depth695
 			return false
 		},
-		/* 93 ExpressionLHS <- <ValueYielding> */
+		/* 125 ExpressionTail <- <(ExpressionCoalesce / ExpressionTernary)> */
+		nil,
+		/* 126 ExpressionCoalesce <- <(_ ('?' '?') _ Expression)> */
+		nil,
+		/* 127 ExpressionTernary <- <(ExpressionTernaryCondition? _ '?' !'?' _ Expression COLON Expression)> */
+		nil,
+		/* 128 ExpressionTernaryCondition <- <((ComparisonOperator ExpressionOperand) / (MatchOperator RegularExpression))> */
+		nil,
+		/* 129 ValueYielding <- <(Type / Variable / ExpressionGroup)> */
+		nil,
+		/* 130 ExpressionGroup <- <('(' _ Expression _ ')')> */
 		nil,
-		/* 94 ExpressionRHS <- <(Operator Expression)> */
+		/* 131 Use <- <(USE UseModule (__ ('a' 's') __ UseAlias)?)> */
 		nil,
-		/* 95 ValueYielding <- <(Type / Variable)> */
+		/* 132 UseModule <- <((Identifier SCOPE)* Identifier)> */
 		nil,
-		/* 96 Directive <- <(DirectiveUnset / DirectiveInclude / DirectiveDeclare)> */
+		/* 133 UseAlias <- <Identifier> */
 		nil,
-		/* 97 DirectiveUnset <- <(UNSET VariableSequence)> */
+		/* 134 Param <- <(PARAM Variable (COLON ParamType)? ((__ ParamRequired) / ParamDefault)?)> */
 		nil,
-		/* 98 DirectiveInclude <- <(INCLUDE String)> */
+		/* 135 ParamType <- <Identifier> */
 		nil,
-		/* 99 DirectiveDeclare <- <(DECLARE VariableSequence)> */
+		/* 136 ParamRequired <- <('r' 'e' 'q' 'u' 'i' 'r' 'e' 'd' !([a-z] / [A-Z] / [0-9] / '_'))> */
 		nil,
-		/* 100 Command <- <(_ CommandName (__ ((CommandFirstArg __ CommandSecondArg) / CommandFirstArg / CommandSecondArg))? (_ CommandResultAssignment)?)> */
+		/* 137 ParamDefault <- <(_ '=' !('=' / '~') _ Expression)> */
+		nil,
+		/* 138 Directive <- <(DirectiveUnset / DirectiveInclude / DirectiveDeclare)> */
+		nil,
+		/* 139 DirectiveUnset <- <(UNSET VariableSequence)> */
+		nil,
+		/* 140 DirectiveInclude <- <(INCLUDE String)> */
+		nil,
+		/* 141 DirectiveDeclare <- <(DECLARE VariableSequence)> */
+		nil,
+		/* 142 Command <- <(_ CommandName (__ ((CommandFirstArg __ CommandSecondArg) / CommandFirstArg / CommandSecondArg))? (_ CommandResultAssignment)?)> */
 		func() bool {
-			position364, tokenIndex364, depth364 := position, tokenIndex, depth
+			position716, tokenIndex716, depth716 := position, tokenIndex, depth
 			{
-				position365 := position
+				position717 := position
 				depth++
 				if !_rules[rule_]() {
-					goto l364
+					goto l716
 				}
 				{
-					position366 := position
+					position718 := position
 					depth++
+				l719:
 					{
-						position367, tokenIndex367, depth367 := position, tokenIndex, depth
+						position720, tokenIndex720, depth720 := position, tokenIndex, depth
 						if !_rules[ruleIdentifier]() {
-							goto l367
+							goto l720
 						}
-						{
-							position369 := position
-							depth++
-							if buffer[position] != rune(':') {
-								goto l367
-							}
-							position++
-							if buffer[position] != rune(':') {
-								goto l367
-							}
-							position++
-							depth--
-							add(ruleSCOPE, position369)
+						if !_rules[ruleSCOPE]() {
+							goto l720
 						}
-						goto l368
-					l367:
-						position, tokenIndex, depth = position367, tokenIndex367, depth367
+						goto l719
+					l720:
+						position, tokenIndex, depth = position720, tokenIndex720, depth720
 					}
-				l368:
 					if !_rules[ruleIdentifier]() {
-						goto l364
+						goto l716
 					}
 					depth--
-					add(ruleCommandName, position366)
+					add(ruleCommandName, position718)
 				}
 				{
-					position370, tokenIndex370, depth370 := position, tokenIndex, depth
+					position721, tokenIndex721, depth721 := position, tokenIndex, depth
 					if !_rules[rule__]() {
-						goto l370
+						goto l721
 					}
 					{
-						position372, tokenIndex372, depth372 := position, tokenIndex, depth
+						position723, tokenIndex723, depth723 := position, tokenIndex, depth
 						if !_rules[ruleCommandFirstArg]() {
-							goto l373
+							goto l724
 						}
 						if !_rules[rule__]() {
-							goto l373
+							goto l724
 						}
 						if !_rules[ruleCommandSecondArg]() {
-							goto l373
+							goto l724
 						}
-						goto l372
-					l373:
-						position, tokenIndex, depth = position372, tokenIndex372, depth372
+						goto l723
+					l724:
+						position, tokenIndex, depth = position723, tokenIndex723, depth723
 						if !_rules[ruleCommandFirstArg]() {
-							goto l374
+							goto l725
 						}
-						goto l372
-					l374:
-						position, tokenIndex, depth = position372, tokenIndex372, depth372
+						goto l723
+					l725:
+						position, tokenIndex, depth = position723, tokenIndex723, depth723
 						if !_rules[ruleCommandSecondArg]() {
-							goto l370
+							goto l721
 						}
 					}
-				l372:
-					goto l371
-				l370:
-					position, tokenIndex, depth = position370, tokenIndex370, depth370
+				l723:
+					goto l722
+				l721:
+					position, tokenIndex, depth = position721, tokenIndex721, depth721
 				}
-			l371:
+			l722:
 				{
-					position375, tokenIndex375, depth375 := position, tokenIndex, depth
+					position726, tokenIndex726, depth726 := position, tokenIndex, depth
 					if !_rules[rule_]() {
-						goto l375
+						goto l726
 					}
 					{
-						position377 := position
+						position728 := position
 						depth++
 						{
-							position378 := position
+							position729 := position
 							depth++
 							if !_rules[rule_]() {
-								goto l375
+								goto l726
 							}
 							if buffer[position] != rune('-') {
-								goto l375
+								goto l726
 							}
 							position++
 							if buffer[position] != rune('>') {
-								goto l375
+								goto l726
 							}
 							position++
 							if !_rules[rule_]() {
-								goto l375
+								goto l726
 							}
 							depth--
-							add(ruleASSIGN, position378)
+							add(ruleASSIGN, position729)
 						}
 						if !_rules[ruleVariable]() {
-							goto l375
+							goto l726
 						}
 						depth--
-						add(ruleCommandResultAssignment, position377)
+						add(ruleCommandResultAssignment, position728)
 					}
-					goto l376
-				l375:
-					position, tokenIndex, depth = position375, tokenIndex375, depth375
+					goto l727
+				l726:
+					position, tokenIndex, depth = position726, tokenIndex726, depth726
 				}
-			l376:
+			l727:
 				depth--
-				add(ruleCommand, position365)
+				add(ruleCommand, position717)
 			}
 			return true
-		l364:
-			position, tokenIndex, depth = position364, tokenIndex364, depth364
+		l716:
+			position, tokenIndex, depth = position716, tokenIndex716, depth716
 			return false
 		},
-		/* 101 CommandName <- <((Identifier SCOPE)? Identifier)> */
+		/* 143 CommandName <- <((Identifier SCOPE)* Identifier)> */
 		nil,
-		/* 102 CommandFirstArg <- <(Variable / Type)> */
+		/* 144 CommandFirstArg <- <(Variable / Type)> */
 		func() bool {
-			position380, tokenIndex380, depth380 := position, tokenIndex, depth
+			position731, tokenIndex731, depth731 := position, tokenIndex, depth
 			{
-				position381 := position
+				position732 := position
 				depth++
 				{
-					position382, tokenIndex382, depth382 := position, tokenIndex, depth
+					position733, tokenIndex733, depth733 := position, tokenIndex, depth
 					if !_rules[ruleVariable]() {
-						goto l383
+						goto l734
 					}
-					goto l382
-				l383:
-					position, tokenIndex, depth = position382, tokenIndex382, depth382
+					goto l733
+				l734:
+					position, tokenIndex, depth = position733, tokenIndex733, depth733
 					if !_rules[ruleType]() {
-						goto l380
+						goto l731
 					}
 				}
-			l382:
+			l733:
 				depth--
-				add(ruleCommandFirstArg, position381)
+				add(ruleCommandFirstArg, position732)
 			}
 			return true
-		l380:
-			position, tokenIndex, depth = position380, tokenIndex380, depth380
+		l731:
+			position, tokenIndex, depth = position731, tokenIndex731, depth731
 			return false
 		},
-		/* 103 CommandSecondArg <- <Object> */
+		/* 145 CommandSecondArg <- <Object> */
 		func() bool {
-			position384, tokenIndex384, depth384 := position, tokenIndex, depth
+			position735, tokenIndex735, depth735 := position, tokenIndex, depth
 			{
-				position385 := position
+				position736 := position
 				depth++
 				if !_rules[ruleObject]() {
-					goto l384
+					goto l735
 				}
 				depth--
-				add(ruleCommandSecondArg, position385)
+				add(ruleCommandSecondArg, position736)
 			}
 			return true
-		l384:
-			position, tokenIndex, depth = position384, tokenIndex384, depth384
+		l735:
+			position, tokenIndex, depth = position735, tokenIndex735, depth735
 			return false
 		},
-		/* 104 CommandResultAssignment <- <(ASSIGN Variable)> */
+		/* 146 CommandResultAssignment <- <(ASSIGN Variable)> */
 		nil,
-		/* 105 Conditional <- <(IfStanza ElseIfStanza* ElseStanza?)> */
+		/* 147 Conditional <- <(IfStanza ElseIfStanza* ElseStanza?)> */
 		nil,
-		/* 106 IfStanza <- <(IF ConditionalExpression OPEN Block* CLOSE)> */
+		/* 148 IfStanza <- <(IF ConditionalExpression OPEN Block* CLOSE)> */
 		func() bool {
-			position388, tokenIndex388, depth388 := position, tokenIndex, depth
+			position739, tokenIndex739, depth739 := position, tokenIndex, depth
 			{
-				position389 := position
+				position740 := position
 				depth++
 				{
-					position390 := position
+					position741 := position
 					depth++
 					if !_rules[rule_]() {
-						goto l388
+						goto l739
 					}
 					if buffer[position] != rune('i') {
-						goto l388
+						goto l739
 					}
 					position++
 					if buffer[position] != rune('f') {
-						goto l388
+						goto l739
 					}
 					position++
 					if !_rules[rule_]() {
-						goto l388
+						goto l739
 					}
 					depth--
-					add(ruleIF, position390)
+					add(ruleIF, position741)
 				}
 				if !_rules[ruleConditionalExpression]() {
-					goto l388
+					goto l739
 				}
 				if !_rules[ruleOPEN]() {
-					goto l388
+					goto l739
 				}
-			l391:
+			l742:
 				{
-					position392, tokenIndex392, depth392 := position, tokenIndex, depth
+					position743, tokenIndex743, depth743 := position, tokenIndex, depth
 					if !_rules[ruleBlock]() {
-						goto l392
+						goto l743
 					}
-					goto l391
-				l392:
-					position, tokenIndex, depth = position392, tokenIndex392, depth392
+					goto l742
+				l743:
+					position, tokenIndex, depth = position743, tokenIndex743, depth743
 				}
 				if !_rules[ruleCLOSE]() {
-					goto l388
+					goto l739
 				}
 				depth--
-				add(ruleIfStanza, position389)
+				add(ruleIfStanza, position740)
 			}
 			return true
-		l388:
-			position, tokenIndex, depth = position388, tokenIndex388, depth388
+		l739:
+			position, tokenIndex, depth = position739, tokenIndex739, depth739
 			return false
 		},
-		/* 107 ElseIfStanza <- <(ELSE IfStanza)> */
+		/* 149 ElseIfStanza <- <(ELSE IfStanza)> */
+		nil,
+		/* 150 ElseStanza <- <(ELSE OPEN Block* CLOSE)> */
+		nil,
+		/* 151 Switch <- <(SWITCH Expression OPEN (COMMENT _)* (SwitchCase (COMMENT _)*)* (SwitchDefault (COMMENT _)*)? CLOSE)> */
 		nil,
-		/* 108 ElseStanza <- <(ELSE OPEN Block* CLOSE)> */
+		/* 152 SwitchCase <- <(CASE (SwitchCaseRegex / SwitchCaseMembership / SwitchCaseValues) OPEN Block* CLOSE)> */
 		nil,
-		/* 109 Loop <- <(LOOP ((OPEN Block* CLOSE) / (LoopConditionFixedLength OPEN Block* CLOSE) / (LoopConditionIterable OPEN Block* CLOSE) / (LoopConditionBounded OPEN Block* CLOSE) / (LoopConditionTruthy OPEN Block* CLOSE)))> */
+		/* 153 SwitchCaseRegex <- <RegularExpression> */
 		nil,
-		/* 110 LoopConditionFixedLength <- <(COUNT (Integer / Variable))> */
+		/* 154 SwitchCaseMembership <- <('i' 'n' __ Expression)> */
 		nil,
-		/* 111 LoopConditionIterable <- <(LoopIterableLHS IN LoopIterableRHS)> */
+		/* 155 SwitchCaseValues <- <ExpressionSequence> */
 		nil,
-		/* 112 LoopIterableLHS <- <VariableSequence> */
+		/* 156 SwitchDefault <- <(DEFAULT OPEN Block* CLOSE)> */
 		nil,
-		/* 113 LoopIterableRHS <- <(Command / Variable)> */
+		/* 157 Defer <- <(DEFER OPEN Block* CLOSE)> */
 		nil,
-		/* 114 LoopConditionBounded <- <(Command SEMI ConditionalExpression SEMI Command)> */
+		/* 158 Retry <- <(RETRY RetryAttempts (_ RetryOptions)? OPEN Block* CLOSE)> */
 		nil,
-		/* 115 LoopConditionTruthy <- <ConditionalExpression> */
+		/* 159 RetryAttempts <- <(Integer / Variable)> */
 		nil,
-		/* 116 ConditionalExpression <- <(NOT? (ConditionWithAssignment / ConditionWithCommand / ConditionWithRegex / ConditionWithComparator))> */
+		/* 160 RetryOptions <- <Object> */
+		nil,
+		/* 161 Timeout <- <(TIMEOUT TimeoutDuration OPEN Block* CLOSE)> */
+		nil,
+		/* 162 TimeoutDuration <- <(Duration / Variable / Integer)> */
+		nil,
+		/* 163 Loop <- <(LOOP ((OPEN Block* CLOSE) / (LoopConditionFixedLength OPEN Block* CLOSE) / (LoopConditionIterable OPEN Block* CLOSE) / (LoopConditionBounded OPEN Block* CLOSE) / (LoopConditionTruthy OPEN Block* CLOSE)))> */
+		nil,
+		/* 164 LoopConditionFixedLength <- <(COUNT (Integer / Variable))> */
+		nil,
+		/* 165 LoopConditionIterable <- <(LoopIterableLHS IN LoopIterableRHS)> */
+		nil,
+		/* 166 LoopIterableLHS <- <VariableSequence> */
+		nil,
+		/* 167 LoopIterableRHS <- <(LoopIterableMatch / Command / Variable)> */
+		nil,
+		/* 168 LoopIterableMatch <- <(Expression Match RegularExpression)> */
+		nil,
+		/* 169 LoopConditionBounded <- <(Command SEMI ConditionalExpression SEMI Command)> */
+		nil,
+		/* 170 LoopConditionTruthy <- <ConditionalExpression> */
+		nil,
+		/* 171 ConditionalExpression <- <(NOT? (ConditionWithAssignment / ConditionWithCommand / ConditionWithRegex / ConditionWithComparator))> */
 		func() bool {
-			position402, tokenIndex402, depth402 := position, tokenIndex, depth
+			position766, tokenIndex766, depth766 := position, tokenIndex, depth
 			{
-				position403 := position
+				position767 := position
 				depth++
 				{
-					position404, tokenIndex404, depth404 := position, tokenIndex, depth
+					position768, tokenIndex768, depth768 := position, tokenIndex, depth
 					{
-						position406 := position
+						position770 := position
 						depth++
 						if !_rules[rule_]() {
-							goto l404
+							goto l768
 						}
 						if buffer[position] != rune('n') {
-							goto l404
+							goto l768
 						}
 						position++
 						if buffer[position] != rune('o') {
-							goto l404
+							goto l768
 						}
 						position++
 						if buffer[position] != rune('t') {
-							goto l404
+							goto l768
 						}
 						position++
 						if !_rules[rule__]() {
-							goto l404
+							goto l768
 						}
 						depth--
-						add(ruleNOT, position406)
+						add(ruleNOT, position770)
 					}
-					goto l405
-				l404:
-					position, tokenIndex, depth = position404, tokenIndex404, depth404
+					goto l769
+				l768:
+					position, tokenIndex, depth = position768, tokenIndex768, depth768
 				}
-			l405:
+			l769:
 				{
-					position407, tokenIndex407, depth407 := position, tokenIndex, depth
+					position771, tokenIndex771, depth771 := position, tokenIndex, depth
 					{
-						position409 := position
+						position773 := position
 						depth++
 						if !_rules[ruleAssignment]() {
-							goto l408
+							goto l772
 						}
 						if !_rules[ruleSEMI]() {
-							goto l408
+							goto l772
 						}
 						if !_rules[ruleConditionalExpression]() {
-							goto l408
+							goto l772
 						}
 						depth--
-						add(ruleConditionWithAssignment, position409)
+						add(ruleConditionWithAssignment, position773)
 					}
-					goto l407
-				l408:
-					position, tokenIndex, depth = position407, tokenIndex407, depth407
+					goto l771
+				l772:
+					position, tokenIndex, depth = position771, tokenIndex771, depth771
 					{
-						position411 := position
+						position775 := position
 						depth++
 						if !_rules[ruleCommand]() {
-							goto l410
+							goto l774
+						}
+						{
+							position776, tokenIndex776, depth776 := position, tokenIndex, depth
+							{
+								position777, tokenIndex777, depth777 := position, tokenIndex, depth
+								if !_rules[ruleComparisonOperator]() {
+									goto l778
+								}
+								goto l777
+							l778:
+								position, tokenIndex, depth = position777, tokenIndex777, depth777
+								if !_rules[ruleMatchOperator]() {
+									goto l779
+								}
+								goto l777
+							l779:
+								position, tokenIndex, depth = position777, tokenIndex777, depth777
+								if !_rules[ruleOperator]() {
+									goto l776
+								}
+							}
+						l777:
+							goto l774
+						l776:
+							position, tokenIndex, depth = position776, tokenIndex776, depth776
 						}
 						{
-							position412, tokenIndex412, depth412 := position, tokenIndex, depth
+							position780, tokenIndex780, depth780 := position, tokenIndex, depth
 							if !_rules[ruleSEMI]() {
-								goto l412
+								goto l780
 							}
 							if !_rules[ruleConditionalExpression]() {
-								goto l412
+								goto l780
 							}
-							goto l413
-						l412:
-							position, tokenIndex, depth = position412, tokenIndex412, depth412
+							goto l781
+						l780:
+							position, tokenIndex, depth = position780, tokenIndex780, depth780
 						}
-					l413:
+					l781:
 						depth--
-						add(ruleConditionWithCommand, position411)
+						add(ruleConditionWithCommand, position775)
 					}
-					goto l407
-				l410:
-					position, tokenIndex, depth = position407, tokenIndex407, depth407
+					goto l771
+				l774:
+					position, tokenIndex, depth = position771, tokenIndex771, depth771
 					{
-						position415 := position
+						position783 := position
 						depth++
 						if !_rules[ruleExpression]() {
-							goto l414
+							goto l782
 						}
-						{
-							position416 := position
-							depth++
-							{
-								position417, tokenIndex417, depth417 := position, tokenIndex, depth
-								{
-									position419 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l418
-									}
-									if buffer[position] != rune('=') {
-										goto l418
-									}
-									position++
-									if buffer[position] != rune('~') {
-										goto l418
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l418
-									}
-									depth--
-									add(ruleMatch, position419)
-								}
-								goto l417
-							l418:
-								position, tokenIndex, depth = position417, tokenIndex417, depth417
-								{
-									position420 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l414
-									}
-									if buffer[position] != rune('!') {
-										goto l414
-									}
-									position++
-									if buffer[position] != rune('~') {
-										goto l414
-									}
-									position++
-									if !_rules[rule_]() {
-										goto l414
-									}
-									depth--
-									add(ruleUnmatch, position420)
-								}
-							}
-						l417:
-							depth--
-							add(ruleMatchOperator, position416)
+						if !_rules[ruleMatchOperator]() {
+							goto l782
 						}
 						if !_rules[ruleRegularExpression]() {
-							goto l414
+							goto l782
 						}
 						depth--
-						add(ruleConditionWithRegex, position415)
+						add(ruleConditionWithRegex, position783)
 					}
-					goto l407
-				l414:
-					position, tokenIndex, depth = position407, tokenIndex407, depth407
+					goto l771
+				l782:
+					position, tokenIndex, depth = position771, tokenIndex771, depth771
 					{
-						position421 := position
+						position784 := position
 						depth++
 						{
-							position422 := position
+							position785 := position
 							depth++
 							if !_rules[ruleExpression]() {
-								goto l402
+								goto l766
 							}
 							depth--
-							add(ruleConditionWithComparatorLHS, position422)
+							add(ruleConditionWithComparatorLHS, position785)
 						}
 						{
-							position423, tokenIndex423, depth423 := position, tokenIndex, depth
+							position786, tokenIndex786, depth786 := position, tokenIndex, depth
 							{
-								position425 := position
+								position788 := position
 								depth++
-								{
-									position426 := position
-									depth++
-									if !_rules[rule_]() {
-										goto l423
-									}
-									{
-										position427, tokenIndex427, depth427 := position, tokenIndex, depth
-										{
-											position429 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l428
-											}
-											if buffer[position] != rune('=') {
-												goto l428
-											}
-											position++
-											if buffer[position] != rune('=') {
-												goto l428
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l428
-											}
-											depth--
-											add(ruleEquality, position429)
-										}
-										goto l427
-									l428:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position431 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l430
-											}
-											if buffer[position] != rune('!') {
-												goto l430
-											}
-											position++
-											if buffer[position] != rune('=') {
-												goto l430
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l430
-											}
-											depth--
-											add(ruleNonEquality, position431)
-										}
-										goto l427
-									l430:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position433 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l432
-											}
-											if buffer[position] != rune('>') {
-												goto l432
-											}
-											position++
-											if buffer[position] != rune('=') {
-												goto l432
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l432
-											}
-											depth--
-											add(ruleGreaterEqual, position433)
-										}
-										goto l427
-									l432:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position435 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l434
-											}
-											if buffer[position] != rune('<') {
-												goto l434
-											}
-											position++
-											if buffer[position] != rune('=') {
-												goto l434
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l434
-											}
-											depth--
-											add(ruleLessEqual, position435)
-										}
-										goto l427
-									l434:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position437 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l436
-											}
-											if buffer[position] != rune('>') {
-												goto l436
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l436
-											}
-											depth--
-											add(ruleGreaterThan, position437)
-										}
-										goto l427
-									l436:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position439 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l438
-											}
-											if buffer[position] != rune('<') {
-												goto l438
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l438
-											}
-											depth--
-											add(ruleLessThan, position439)
-										}
-										goto l427
-									l438:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position441 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l440
-											}
-											if buffer[position] != rune('i') {
-												goto l440
-											}
-											position++
-											if buffer[position] != rune('n') {
-												goto l440
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l440
-											}
-											depth--
-											add(ruleMembership, position441)
-										}
-										goto l427
-									l440:
-										position, tokenIndex, depth = position427, tokenIndex427, depth427
-										{
-											position442 := position
-											depth++
-											if !_rules[rule_]() {
-												goto l423
-											}
-											if buffer[position] != rune('n') {
-												goto l423
-											}
-											position++
-											if buffer[position] != rune('o') {
-												goto l423
-											}
-											position++
-											if buffer[position] != rune('t') {
-												goto l423
-											}
-											position++
-											if !_rules[rule__]() {
-												goto l423
-											}
-											if buffer[position] != rune('i') {
-												goto l423
-											}
-											position++
-											if buffer[position] != rune('n') {
-												goto l423
-											}
-											position++
-											if !_rules[rule_]() {
-												goto l423
-											}
-											depth--
-											add(ruleNonMembership, position442)
-										}
-									}
-								l427:
-									if !_rules[rule_]() {
-										goto l423
-									}
-									depth--
-									add(ruleComparisonOperator, position426)
+								if !_rules[ruleComparisonOperator]() {
+									goto l786
 								}
 								if !_rules[ruleExpression]() {
-									goto l423
+									goto l786
 								}
 								depth--
-								add(ruleConditionWithComparatorRHS, position425)
+								add(ruleConditionWithComparatorRHS, position788)
 							}
-							goto l424
-						l423:
-							position, tokenIndex, depth = position423, tokenIndex423, depth423
+							goto l787
+						l786:
+							position, tokenIndex, depth = position786, tokenIndex786, depth786
 						}
-					l424:
+					l787:
 						depth--
-						add(ruleConditionWithComparator, position421)
+						add(ruleConditionWithComparator, position784)
 					}
 				}
-			l407:
+			l771:
 				depth--
-				add(ruleConditionalExpression, position403)
+				add(ruleConditionalExpression, position767)
 			}
 			return true
-		l402:
-			position, tokenIndex, depth = position402, tokenIndex402, depth402
+		l766:
+			position, tokenIndex, depth = position766, tokenIndex766, depth766
 			return false
 		},
-		/* 117 ConditionWithAssignment <- <(Assignment SEMI ConditionalExpression)> */
+		/* 172 ConditionWithAssignment <- <(Assignment SEMI ConditionalExpression)> */
 		nil,
-		/* 118 ConditionWithCommand <- <(Command (SEMI ConditionalExpression)?)> */
+		/* 173 ConditionWithCommand <- <(Command !(ComparisonOperator / MatchOperator / Operator) (SEMI ConditionalExpression)?)> */
 		nil,
-		/* 119 ConditionWithRegex <- <(Expression MatchOperator RegularExpression)> */
+		/* 174 ConditionWithRegex <- <(Expression MatchOperator RegularExpression)> */
 		nil,
-		/* 120 ConditionWithComparator <- <(ConditionWithComparatorLHS ConditionWithComparatorRHS?)> */
+		/* 175 ConditionWithComparator <- <(ConditionWithComparatorLHS ConditionWithComparatorRHS?)> */
 		nil,
-		/* 121 ConditionWithComparatorLHS <- <Expression> */
+		/* 176 ConditionWithComparatorLHS <- <Expression> */
 		nil,
-		/* 122 ConditionWithComparatorRHS <- <(ComparisonOperator Expression)> */
+		/* 177 ConditionWithComparatorRHS <- <(ComparisonOperator Expression)> */
 		nil,
 	}
 	p.rules = _rules
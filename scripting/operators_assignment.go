@@ -2,10 +2,11 @@ package scripting
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 
-	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/sliceutil"
-	"github.com/PerformLine/go-stockutil/stringutil"
 )
 
 type AssignmentOperator int
@@ -86,65 +87,69 @@ func (self AssignmentOperator) Evaluate(lhs interface{}, rhs interface{}) (inter
 	if v, err := exprToValue(lhs); err == nil {
 		lhs = v
 	} else {
-		log.Panicf("malformed  left-hand expression: %v", err)
+		return nil, fmt.Errorf("malformed left-hand expression: %v", err)
 	}
 
 	if v, err := exprToValue(rhs); err == nil {
 		rhs = v
 	} else {
-		log.Panicf("malformed right-hand expression (%T): %v", rhs, err)
+		return nil, err
 	}
 
-	var lv float64
-	var rv float64
-	var lverr error
-	var rverr error
+	switch self {
+	case assnAssignEq:
+		return rhs, nil
 
-	// log.Debugf("lhs=%T(%v) %v rhs=%T(%v)", lhs, lhs, self, rhs, rhs)
+	case assnAppend:
+		return append(sliceutil.Sliceify(lhs), rhs), nil
+	}
 
-	lv, lverr = stringutil.ConvertToFloat(lhs)
-	rv, rverr = stringutil.ConvertToFloat(rhs)
+	// strings containing numbers are treated as those numbers (e.g.: $n += "2" adds 2 to $n), and
+	// only other strings are concatenated by +=
+	lhs = numericString(lhs)
+	rhs = numericString(rhs)
 
-	switch self {
-	case assnAssignEq, assnAppend:
-		break
-	default:
-		if lverr != nil {
-			lv = 0
-		}
+	// compound assignment to an unset variable starts from the zero value of the right-hand side
+	if isEmpty(lhs) && !isEmpty(rhs) {
+		lhs = reflect.Zero(reflect.TypeOf(rhs)).Interface()
+	}
 
-		if rverr != nil {
-			return nil, rverr
-		}
+	if op := self.operator(); op != opNull {
+		return op.evaluate(lhs, rhs)
 	}
 
-	switch self {
-	case assnAssignEq:
-		return rhs, nil
+	return 0, fmt.Errorf("unsupported assignment operator %v", self)
+}
 
+// return the arithmetic operator that compound assignment operators (e.g.: "+=") perform
+func (self AssignmentOperator) operator() operator {
+	switch self {
 	case assnStarEq:
-		return (lv * rv), nil
-
+		return opMultiply
 	case assnDivEq:
-		if rv == 0 {
-			return 0, fmt.Errorf("divide by zero")
-		}
-
-		return (lv / rv), nil
-
+		return opDivide
 	case assnPlusEq:
-		return (lv + rv), nil
-
+		return opAdd
 	case assnMinusEq:
-		return (lv - rv), nil
+		return opSubtract
 	case assnAndEq:
-		return int64(lv) & int64(rv), nil
-
+		return opBitwiseAnd
 	case assnOrEq:
-		return int64(lv) | int64(rv), nil
-	case assnAppend:
-		return append(sliceutil.Sliceify(lhs), rhs), nil
+		return opBitwiseOr
+	default:
+		return opNull
 	}
+}
 
-	return 0, fmt.Errorf("unsupported assignment operator %v", self)
+// convert a string containing an integer or floating point number into that number
+func numericString(in interface{}) interface{} {
+	if s, ok := in.(string); ok {
+		if i, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil {
+			return i
+		} else if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+			return f
+		}
+	}
+
+	return in
 }
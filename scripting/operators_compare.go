@@ -57,9 +57,6 @@ func parseComparator(node *node32) (Comparator, error) {
 
 func (self Comparator) Evaluate(lhs *Expression, rhs *Expression) bool {
 	var lvv, rvv interface{}
-	var lv, rv float64
-	var lverr error
-	var rverr error
 
 	if lhs == nil {
 		log.Fatal("malformed expression: missing left-hand side")
@@ -77,72 +74,134 @@ func (self Comparator) Evaluate(lhs *Expression, rhs *Expression) bool {
 		log.Panicf("invalid expression result: %v", err)
 	}
 
+	if result, err := self.Compare(lvv, rvv); err == nil {
+		return result
+	} else {
+		log.Panic(err)
+		return false
+	}
+}
+
+// Compare two values using this comparator.
+func (self Comparator) Compare(lvv interface{}, rvv interface{}) (bool, error) {
+	var lv, rv float64
+	var lverr error
+	var rverr error
+
+	switch self {
+	case cmpMembership:
+		return isMemberOf(lvv, rvv), nil
+	case cmpNonMembership:
+		return !isMemberOf(lvv, rvv), nil
+	}
+
+	// timestamps and durations are compared with each other (or strings that parse as them)
+	if isTemporal(lvv) || isTemporal(rvv) {
+		if lt, ok := toTime(lvv); ok {
+			if rt, ok := toTime(rvv); ok {
+				return self.compareOrdered(compareInt64(lt.Sub(rt).Nanoseconds(), 0)), nil
+			}
+		} else if ld, ok := toDuration(lvv); ok {
+			if rd, ok := toDuration(rvv); ok {
+				return self.compareOrdered(compareInt64(int64(ld), int64(rd))), nil
+			}
+		}
+
+		switch self {
+		case cmpEquality:
+			return false, nil
+		case cmpNonEquality:
+			return true, nil
+		default:
+			return false, fmt.Errorf("incomparable types %T, %T", lvv, rvv)
+		}
+	}
+
+	// decimals are compared exactly
+	if isDecimal(lvv) || isDecimal(rvv) {
+		if ld, err := ToDecimal(lvv); err == nil {
+			if rd, err := ToDecimal(rvv); err == nil {
+				return self.compareOrdered(ld.Cmp(rd)), nil
+			}
+		}
+	}
+
+	// as are integers
+	if li, ok := toInt64(lvv); ok {
+		if ri, ok := toInt64(rvv); ok {
+			return self.compareOrdered(compareInt64(li, ri)), nil
+		}
+	}
+
 	lv, lverr = stringutil.ConvertToFloat(lvv)
 	rv, rverr = stringutil.ConvertToFloat(rvv)
 
 	switch self {
 	case cmpEquality:
 		if isEmpty(lvv) && isEmpty(rvv) {
-			return true
+			return true, nil
 		} else if res, err := stringutil.RelaxedEqual(lvv, rvv); err == nil {
-			return res
+			return res, nil
 		} else {
-			log.Panicf("incomparable types %T, %T: %v", lvv, rvv, err)
-			return false
+			return false, fmt.Errorf("incomparable types %T, %T: %v", lvv, rvv, err)
 		}
 	case cmpNonEquality:
 		if isEmpty(lvv) && !isEmpty(rvv) {
-			return true
+			return true, nil
 		} else if res, err := stringutil.RelaxedEqual(lvv, rvv); err == nil {
-			return !res
+			return !res, nil
 		} else {
-			log.Panicf("incomparable types %T, %T: %v", lvv, rvv, err)
-			return false
+			return false, fmt.Errorf("incomparable types %T, %T: %v", lvv, rvv, err)
 		}
 
-	case cmpGreaterThan:
+	case cmpGreaterThan, cmpGreaterEqual, cmpLessEqual, cmpLessThan:
 		if lverr == nil && rverr == nil {
-			return (lv > rv)
+			if lv < rv {
+				return self.compareOrdered(-1), nil
+			} else if lv > rv {
+				return self.compareOrdered(1), nil
+			} else {
+				return self.compareOrdered(0), nil
+			}
 		} else {
-			log.Panicf("incomparable types %T, %T", lvv, rvv)
-			return false
+			return false, fmt.Errorf("incomparable types %T, %T", lvv, rvv)
 		}
 
-	case cmpGreaterEqual:
-		if lverr == nil && rverr == nil {
-			return (lv >= rv)
-		} else {
-			log.Panicf("incomparable types %T, %T", lvv, rvv)
-			return false
-		}
+	default:
+		return false, nil
+	}
+}
 
+// interpret the result of a three-way comparison (-1, 0, +1) according to this comparator
+func (self Comparator) compareOrdered(cmp int) bool {
+	switch self {
+	case cmpEquality:
+		return cmp == 0
+	case cmpNonEquality:
+		return cmp != 0
+	case cmpGreaterThan:
+		return cmp > 0
+	case cmpGreaterEqual:
+		return cmp >= 0
 	case cmpLessEqual:
-		if lverr == nil && rverr == nil {
-			return (lv <= rv)
-		} else {
-			log.Panicf("incomparable types %T, %T", lvv, rvv)
-			return false
-		}
-
+		return cmp <= 0
 	case cmpLessThan:
-		if lverr == nil && rverr == nil {
-			return (lv < rv)
-		} else {
-			log.Panicf("incomparable types %T, %T", lvv, rvv)
-			return false
-		}
-
-	case cmpMembership:
-		return isMemberOf(lvv, rvv)
-
-	case cmpNonMembership:
-		return !isMemberOf(lvv, rvv)
-
+		return cmp < 0
 	default:
 		return false
 	}
 }
 
+func compareInt64(a int64, b int64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+
+	return 0
+}
+
 func membershipTest(i int, first interface{}, second interface{}) bool {
 	return fmt.Sprintf("%v", first) == fmt.Sprintf("%v", second)
 }
@@ -3,10 +3,15 @@ package scripting
 import (
 	"fmt"
 	"regexp"
+	"strconv"
 
 	"github.com/PerformLine/go-stockutil/log"
 )
 
+// The name of the variable that regular expression capture groups are bound to when a
+// condition like `if $line =~ /id=(\d+)/ { ... }` matches.
+var RegexMatchVariableName = `match`
+
 type MatchOperator int
 
 const (
@@ -49,3 +54,35 @@ func (self MatchOperator) Evaluate(pattern *regexp.Regexp, want interface{}) boo
 		return !pattern.MatchString(fmt.Sprintf("%v", want))
 	}
 }
+
+// Return the capture groups from the first match of pattern against the given value, keyed by
+// both their positional index (e.g.: "0" for the whole match, "1" for the first group) and, for
+// named groups, their name.  Returns nil if the pattern does not match.
+func (self MatchOperator) Captures(pattern *regexp.Regexp, want interface{}) map[string]interface{} {
+	if v, err := exprToValue(want); err == nil {
+		want = v
+	} else {
+		log.Panicf("malformed expression: %v", err)
+	}
+
+	if groups := pattern.FindStringSubmatch(fmt.Sprintf("%v", want)); groups != nil {
+		return capturesToMap(pattern, groups)
+	}
+
+	return nil
+}
+
+func capturesToMap(pattern *regexp.Regexp, groups []string) map[string]interface{} {
+	var captures = make(map[string]interface{})
+	var names = pattern.SubexpNames()
+
+	for i, group := range groups {
+		captures[strconv.Itoa(i)] = group
+
+		if i < len(names) && names[i] != `` {
+			captures[names[i]] = group
+		}
+	}
+
+	return captures
+}
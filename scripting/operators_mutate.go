@@ -3,10 +3,19 @@ package scripting
 import (
 	"fmt"
 	"math"
+	"strings"
+	"time"
 
+	"github.com/PerformLine/go-stockutil/maputil"
+	"github.com/PerformLine/go-stockutil/sliceutil"
 	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
 )
 
+// The largest string (in bytes) or array (in elements) that can be produced by repeating one with
+// the multiplication operator.
+var MaxRepeatLength int64 = 16 * 1024 * 1024
+
 type operator int
 
 const (
@@ -54,6 +63,66 @@ func parseOperator(node *node32) (operator, error) {
 	}
 }
 
+// Return how tightly the operator binds to its operands relative to the others.
+func (self operator) precedence() int {
+	switch self {
+	case opExponentiate:
+		return 4
+	case opMultiply, opDivide, opModulus:
+		return 3
+	case opAdd, opSubtract:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// evaluate a sequence of operands and the operators between them.  Operators with a higher
+// precedence are applied first, and operators of equal precedence are applied from left to right
+// (except for exponentiation, which is applied from right to left.)
+func evaluateOperators(operands []interface{}, operators []operator) (interface{}, error) {
+	var values = operands[:1:1]
+	var pending = make([]operator, 0, len(operators))
+
+	var reduce = func() error {
+		var op = pending[len(pending)-1]
+		var lhs, rhs = values[len(values)-2], values[len(values)-1]
+
+		pending = pending[:len(pending)-1]
+		values = values[:len(values)-2]
+
+		if result, err := op.evaluate(lhs, rhs); err == nil {
+			values = append(values, result)
+			return nil
+		} else {
+			return err
+		}
+	}
+
+	for i, op := range operators {
+		for len(pending) > 0 {
+			if top := pending[len(pending)-1]; top.precedence() > op.precedence() || (top.precedence() == op.precedence() && op != opExponentiate) {
+				if err := reduce(); err != nil {
+					return nil, err
+				}
+			} else {
+				break
+			}
+		}
+
+		pending = append(pending, op)
+		values = append(values, operands[i+1])
+	}
+
+	for len(pending) > 0 {
+		if err := reduce(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values[0], nil
+}
+
 func (self operator) evaluate(lhs interface{}, rhs interface{}) (interface{}, error) {
 	var lv float64
 	var rv float64
@@ -72,6 +141,28 @@ func (self operator) evaluate(lhs interface{}, rhs interface{}) (interface{}, er
 		return nil, err
 	}
 
+	_, lstr := lhs.(string)
+	_, rstr := rhs.(string)
+
+	// arrays, objects, and repetition of strings
+	if output, ok, err := self.evaluateCollection(lhs, rhs); ok {
+		return output, err
+	}
+
+	// adding anything to a string concatenates them; other operators work on a more specific
+	// type if either side has one
+	if self != opAdd || !(lstr || rstr) {
+		if isTemporal(lhs) || isTemporal(rhs) {
+			return self.evaluateTemporal(lhs, rhs)
+		} else if isDecimal(lhs) || isDecimal(rhs) {
+			return self.evaluateDecimal(lhs, rhs)
+		} else if li, ok := toInt64(lhs); ok {
+			if ri, ok := toInt64(rhs); ok {
+				return self.evaluateInteger(li, ri)
+			}
+		}
+	}
+
 	lv, lverr = stringutil.ConvertToFloat(lhs)
 	rv, rverr = stringutil.ConvertToFloat(rhs)
 
@@ -106,9 +197,6 @@ func (self operator) evaluate(lhs interface{}, rhs interface{}) (interface{}, er
 		output = math.Mod(lv, rv)
 
 	case opAdd:
-		_, lstr := lhs.(string)
-		_, rstr := rhs.(string)
-
 		if lstr || rstr {
 			output = fmt.Sprintf("%v%v", lhs, rhs)
 		} else {
@@ -144,6 +232,399 @@ func (self operator) evaluate(lhs interface{}, rhs interface{}) (interface{}, er
 	}
 }
 
+// perform operations on arrays and objects.  The boolean return value indicates whether the
+// operands were handled here at all.
+func (self operator) evaluateCollection(lhs interface{}, rhs interface{}) (interface{}, bool, error) {
+	var larr = isArray(lhs)
+	var rarr = isArray(rhs)
+
+	switch self {
+	case opAdd:
+		if larr && rarr {
+			return append(sliceutil.Sliceify(lhs), sliceutil.Sliceify(rhs)...), true, nil
+		} else if isObject(lhs) && isObject(rhs) {
+			return deepMerge(toObject(lhs), toObject(rhs)), true, nil
+		}
+
+	case opSubtract:
+		if larr && rarr {
+			var output = make([]interface{}, 0)
+			var exclude = sliceutil.Sliceify(rhs)
+
+			for _, value := range sliceutil.Sliceify(lhs) {
+				if !containsValue(exclude, value) {
+					output = append(output, value)
+				}
+			}
+
+			return output, true, nil
+		}
+
+	case opBitwiseAnd:
+		if larr && rarr {
+			var output = make([]interface{}, 0)
+			var other = sliceutil.Sliceify(rhs)
+
+			for _, value := range sliceutil.Sliceify(lhs) {
+				if containsValue(other, value) && !containsValue(output, value) {
+					output = append(output, value)
+				}
+			}
+
+			return output, true, nil
+		}
+
+	case opBitwiseOr:
+		if larr && rarr {
+			var output = make([]interface{}, 0)
+
+			for _, value := range append(sliceutil.Sliceify(lhs), sliceutil.Sliceify(rhs)...) {
+				if !containsValue(output, value) {
+					output = append(output, value)
+				}
+			}
+
+			return output, true, nil
+		}
+
+	case opMultiply:
+		var subject, times = lhs, rhs
+
+		if _, ok := toInt64(lhs); ok {
+			subject, times = rhs, lhs
+		}
+
+		if n, ok := toInt64(times); ok {
+			if str, ok := subject.(string); ok {
+				if n < 0 {
+					return nil, true, fmt.Errorf("cannot repeat a string a negative number of times")
+				}
+
+				if err := checkRepeatLength(int64(len(str)), n); err != nil {
+					return nil, true, err
+				}
+
+				return strings.Repeat(str, int(n)), true, nil
+			} else if isArray(subject) {
+				if n < 0 {
+					return nil, true, fmt.Errorf("cannot repeat an array a negative number of times")
+				}
+
+				var elements = sliceutil.Sliceify(subject)
+
+				if err := checkRepeatLength(int64(len(elements)), n); err != nil {
+					return nil, true, err
+				} else if len(elements) == 0 {
+					return elements, true, nil
+				}
+
+				var output = make([]interface{}, 0, len(elements)*int(n))
+
+				for i := int64(0); i < n; i++ {
+					output = append(output, elements...)
+				}
+
+				return output, true, nil
+			}
+		}
+	}
+
+	_, lstr := lhs.(string)
+	_, rstr := rhs.(string)
+
+	// strings can still be concatenated with anything
+	if self == opAdd && (lstr || rstr) {
+		return nil, false, nil
+	} else if larr || rarr || isObject(lhs) || isObject(rhs) {
+		return nil, true, fmt.Errorf("unsupported operation: %T %v %T", lhs, self, rhs)
+	}
+
+	return nil, false, nil
+}
+
+// make sure that repeating something of the given length n times won't produce too large a result
+func checkRepeatLength(length int64, n int64) error {
+	if length > 0 && n > MaxRepeatLength/length {
+		return fmt.Errorf("cannot repeat %d times: the result would be longer than %d", n, MaxRepeatLength)
+	}
+
+	return nil
+}
+
+func isArray(in interface{}) bool {
+	if _, ok := in.([]byte); ok {
+		return false
+	}
+
+	return typeutil.IsArray(in)
+}
+
+func isObject(in interface{}) bool {
+	return typeutil.IsMap(in)
+}
+
+func toObject(in interface{}) map[string]interface{} {
+	if m, ok := in.(map[string]interface{}); ok {
+		return m
+	} else if in == nil {
+		return nil
+	}
+
+	return maputil.M(in).MapNative()
+}
+
+// merge two objects together (recursively), with the values in the second taking precedence
+func deepMerge(first map[string]interface{}, second map[string]interface{}) map[string]interface{} {
+	var output = make(map[string]interface{})
+
+	for k, v := range first {
+		output[k] = v
+	}
+
+	for k, v := range second {
+		if current, ok := output[k]; ok && isObject(current) && isObject(v) {
+			output[k] = deepMerge(toObject(current), toObject(v))
+		} else {
+			output[k] = v
+		}
+	}
+
+	return output
+}
+
+func containsValue(haystack []interface{}, needle interface{}) bool {
+	for _, value := range haystack {
+		if eq, err := cmpEquality.Compare(value, needle); err == nil && eq {
+			return true
+		}
+	}
+
+	return false
+}
+
+// perform integer arithmetic, returning an error if the result overflows
+func (self operator) evaluateInteger(lv int64, rv int64) (interface{}, error) {
+	var overflow bool
+	var output int64
+
+	switch self {
+	case opAdd:
+		output = lv + rv
+		overflow = (rv > 0 && output < lv) || (rv < 0 && output > lv)
+
+	case opSubtract:
+		output = lv - rv
+		overflow = (rv > 0 && output > lv) || (rv < 0 && output < lv)
+
+	case opMultiply:
+		output, overflow = multiplyInt64(lv, rv)
+
+	case opDivide:
+		if rv == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		}
+
+		output = lv / rv
+		overflow = (lv == math.MinInt64 && rv == -1)
+
+	case opModulus:
+		if rv == 0 {
+			return nil, fmt.Errorf("cannot divide by zero")
+		} else if rv != -1 {
+			output = lv % rv
+		}
+
+	case opExponentiate:
+		if rv < 0 {
+			return math.Pow(float64(lv), float64(rv)), nil
+		}
+
+		var base = lv
+		output = 1
+
+		// exponentiation by squaring
+		for exp := rv; exp > 0 && !overflow; exp >>= 1 {
+			if exp&1 == 1 {
+				output, overflow = multiplyInt64(output, base)
+			}
+
+			if exp > 1 && !overflow {
+				base, overflow = multiplyInt64(base, base)
+			}
+		}
+
+	case opBitwiseAnd:
+		output = lv & rv
+
+	case opBitwiseOr:
+		output = lv | rv
+
+	case opBitwiseXor:
+		output = lv ^ rv
+
+	default:
+		return nil, fmt.Errorf("operator '%v' not implemented", self)
+	}
+
+	if overflow {
+		return nil, fmt.Errorf("integer overflow: %d %v %d", lv, self, rv)
+	}
+
+	return output, nil
+}
+
+func multiplyInt64(a int64, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+
+	var out = a * b
+
+	if out/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, true
+	}
+
+	return out, false
+}
+
+// perform arithmetic involving decimal values
+func (self operator) evaluateDecimal(lhs interface{}, rhs interface{}) (interface{}, error) {
+	if ld, err := ToDecimal(lhs); err == nil {
+		if rd, err := ToDecimal(rhs); err == nil {
+			switch self {
+			case opAdd:
+				return ld.Add(rd), nil
+			case opSubtract:
+				return ld.Sub(rd), nil
+			case opMultiply:
+				return ld.Mul(rd), nil
+			case opDivide:
+				return ld.Div(rd)
+			case opModulus:
+				return ld.Mod(rd)
+			case opExponentiate:
+				if exp, ok := toInt64(rhs); ok && exp >= 0 {
+					return ld.Pow(exp), nil
+				}
+			}
+
+			return nil, fmt.Errorf("unsupported operation: %T %v %T", lhs, self, rhs)
+		} else {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+}
+
+// convert any Golang integer type into an int64
+func toInt64(in interface{}) (int64, bool) {
+	switch v := in.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint:
+		if uint64(v) <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	}
+
+	return 0, false
+}
+
+func isDecimal(in interface{}) bool {
+	switch in.(type) {
+	case Decimal, *Decimal:
+		return true
+	default:
+		return false
+	}
+}
+
+// perform arithmetic involving timestamps and durations
+func (self operator) evaluateTemporal(lhs interface{}, rhs interface{}) (interface{}, error) {
+	lt, ltime := toTime(lhs)
+	rt, rtime := toTime(rhs)
+	ld, ldur := toDuration(lhs)
+	rd, rdur := toDuration(rhs)
+
+	switch self {
+	case opAdd:
+		if ltime && rdur {
+			return lt.Add(rd), nil
+		} else if ldur && rtime {
+			return rt.Add(ld), nil
+		} else if ldur && rdur {
+			return ld + rd, nil
+		}
+
+	case opSubtract:
+		if ltime && rtime {
+			return lt.Sub(rt), nil
+		} else if ltime && rdur {
+			return lt.Add(-rd), nil
+		} else if ldur && rdur {
+			return ld - rd, nil
+		}
+
+	case opMultiply:
+		if ldur && !rdur && !rtime {
+			if rv, err := stringutil.ConvertToFloat(rhs); err == nil {
+				return time.Duration(float64(ld) * rv), nil
+			}
+		} else if rdur && !ldur && !ltime {
+			if lv, err := stringutil.ConvertToFloat(lhs); err == nil {
+				return time.Duration(lv * float64(rd)), nil
+			}
+		}
+
+	case opDivide:
+		if ldur && rdur {
+			if rd == 0 {
+				return nil, fmt.Errorf("cannot divide by zero")
+			}
+
+			return float64(ld) / float64(rd), nil
+		} else if ldur && !rtime {
+			if rv, err := stringutil.ConvertToFloat(rhs); err == nil {
+				if rv == 0 {
+					return nil, fmt.Errorf("cannot divide by zero")
+				}
+
+				return time.Duration(float64(ld) / rv), nil
+			}
+		}
+
+	case opModulus:
+		if ldur && rdur {
+			if rd == 0 {
+				return nil, fmt.Errorf("cannot divide by zero")
+			}
+
+			return ld % rd, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported operation: %T %v %T", lhs, self, rhs)
+}
+
 func (self operator) String() string {
 	switch self {
 	case opExponentiate:
@@ -0,0 +1,331 @@
+package scripting
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PerformLine/go-stockutil/maputil"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+type pathSegmentType int
+
+const (
+	pathKey pathSegmentType = iota
+	pathSlice
+	pathWildcard
+	pathFilter
+)
+
+// A pathSegment is one part of a variable reference, e.g.: the "users", "[?age > 30]" and "email"
+// in $users[?age > 30].email.
+type pathSegment struct {
+	Type   pathSegmentType
+	Key    string
+	Start  *int64
+	End    *int64
+	Filter *pathPredicate
+}
+
+// A pathPredicate selects the elements of an array (or values of an object) that satisfy a predicate.
+type pathPredicate struct {
+	Field      []string
+	Comparator *Comparator
+	Value      interface{}
+	Pattern    *regexp.Regexp
+	Negate     bool
+}
+
+func (self *pathPredicate) matches(element interface{}) (bool, error) {
+	var operand = element
+
+	if len(self.Field) > 0 {
+		if v, err := applyPath(element, keySegments(self.Field...)); err == nil {
+			operand = v
+		} else {
+			return false, err
+		}
+	}
+
+	if self.Pattern != nil {
+		if isEmpty(operand) {
+			return self.Negate, nil
+		}
+
+		return self.Pattern.MatchString(typeutil.String(operand)) != self.Negate, nil
+	} else if self.Comparator != nil {
+		return self.Comparator.Compare(operand, self.Value)
+	} else {
+		return isTruthy(operand), nil
+	}
+}
+
+func keySegments(keys ...string) []pathSegment {
+	var segments = make([]pathSegment, len(keys))
+
+	for i, key := range keys {
+		segments[i] = pathSegment{
+			Type: pathKey,
+			Key:  key,
+		}
+	}
+
+	return segments
+}
+
+// returns the number of leading segments that can be resolved with a plain dot-separated key
+func simplePrefixLength(segments []pathSegment) int {
+	for i, segment := range segments {
+		if segment.Type != pathKey || strings.HasPrefix(segment.Key, `-`) {
+			return i
+		}
+	}
+
+	return len(segments)
+}
+
+func joinKeys(segments []pathSegment) string {
+	var keys = make([]string, len(segments))
+
+	for i, segment := range segments {
+		keys[i] = segment.Key
+	}
+
+	return strings.Join(keys, `.`)
+}
+
+// Retrieve the value that the given path refers to.  Paths containing wildcards or filters
+// yield an array of all matching values.
+func resolvePath(scope *Scope, segments []pathSegment) (interface{}, error) {
+	var n = simplePrefixLength(segments)
+
+	if n == 0 {
+		return nil, fmt.Errorf("variable reference must start with a name")
+	}
+
+	var value = scope.Get(joinKeys(segments[:n]))
+
+	if n == len(segments) {
+		return value, nil
+	}
+
+	return applyPath(value, segments[n:])
+}
+
+// Return the plain dot-separated keys for every value the given path refers to.  This is used
+// to determine which values to set when a path is the target of an assignment.
+func resolvePathTargets(scope *Scope, segments []pathSegment) ([]string, error) {
+	var n = simplePrefixLength(segments)
+
+	if n == 0 {
+		return nil, fmt.Errorf("variable reference must start with a name")
+	} else if n == len(segments) {
+		return []string{joinKeys(segments)}, nil
+	}
+
+	var prefix = make([]string, n)
+
+	for i, segment := range segments[:n] {
+		prefix[i] = segment.Key
+	}
+
+	return expandPath(prefix, scope.Get(joinKeys(segments[:n])), segments[n:])
+}
+
+func applyPath(value interface{}, segments []pathSegment) (interface{}, error) {
+	for i, segment := range segments {
+		if isEmpty(value) {
+			return nil, nil
+		}
+
+		switch segment.Type {
+		case pathKey:
+			value = indexOf(value, segment.Key)
+
+		case pathSlice:
+			if str, ok := value.(string); ok {
+				var runes = []rune(str)
+				var start, end = sliceBounds(len(runes), segment.Start, segment.End)
+
+				value = string(runes[start:end])
+			} else if isArray(value) {
+				var elements = sliceutil.Sliceify(value)
+				var start, end = sliceBounds(len(elements), segment.Start, segment.End)
+
+				value = elements[start:end]
+			} else {
+				return nil, fmt.Errorf("cannot slice %T", value)
+			}
+
+		case pathWildcard, pathFilter:
+			var output = make([]interface{}, 0)
+			var _, elements = entriesOf(value)
+
+			for _, element := range elements {
+				if segment.Filter != nil {
+					if ok, err := segment.Filter.matches(element); err != nil {
+						return nil, err
+					} else if !ok {
+						continue
+					}
+				}
+
+				// missing values are kept (as null) so that the output lines up with the elements
+				if v, err := applyPath(element, segments[i+1:]); err == nil {
+					output = append(output, v)
+				} else {
+					return nil, err
+				}
+			}
+
+			return output, nil
+		}
+	}
+
+	return value, nil
+}
+
+func expandPath(prefix []string, value interface{}, segments []pathSegment) ([]string, error) {
+	if len(segments) == 0 {
+		return []string{strings.Join(prefix, `.`)}, nil
+	}
+
+	var segment = segments[0]
+	var keys = make([]string, 0)
+	var children []string
+	var elements []interface{}
+
+	switch segment.Type {
+	case pathKey:
+		var key = segment.Key
+
+		if isArray(value) && strings.HasPrefix(key, `-`) {
+			if i, err := strconv.Atoi(key); err == nil {
+				if i += sliceutil.Len(value); i < 0 {
+					return nil, fmt.Errorf("index %v out of range", segment.Key)
+				}
+
+				key = strconv.Itoa(i)
+			}
+		}
+
+		children = []string{key}
+		elements = []interface{}{indexOf(value, key)}
+
+	case pathSlice:
+		if !isArray(value) {
+			return nil, fmt.Errorf("cannot slice %T", value)
+		}
+
+		var start, end = sliceBounds(sliceutil.Len(value), segment.Start, segment.End)
+
+		for i := start; i < end; i++ {
+			children = append(children, strconv.Itoa(i))
+			elements = append(elements, indexOf(value, strconv.Itoa(i)))
+		}
+
+	case pathWildcard, pathFilter:
+		children, elements = entriesOf(value)
+	}
+
+	for i, element := range elements {
+		if segment.Filter != nil {
+			if ok, err := segment.Filter.matches(element); err != nil {
+				return nil, err
+			} else if !ok {
+				continue
+			}
+		}
+
+		if expanded, err := expandPath(append(append([]string{}, prefix...), children[i]), element, segments[1:]); err == nil {
+			keys = append(keys, expanded...)
+		} else {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+// retrieve a single key from an object, or index from an array (negative indices count
+// backwards from the end of the array).
+func indexOf(value interface{}, key string) interface{} {
+	if isArray(value) {
+		if i, err := strconv.Atoi(key); err == nil {
+			var elements = sliceutil.Sliceify(value)
+
+			if i < 0 {
+				i += len(elements)
+			}
+
+			if i >= 0 && i < len(elements) {
+				return elements[i]
+			}
+		}
+
+		return nil
+	} else if isObject(value) {
+		return toObject(value)[key]
+	} else {
+		return nil
+	}
+}
+
+// return the keys and values of an object (sorted by key), or the indices and elements of an array
+func entriesOf(value interface{}) ([]string, []interface{}) {
+	var keys = make([]string, 0)
+	var values = make([]interface{}, 0)
+
+	if isArray(value) {
+		for i, element := range sliceutil.Sliceify(value) {
+			keys = append(keys, strconv.Itoa(i))
+			values = append(values, element)
+		}
+	} else if isObject(value) {
+		var obj = toObject(value)
+
+		keys = maputil.StringKeys(obj)
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			values = append(values, obj[key])
+		}
+	}
+
+	return keys, values
+}
+
+// resolve (possibly negative or missing) slice bounds against a length, the same way Python does
+func sliceBounds(length int, start *int64, end *int64) (int, int) {
+	var bound = func(b *int64, fallback int) int {
+		if b == nil {
+			return fallback
+		}
+
+		var i = int(*b)
+
+		if i < 0 {
+			i += length
+		}
+
+		if i < 0 {
+			return 0
+		} else if i > length {
+			return length
+		}
+
+		return i
+	}
+
+	var s, e = bound(start, 0), bound(end, length)
+
+	if s > e {
+		s = e
+	}
+
+	return s, e
+}
@@ -2,21 +2,18 @@ package scripting
 
 import (
 	"encoding/json"
-	"regexp"
+	"fmt"
 	"strings"
 	"sync"
 
 	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/maputil"
-	"github.com/PerformLine/go-stockutil/rxutil"
 	"github.com/PerformLine/go-stockutil/sliceutil"
 	"github.com/PerformLine/go-stockutil/stringutil"
 	"github.com/PerformLine/go-stockutil/typeutil"
 )
 
-var rxInterpolate = regexp.MustCompile(`({[^}]+})`)
 var placeholderVarName = `_`
-var maxInterpolateSequences = 64
 
 // This represents the name of a module whose commands do not need to be qualified with
 // a "name::" prefix.
@@ -24,12 +21,39 @@ var UnqualifiedModuleName = `core`
 
 type tracer int
 
+// Wraps a value that should be stored as a read-only variable when given to Scope.Set or
+// Scope.TrySet (and by extension, to NewEnvironment or Environment.SetData.)
+type ConstantValue struct {
+	Value interface{}
+}
+
+// Mark the given value as read-only, e.g.:
+// env.SetData(map[string]interface{}{`api_base`: scripting.Constant(`https://example.com`)})
+func Constant(value interface{}) *ConstantValue {
+	return &ConstantValue{
+		Value: value,
+	}
+}
+
+// Returned when attempting to assign a new value to a read-only variable.
+type ReadOnlyError struct {
+	Key string
+}
+
+func (self *ReadOnlyError) Error() string {
+	return fmt.Sprintf("cannot assign to constant $%s", self.Key)
+}
+
 type Scope struct {
 	parent         *Scope
 	data           map[string]interface{}
 	isolatedReads  bool
 	isolatedWrites bool
 	mostRecentKey  string
+	returnValue    interface{}
+	hasReturned    bool
+	readonly       map[string]bool
+	enclosing      *Scope
 	evallock       sync.Mutex
 	ctx            *Context
 }
@@ -45,6 +69,7 @@ func NewEphemeralScope(parent *Scope) *Scope {
 	scope := NewScope(nil)
 	scope.isolatedReads = false
 	scope.isolatedWrites = true
+	scope.enclosing = parent
 	return scope
 }
 
@@ -71,6 +96,24 @@ func (self *Scope) MostRecentValue() interface{} {
 	return self.Get(self.mostRecentKey)
 }
 
+// Record the value given to a "return" statement that ended the evaluation of a script in this scope.
+func (self *Scope) SetReturnValue(value interface{}) {
+	self.returnValue = value
+	self.hasReturned = true
+}
+
+// Forget any value recorded by SetReturnValue.
+func (self *Scope) ClearReturnValue() {
+	self.returnValue = nil
+	self.hasReturned = false
+}
+
+// Return the value given to the "return" statement that ended the evaluation of a script in this
+// scope, and whether evaluation was ended by a "return" statement at all.
+func (self *Scope) ReturnValue() (interface{}, bool) {
+	return self.returnValue, self.hasReturned
+}
+
 // Sets the scope evaluation lock and store the given context.
 func (self *Scope) LockContext(ctx *Context) {
 	self.evallock.Lock()
@@ -129,15 +172,84 @@ func (self *Scope) Declare(key string) {
 	var e emptyValue
 	key = self.prepVariableName(key)
 
+	// constants already occupy their key
+	if self.readonly[rootVariableName(key)] {
+		return
+	}
+
 	// log.Infof("DECL scope(%d)[%v]", self.Level(), key)
 	maputil.DeepSet(self.data, strings.Split(key, `.`), e)
 }
 
+// Set the value of the given key in the scope that owns it (see OwnerOf).  Values wrapped with
+// Constant are stored as read-only variables in this scope (see SetConstant).  Values that can't be
+// set (e.g.: because the key refers to a read-only variable) are logged and skipped; use TrySet to
+// have the error returned instead.
 func (self *Scope) Set(key string, value interface{}) {
+	if err := self.TrySet(key, value); err != nil {
+		log.Warningf("not setting $%s: %v", self.prepVariableName(key), err)
+	}
+}
+
+// Set the value of the given key in the same way as Set, returning an error if the key refers to a
+// read-only variable or the value could not be set.
+func (self *Scope) TrySet(key string, value interface{}) error {
+	if constant, ok := value.(*ConstantValue); ok {
+		return self.SetConstant(key, constant.Value)
+	}
+
 	key = self.prepVariableName(key)
-	scope := self.OwnerOf(key)
-	scope.set(key, value)
+
+	if self.IsReadOnly(key) {
+		return &ReadOnlyError{
+			Key: key,
+		}
+	}
+
+	if err := self.OwnerOf(key).set(key, value); err != nil {
+		return err
+	}
+
+	self.mostRecentKey = key
+	return nil
+}
+
+// Set the value of the given top-level variable in this scope, and prevent it from being assigned
+// to again in this scope or any scope descended from it.
+func (self *Scope) SetConstant(key string, value interface{}) error {
+	key = self.prepVariableName(key)
+
+	if key == `` || key == placeholderVarName || rootVariableName(key) != key {
+		return fmt.Errorf("cannot declare $%s as a constant: only top-level variables can be constants", key)
+	} else if self.IsReadOnly(key) {
+		return &ReadOnlyError{
+			Key: key,
+		}
+	} else if err := self.set(key, value); err != nil {
+		return err
+	}
+
+	if self.readonly == nil {
+		self.readonly = make(map[string]bool)
+	}
+
+	self.readonly[key] = true
 	self.mostRecentKey = key
+	return nil
+}
+
+// Return whether the given key (or the variable it belongs to) is read-only in this scope.
+// Constants are enforced in all descendant scopes, including isolated and ephemeral ones.
+func (self *Scope) IsReadOnly(key string) bool {
+	var name = rootVariableName(self.prepVariableName(key))
+
+	for scope := self; scope != nil; scope = scope.outer() {
+		if scope.readonly[name] {
+			return true
+		}
+	}
+
+	return false
 }
 
 func (self *Scope) Get(key string, fallback ...interface{}) interface{} {
@@ -177,9 +289,13 @@ func (self *Scope) IsLocal(key string) bool {
 	return true
 }
 
-func (self *Scope) set(key string, value interface{}) {
+func (self *Scope) set(key string, value interface{}) error {
 	if key == `` || key == placeholderVarName {
-		return
+		return nil
+	} else if self.readonly[rootVariableName(key)] {
+		return &ReadOnlyError{
+			Key: key,
+		}
 	}
 
 	if isEmpty(value) {
@@ -187,7 +303,7 @@ func (self *Scope) set(key string, value interface{}) {
 	} else if v, err := exprToValue(value); err == nil {
 		value = v
 	} else {
-		log.Panicf("Cannot set %v: %v", key, err)
+		return fmt.Errorf("Cannot set %v: %v", key, err)
 	}
 
 	value = intIfYouCan(value)
@@ -202,6 +318,7 @@ func (self *Scope) set(key string, value interface{}) {
 	// }
 
 	maputil.DeepSet(self.data, strings.Split(key, `.`), value)
+	return nil
 }
 
 func (self *Scope) get(key string, fallback ...interface{}) (interface{}, *Scope) {
@@ -236,25 +353,24 @@ func (self *Scope) get(key string, fallback ...interface{}) (interface{}, *Scope
 	}
 }
 
+// Expand all interpolation sequences in the given string using values from this scope.  Sequences
+// that fail to evaluate are replaced with an empty string; use CompileTemplate(in).Render(scope) to
+// have the error returned instead.
 func (self *Scope) Interpolate(in string) string {
-	for i := 0; i < maxInterpolateSequences; i++ {
-		if match := rxutil.Match(rxInterpolate, in); match != nil {
-			seq := match.Group(1)
-			seq = stringutil.Unwrap(seq, `{`, `}`)
-
-			value := self.Get(seq)
+	if !strings.Contains(in, `{`) {
+		return in
+	}
 
-			if isEmpty(value) {
-				value = ``
-			}
+	return CompileTemplate(in).render(self)
+}
 
-			in = match.ReplaceGroup(1, typeutil.String(value))
-		} else {
-			break
-		}
+// the scope that read-only variables are inherited from
+func (self *Scope) outer() *Scope {
+	if self.parent != nil {
+		return self.parent
 	}
 
-	return in
+	return self.enclosing
 }
 
 func (self *Scope) prepVariableName(key string) string {
@@ -263,6 +379,12 @@ func (self *Scope) prepVariableName(key string) string {
 	return key
 }
 
+// return the name of the top-level variable the given key belongs to
+func rootVariableName(key string) string {
+	name, _ := stringutil.SplitPair(key, `.`)
+	return name
+}
+
 func isEmpty(in interface{}) bool {
 	if in == nil {
 		return true
@@ -1,6 +1,8 @@
 package scripting
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -20,3 +22,167 @@ func TestInterpolate(t *testing.T) {
 	assert.Equal(int(15155870), scope.Get(`a`))
 	assert.Equal(`test test 1 2 3 15155870`, scope.Interpolate(`test test {x} {y} {z} {a}`))
 }
+
+func TestInterpolateExpressions(t *testing.T) {
+	assert := require.New(t)
+	scope := NewScope(nil)
+	scope.Set(`name`, `friend`)
+	scope.Set(`price`, 10)
+	scope.Set(`items`, []interface{}{`a`, `b`, `c`})
+	scope.Set(`user`, map[string]interface{}{
+		`name`:  `  Someone  `,
+		`email`: nil,
+		`tags`:  []interface{}{`x`, `y`},
+	})
+
+	assert.Equal(`hello FRIEND`, scope.Interpolate(`hello {name | upper}`))
+	assert.Equal(`Total: 12.00`, scope.Interpolate(`Total: {price * 1.2:%.2f}`))
+	assert.Equal(`3 items`, scope.Interpolate(`{items | length} items`))
+	assert.Equal(`a, b, c`, scope.Interpolate(`{items | join(', ')}`))
+	assert.Equal(`b`, scope.Interpolate(`{items[1]}`))
+	assert.Equal(`y`, scope.Interpolate(`{user.tags[1]}`))
+	assert.Equal(`y`, scope.Interpolate(`{user[tags][1]}`))
+	assert.Equal(`c`, scope.Interpolate(`{items[-1]}`))
+	assert.Equal(`b,c`, scope.Interpolate(`{items[1:] | join(',')}`))
+	assert.Equal(`fri`, scope.Interpolate(`{name[:3]}`))
+	assert.Equal(`x`, scope.Interpolate(`{user.tags[?@ =~ /x/] | join('')}`))
+	assert.Equal(`someone`, scope.Interpolate(`{user.name | trim | lower}`))
+	assert.Equal(`none`, scope.Interpolate(`{user.email ?? 'none'}`))
+	assert.Equal(`none`, scope.Interpolate(`{user.email | default('none')}`))
+	assert.Equal(`ten`, scope.Interpolate(`{price == 10 ? 'ten' : 'other'}`))
+	assert.Equal(`cheap`, scope.Interpolate(`{price > 100 ? 'expensive' : 'cheap'}`))
+	assert.Equal(`f-word`, scope.Interpolate(`{name =~ /^f/ ? 'f-word' : 'other'}`))
+	assert.Equal(`missing`, scope.Interpolate(`{user.email ? user.email : 'missing'}`))
+	assert.Equal(`010`, scope.Interpolate(`{price > 1 ? price : 0:%03d}`))
+	assert.Equal(`16`, scope.Interpolate(`{(price - 2) * 2}`))
+	assert.Equal(`100`, scope.Interpolate(`{price ** 2}`))
+	assert.Equal(`0042`, scope.Interpolate(`{42:%04d}`))
+	assert.Equal(`["a","b","c"]`, scope.Interpolate(`{items | json}`))
+	assert.Equal(`{name} is friend`, scope.Interpolate(`{{name} is {name}`))
+	assert.Equal(`friend}}`, scope.Interpolate(`{name}}}`))
+	assert.Equal(`5 10`, scope.Interpolate(`{price - 2 - 3} {2 * 3 + 4}`))
+	assert.Equal(``, scope.Interpolate(`{nope}`))
+	assert.Equal(`x`, scope.Interpolate(`{name | nope}x`))
+
+	_, err := CompileTemplate(`{name | nope}x`).Render(scope)
+	assert.Error(err)
+
+	// keys that look like expressions still resolve to the variable they name
+	scope.Set(`some-key`, `value`)
+	assert.Equal(`value`, scope.Interpolate(`{some-key}`))
+
+	// more than 64 sequences
+	var in, out string
+
+	for i := 0; i < 100; i++ {
+		in += `{price}`
+		out += `10`
+	}
+
+	assert.Equal(out, scope.Interpolate(in))
+
+	RegisterTemplateFilter(`reverse`, func(value interface{}, _ ...interface{}) (interface{}, error) {
+		var runes = []rune(value.(string))
+
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+
+		return string(runes), nil
+	})
+
+	assert.Equal(`dneirf`, scope.Interpolate(`{name | reverse}`))
+}
+
+func TestScopeConstants(t *testing.T) {
+	assert := require.New(t)
+	scope := NewScope(nil)
+
+	assert.NoError(scope.TrySet(`api`, Constant(`https://example.com`)))
+	assert.NoError(scope.SetConstant(`retries`, 3))
+	assert.NoError(scope.TrySet(`other`, 1))
+
+	assert.Equal(`https://example.com`, scope.Get(`api`))
+	assert.Equal(3, scope.Get(`retries`))
+	assert.True(scope.IsReadOnly(`api`))
+	assert.True(scope.IsReadOnly(`$retries`))
+	assert.False(scope.IsReadOnly(`other`))
+
+	err := scope.TrySet(`api`, `nope`)
+	assert.Error(err)
+	assert.IsType(&ReadOnlyError{}, err)
+	assert.NoError(scope.TrySet(`other`, 2))
+	assert.Error(scope.SetConstant(`api`, `nope`))
+	assert.Error(scope.SetConstant(`config.key`, true))
+	assert.Error(scope.set(`retries`, 4))
+
+	// constants cannot be changed or shadowed from any descendant scope
+	for _, child := range []*Scope{
+		NewScope(scope),
+		NewIsolatedScope(scope),
+		NewEphemeralScope(scope),
+		NewScope(NewScope(scope)),
+	} {
+		assert.True(child.IsReadOnly(`api`))
+		assert.Error(child.TrySet(`api`, `nope`))
+		assert.Error(child.TrySet(`api.path`, `/nope`))
+		assert.NoError(child.TrySet(`other`, 3))
+	}
+
+	// declaring a constant does not clear it
+	scope.Declare(`api`)
+	assert.Equal(`https://example.com`, scope.Get(`api`))
+}
+
+func TestTemplateCache(t *testing.T) {
+	assert := require.New(t)
+
+	defer func(max int) {
+		MaxCachedTemplates = max
+	}(MaxCachedTemplates)
+
+	MaxCachedTemplates = 2
+	templateCache = newTemplateLRU()
+
+	var a = CompileTemplate(`{a}`)
+	CompileTemplate(`{b}`)
+
+	// using a template keeps it in the cache when others are added
+	assert.True(a == CompileTemplate(`{a}`))
+	CompileTemplate(`{c}`)
+
+	assert.Equal(2, templateCache.len())
+	assert.True(a == CompileTemplate(`{a}`))
+
+	_, ok := templateCache.get(`{b}`)
+	assert.False(ok)
+
+	_, ok = templateCache.get(`{c}`)
+	assert.True(ok)
+}
+
+func TestTemplateFiltersConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	var scope = NewScope(nil)
+
+	scope.Set(`name`, `friend`)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			RegisterTemplateFilter(fmt.Sprintf("noop%d", i), func(value interface{}, _ ...interface{}) (interface{}, error) {
+				return value, nil
+			})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			scope.Interpolate(`{name | upper}`)
+		}()
+	}
+
+	wg.Wait()
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/fatih/structs"
 	"github.com/PerformLine/go-stockutil/maputil"
@@ -88,10 +89,6 @@ func (self *Friendscript) Scope() *Scope {
 	return self.runtime.scope
 }
 
-func (self *Friendscript) lcp() string {
-	return stringutil.LongestCommonPrefix(strings.Split(self.Buffer, "\n"))
-}
-
 func (self *Friendscript) errorWithContext(err error) error {
 	raw := strings.TrimSpace(err.Error())
 
@@ -135,7 +132,7 @@ func (self *Friendscript) Blocks() []*Block {
 		// fmt.Printf("[% 2d] %v%v %q\n", depth, strings.Repeat(`  `, depth), node, self.s(node))
 
 		switch node.rule() {
-		case ruleStatementBlock:
+		case ruleStatementBlock, ruleFlowControlWord:
 			blocks = append(blocks, &Block{
 				friendscript: self,
 				node:         node,
@@ -178,6 +175,41 @@ func (self *node32) firstChild(anyOf ...pegRule) *node32 {
 	return self.firstN(0, anyOf...)
 }
 
+// Return the first immediate child of this node matching any of the given rules.  Unlike
+// firstChild, this never considers the siblings of this node (or their children).
+func (self *node32) directChild(anyOf ...pegRule) *node32 {
+	for child := self.up; child != nil; child = child.next {
+		switch child.rule() {
+		case rule_, rule__:
+			continue
+		}
+
+		if len(anyOf) == 0 || sliceutil.Contains(anyOf, child.rule()) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// Return all immediate children of this node matching any of the given rules.
+func (self *node32) directChildren(anyOf ...pegRule) []*node32 {
+	var results = make([]*node32, 0)
+
+	for child := self.up; child != nil; child = child.next {
+		switch child.rule() {
+		case rule_, rule__:
+			continue
+		}
+
+		if len(anyOf) == 0 || sliceutil.Contains(anyOf, child.rule()) {
+			results = append(results, child)
+		}
+	}
+
+	return results
+}
+
 func (self *node32) find(anyOf ...pegRule) []*node32 {
 	return self.findN(-1, anyOf...)
 }
@@ -295,9 +327,14 @@ func debugNode(friendscript *Friendscript, node *node32) {
 	}
 }
 
-// return int or int64 when appropriate
+// Return whole numbers as an int (or int64 if they don't fit), so that integers have the same type
+// whether they are stored in a variable directly or inside of an array or object.
 func intIfYouCan(in interface{}) interface{} {
-	if oF, ok := in.(float64); ok {
+	if i64, ok := in.(int64); ok {
+		if i64 == int64(int(i64)) {
+			return int(i64)
+		}
+	} else if oF, ok := in.(float64); ok {
 		if oF == float64(int(oF)) {
 			return int(oF)
 
@@ -313,11 +350,17 @@ func intIfYouCan(in interface{}) interface{} {
 func mapifyStruct(in interface{}) interface{} {
 	maputil.UnmarshalStructTag = `json`
 
-	if m, ok := in.(mappable); ok {
+	if _, ok := in.(Iterator); ok {
+		return in
+	} else if m, ok := in.(mappable); ok {
 		return m.ToMap()
 
 	} else if b, ok := in.([]byte); ok {
 		return b
+	} else if t, ok := in.(time.Time); ok {
+		return t
+	} else if d, ok := in.(Decimal); ok {
+		return d
 	} else if typeutil.IsArray(in) {
 		var elems = make([]interface{}, sliceutil.Len(in))
 
@@ -2,10 +2,10 @@ package scripting
 
 import (
 	"fmt"
-	"go/constant"
-	"go/token"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/stringutil"
@@ -27,6 +27,12 @@ const (
 	CommandStatement
 	ConditionalStatement
 	LoopStatement
+	SwitchStatement
+	DeferStatement
+	RetryStatement
+	TimeoutStatement
+	ParamStatement
+	UseStatement
 	FlowControlStatement
 	NoOpStatement
 )
@@ -45,6 +51,18 @@ func (self StatementType) String() string {
 		return `ConditionalStatement`
 	case LoopStatement:
 		return `LoopStatement`
+	case SwitchStatement:
+		return `SwitchStatement`
+	case DeferStatement:
+		return `DeferStatement`
+	case RetryStatement:
+		return `RetryStatement`
+	case TimeoutStatement:
+		return `TimeoutStatement`
+	case ParamStatement:
+		return `ParamStatement`
+	case UseStatement:
+		return `UseStatement`
 	case FlowControlStatement:
 		return `FlowControlStatement`
 	case NoOpStatement:
@@ -77,50 +95,45 @@ func (self *Statement) raw(node *node32) string {
 	return self.Script().s(node)
 }
 
-func (self *Statement) s(node *node32) string {
+func (self *Statement) s(node *node32) (string, error) {
 	if node != nil {
 		raw := self.raw(node)
 
 		if child := node.firstChild(); child != nil {
 			switch child.rule() {
 			case ruleIdentifier:
-				return raw
+				return raw, nil
 
 			case ruleStringLiteral:
 				raw = strings.TrimPrefix(raw, `'`)
 				raw = strings.TrimSuffix(raw, `'`)
-				return raw
+				return raw, nil
 
-			case ruleStringInterpolated:
-				// use Golang's own string literal interpreter to handle escape sequences
-				// and unicode code points in the incoming string
-				raw = constant.StringVal(constant.MakeFromLiteral(raw, token.STRING, 0))
-
-				return self.Script().Scope().Interpolate(raw)
-
-			case ruleTriquote:
-				raw = self.raw(child.firstChild(ruleTriquoteBody))
-				lcp := self.Script().lcp()
+			case ruleStringRaw:
+				raw = strings.TrimPrefix(raw, "`")
+				raw = strings.TrimSuffix(raw, "`")
+				return raw, nil
 
-				if lcp != `` {
-					lines := strings.Split(raw, "\n")
-
-					for i, line := range lines {
-						lines[i] = strings.TrimPrefix(line, lcp)
-					}
+			case ruleStringInterpolated:
+				raw = strings.TrimPrefix(raw, `"`)
+				raw = unescapeString(strings.TrimSuffix(raw, `"`))
 
-					raw = strings.Join(lines, "\n")
+				if !strings.Contains(raw, `{`) {
+					return raw, nil
 				}
 
-				return raw
+				return CompileTemplate(raw).Render(self.Script().Scope())
+
+			case ruleTriquote:
+				return dedent(self.raw(child.firstChild(ruleTriquoteBody))), nil
 
 			default:
-				return raw
+				return raw, nil
 			}
 		}
 	}
 
-	return ``
+	return ``, nil
 }
 
 func (self *Statement) Type() StatementType {
@@ -128,7 +141,7 @@ func (self *Statement) Type() StatementType {
 		switch self.node.rule() {
 		case ruleSEMI:
 			return NoOpStatement
-		case ruleAssignment:
+		case ruleAssignment, ruleConstant:
 			return AssignmentStatement
 		case ruleDirective:
 			return DirectiveStatement
@@ -140,6 +153,18 @@ func (self *Statement) Type() StatementType {
 			return CommandStatement
 		case ruleConditional:
 			return ConditionalStatement
+		case ruleSwitch:
+			return SwitchStatement
+		case ruleDefer:
+			return DeferStatement
+		case ruleRetry:
+			return RetryStatement
+		case ruleTimeout:
+			return TimeoutStatement
+		case ruleParam:
+			return ParamStatement
+		case ruleUse:
+			return UseStatement
 		}
 	}
 
@@ -185,6 +210,45 @@ func (self *Statement) Conditional() *Conditional {
 	return nil
 }
 
+func (self *Statement) Switch() *Switch {
+	if self.Type() == SwitchStatement {
+		return &Switch{
+			statement: self,
+		}
+	}
+
+	return nil
+}
+
+// Return the blocks of a defer statement, which are evaluated when the enclosing script exits.
+func (self *Statement) DeferredBlocks() []*Block {
+	if self.Type() == DeferStatement {
+		return directBlocks(self, self.node)
+	}
+
+	return make([]*Block, 0)
+}
+
+func (self *Statement) Retry() *Retry {
+	if self.Type() == RetryStatement {
+		return &Retry{
+			statement: self,
+		}
+	}
+
+	return nil
+}
+
+func (self *Statement) Timeout() *Timeout {
+	if self.Type() == TimeoutStatement {
+		return &Timeout{
+			statement: self,
+		}
+	}
+
+	return nil
+}
+
 func (self *Statement) Loop() *Loop {
 	if self.Type() == LoopStatement {
 		return &Loop{
@@ -202,10 +266,10 @@ func (self *Statement) parseObject(node *node32) (map[string]interface{}, error)
 	if node != nil {
 		if pairs := node.children(ruleKeyValuePair); len(pairs) > 0 {
 			for _, pair := range pairs {
-				key := self.s(pair.first(ruleKey))
-
-				if value, err := self.parseValue(pair.first(ruleKValue)); err == nil {
-					output[key] = value
+				if key, err := self.s(pair.first(ruleKey)); err != nil {
+					return nil, err
+				} else if value, err := self.parseValue(pair.first(ruleKValue)); err == nil {
+					output[key] = intIfYouCan(value)
 				} else {
 					return nil, err
 				}
@@ -218,28 +282,50 @@ func (self *Statement) parseObject(node *node32) (map[string]interface{}, error)
 
 func (self *Statement) parseRegex(node *node32) (*regexp.Regexp, error) {
 	if node.rule() == ruleRegularExpression {
-		rx := self.raw(node)
+		return compileRegexLiteral(self.raw(node))
+	} else {
+		return nil, fmt.Errorf("not a regex node")
+	}
+}
 
-		if strings.HasPrefix(rx, `/`) {
-			rx = strings.TrimPrefix(rx, `/`)
-			flags := ``
+// compile a regular expression literal in the form /pattern/flags
+func compileRegexLiteral(rx string) (*regexp.Regexp, error) {
+	if strings.HasPrefix(rx, `/`) {
+		rx = strings.TrimPrefix(rx, `/`)
+		flags := ``
 
-			if i := strings.LastIndex(rx, `/`); i > 0 {
-				flags = strings.TrimPrefix(rx[i:], `/`)
-				rx = rx[:i]
-			}
+		if i := strings.LastIndex(rx, `/`); i > 0 {
+			flags = strings.TrimPrefix(rx[i:], `/`)
+			rx = rx[:i]
+		}
 
-			for _, flag := range flags {
-				rx = `(?` + string(flag) + `)` + rx
+		for _, flag := range flags {
+			// the "g" (global) flag is not a Golang regexp flag; it changes how many
+			// matches are consumed by the statement using the expression
+			if flag == 'g' {
+				continue
 			}
 
-			return regexp.Compile(rx)
-		} else {
-			return nil, fmt.Errorf("malformed regex")
+			rx = `(?` + string(flag) + `)` + rx
 		}
+
+		return regexp.Compile(rx)
 	} else {
-		return nil, fmt.Errorf("not a regex node")
+		return nil, fmt.Errorf("malformed regex")
+	}
+}
+
+// Return whether the given regular expression node has the "g" (global) flag set.
+func (self *Statement) isGlobalRegex(node *node32) bool {
+	if node != nil && node.rule() == ruleRegularExpression {
+		rx := self.raw(node)
+
+		if i := strings.LastIndex(rx, `/`); i > 0 {
+			return strings.Contains(rx[i:], `g`)
+		}
 	}
+
+	return false
 }
 
 func (self *Statement) parseArray(node *node32) ([]interface{}, error) {
@@ -249,7 +335,7 @@ func (self *Statement) parseArray(node *node32) ([]interface{}, error) {
 		if seq := node.first(ruleExpressionSequence); seq != nil {
 			for i, exprNode := range seq.children(ruleExpression) {
 				if value, err := NewExpression(self, exprNode).Value(); err == nil {
-					output = append(output, value)
+					output = append(output, intIfYouCan(value))
 				} else {
 					return nil, fmt.Errorf("index %d: %v", i, err)
 				}
@@ -265,6 +351,11 @@ func (self *Statement) parseValue(node *node32) (interface{}, error) {
 		ruleArray,
 		ruleObject,
 		ruleExpression,
+		ruleRegularExpression,
+		ruleTimestamp,
+		ruleDuration,
+		ruleByteSize,
+		ruleNow,
 		ruleScalarType,
 	)
 
@@ -274,7 +365,7 @@ func (self *Statement) parseValue(node *node32) (interface{}, error) {
 
 	switch value.rule() {
 	case ruleIdentifier:
-		return self.s(node), nil
+		return self.s(node)
 
 	case ruleExpression:
 		return NewExpression(self, value).Value()
@@ -282,8 +373,9 @@ func (self *Statement) parseValue(node *node32) (interface{}, error) {
 	case ruleScalarType:
 		value = value.first(
 			ruleNullValue,
-			ruleInteger,
+			ruleDecimal,
 			ruleFloat,
+			ruleInteger,
 			ruleBoolean,
 			ruleString,
 		)
@@ -305,13 +397,35 @@ func (self *Statement) parseValue(node *node32) (interface{}, error) {
 		return new(emptyValue), nil
 
 	case ruleInteger:
-		return stringutil.MustInteger(self.raw(value)), nil
+		if v, err := strconv.ParseInt(self.raw(value), 10, 64); err == nil {
+			return v, nil
+		} else {
+			return nil, fmt.Errorf("invalid integer %v: %v", self.raw(value), err)
+		}
 
 	case ruleFloat:
 		return stringutil.MustFloat(self.raw(value)), nil
 
+	case ruleDecimal:
+		return ParseDecimal(self.raw(value))
+
 	case ruleString:
-		return self.s(value), nil
+		return self.s(value)
+
+	case ruleTimestamp:
+		return ParseTimestamp(self.raw(value))
+
+	case ruleDuration:
+		return ParseDuration(self.raw(value))
+
+	case ruleByteSize:
+		return ParseByteSize(self.raw(value))
+
+	case ruleNow:
+		return time.Now(), nil
+
+	case ruleRegularExpression:
+		return self.parseRegex(value)
 
 	case ruleArray:
 		return self.parseArray(value)
@@ -325,54 +439,161 @@ func (self *Statement) parseValue(node *node32) (interface{}, error) {
 }
 
 func (self *Statement) resolveVariableKey(node *node32) (string, error) {
+	if targets, err := self.resolveVariableTargets(node); err == nil {
+		switch len(targets) {
+		case 0:
+			return ``, nil
+		case 1:
+			return targets[0], nil
+		default:
+			return ``, fmt.Errorf("variable %v refers to more than one value", self.raw(node))
+		}
+	} else {
+		return ``, err
+	}
+}
+
+// Return the keys of all values the given variable refers to.
+func (self *Statement) resolveVariableTargets(node *node32) ([]string, error) {
+	if segments, err := self.resolveVariableSegments(node); err == nil {
+		if len(segments) == 0 {
+			return []string{``}, nil
+		}
+
+		return resolvePathTargets(self.Script().Scope(), segments)
+	} else {
+		return nil, err
+	}
+}
+
+func (self *Statement) resolveVariableSegments(node *node32) ([]pathSegment, error) {
 	if node.rule() == ruleVariable {
 		child := node.firstChild()
-		keyparts := make([]string, 0)
+		segments := make([]pathSegment, 0)
 
 		switch child.rule() {
 		case ruleVariableNameSequence:
-			nameNodes := child.children(ruleVariableName)
-
-			for _, varpart := range nameNodes {
-				identNode := varpart.firstChild(ruleIdentifier)
-				ident := self.raw(identNode)
-				keyparts = append(keyparts, ident)
-
-				if index := identNode.firstChild(ruleVariableIndex); index != nil {
-					if indexNode := index.firstChild(ruleExpression); indexNode != nil {
-						if indexValue, err := NewExpression(self, indexNode).Value(); err == nil {
-							keyparts = append(keyparts, fmt.Sprintf("%v", indexValue))
-						} else {
-							return ``, err
-						}
+			for _, varpart := range child.directChildren(ruleVariableName) {
+				segments = append(segments, pathSegment{
+					Type: pathKey,
+					Key:  self.raw(varpart.directChild(ruleIdentifier)),
+				})
+
+				for _, index := range varpart.directChildren(ruleVariableIndex) {
+					if segment, err := self.parseVariableIndex(index.directChild()); err == nil {
+						segments = append(segments, segment)
 					} else {
-						return ``, fmt.Errorf("expected expression for index key")
+						return nil, err
 					}
 				}
 			}
 
-			return strings.Join(keyparts, `.`), nil
+			return segments, nil
 
 		case ruleSKIPVAR:
-			return ``, nil
+			return nil, nil
 
 		default:
-			return ``, fmt.Errorf("invalid variable usage '%v'", self.raw(node))
+			return nil, fmt.Errorf("invalid variable usage '%v'", self.raw(node))
 		}
 	} else {
-		return ``, fmt.Errorf("expected variable, got %v", node)
+		return nil, fmt.Errorf("expected variable, got %v", node)
+	}
+}
+
+func (self *Statement) parseVariableIndex(node *node32) (pathSegment, error) {
+	if node == nil {
+		return pathSegment{}, fmt.Errorf("expected expression for index key")
+	}
+
+	switch node.rule() {
+	case ruleVariableSlice:
+		var segment = pathSegment{
+			Type: pathSlice,
+		}
+
+		for _, bound := range node.directChildren(ruleVariableSliceStart, ruleVariableSliceEnd) {
+			if value, err := NewExpression(self, bound.directChild(ruleExpression)).Value(); err == nil {
+				if i, ok := toInt64(value); ok {
+					if bound.rule() == ruleVariableSliceStart {
+						segment.Start = &i
+					} else {
+						segment.End = &i
+					}
+				} else {
+					return pathSegment{}, fmt.Errorf("slice bounds must be integers, got %T", value)
+				}
+			} else {
+				return pathSegment{}, err
+			}
+		}
+
+		return segment, nil
+
+	case ruleVariableWildcard:
+		return pathSegment{
+			Type: pathWildcard,
+		}, nil
+
+	case ruleVariableFilter:
+		var predicate = new(pathPredicate)
+
+		for _, part := range strings.Split(self.raw(node.directChild(ruleFilterField)), `.`) {
+			if part != `@` {
+				predicate.Field = append(predicate.Field, part)
+			}
+		}
+
+		if rxNode := node.directChild(ruleRegularExpression); rxNode != nil {
+			if rx, err := self.parseRegex(rxNode); err == nil {
+				predicate.Pattern = rx
+				predicate.Negate = (node.directChild(ruleMatchOperator).firstChild(ruleUnmatch) != nil)
+			} else {
+				return pathSegment{}, err
+			}
+		} else if opNode := node.directChild(ruleComparisonOperator); opNode != nil {
+			if cmp, err := parseComparator(opNode); err == nil {
+				predicate.Comparator = &cmp
+			} else {
+				return pathSegment{}, err
+			}
+
+			if value, err := NewExpression(self, node.directChild(ruleExpression)).Value(); err == nil {
+				predicate.Value = value
+			} else {
+				return pathSegment{}, err
+			}
+		}
+
+		return pathSegment{
+			Type:   pathFilter,
+			Filter: predicate,
+		}, nil
+
+	case ruleExpression:
+		if value, err := NewExpression(self, node).Value(); err == nil {
+			return pathSegment{
+				Type: pathKey,
+				Key:  fmt.Sprintf("%v", intIfYouCan(value)),
+			}, nil
+		} else {
+			return pathSegment{}, err
+		}
+
+	default:
+		return pathSegment{}, fmt.Errorf("invalid index %q", self.raw(node))
 	}
 }
 
 func (self *Statement) resolveVariable(node *node32) (interface{}, error) {
-	if key, err := self.resolveVariableKey(node); err == nil {
-		if key == `` {
+	if segments, err := self.resolveVariableSegments(node); err == nil {
+		if len(segments) == 0 {
 			return nil, nil
 		} else {
-			return self.Script().Scope().Get(key), nil
+			return resolvePath(self.Script().Scope(), segments)
 		}
 	} else {
-		return nil, fmt.Errorf("expected variable, got %v", node)
+		return nil, err
 	}
 }
 
@@ -386,9 +607,17 @@ func (self *Statement) makeAssignment(node *node32) *Assignment {
 			names := make([]string, 0)
 			expressions := make([]*Expression, 0)
 
+			targets := make([][]string, 0)
+
 			for _, varNode := range lhs.first().children(ruleVariable) {
-				if key, err := self.resolveVariableKey(varNode); err == nil {
-					names = append(names, key)
+				if keys, err := self.resolveVariableTargets(varNode); err == nil {
+					if len(keys) == 1 {
+						names = append(names, keys[0])
+					} else {
+						names = append(names, self.raw(varNode))
+					}
+
+					targets = append(targets, keys)
 				} else {
 					log.Panicf("unable to resolve variable name: %v", err)
 				}
@@ -402,7 +631,9 @@ func (self *Statement) makeAssignment(node *node32) *Assignment {
 				LeftHandSide:  names,
 				Operator:      aop,
 				RightHandSide: expressions,
+				Constant:      node.rule() == ruleConstant,
 				statement:     self,
+				targets:       targets,
 			}
 		} else {
 			log.Panicf("invalid assignment operator: %v", err)
@@ -6,9 +6,31 @@ type Assignment struct {
 	LeftHandSide  []string
 	Operator      AssignmentOperator
 	RightHandSide []*Expression
+	Constant      bool
 	statement     *Statement
+	targets       [][]string
 }
 
 func (self *Assignment) String() string {
+	if self.Constant {
+		return fmt.Sprintf("const %v %v (%d expressions)", self.LeftHandSide, self.Operator, len(self.RightHandSide))
+	}
+
 	return fmt.Sprintf("%v %v (%d expressions)", self.LeftHandSide, self.Operator, len(self.RightHandSide))
 }
+
+func (self *Assignment) SourceContext() *Context {
+	return self.statement.SourceContext()
+}
+
+// Return the keys of all of the values that the nth left-hand side variable refers to.  Variables
+// with wildcards, filters, or slices (e.g.: $users[*].active) can refer to several values (or none).
+func (self *Assignment) Targets(i int) []string {
+	if i < len(self.targets) {
+		return self.targets[i]
+	} else if i < len(self.LeftHandSide) {
+		return []string{self.LeftHandSide[i]}
+	}
+
+	return nil
+}
@@ -3,8 +3,6 @@ package scripting
 import (
 	"fmt"
 	"strings"
-
-	"github.com/PerformLine/go-stockutil/stringutil"
 )
 
 type Command struct {
@@ -92,14 +90,15 @@ func (self *Command) Script() *Friendscript {
 	return self.statement.Script()
 }
 
-// Return the name of the module the command resides in and the command name.
+// Return the name of the module the command resides in and the command name.  Commands in
+// submodules (e.g.: "aws::s3::get") return the full path to the submodule ("aws::s3").
 func (self *Command) Name() (string, string) {
 	ident := self.node.first(ruleCommandName)
 	cmdname := self.statement.raw(ident)
 	modname := UnqualifiedModuleName
 
-	if strings.Contains(cmdname, `::`) {
-		modname, cmdname = stringutil.SplitPair(cmdname, `::`)
+	if i := strings.LastIndex(cmdname, ModuleSeparator); i >= 0 {
+		modname, cmdname = cmdname[:i], cmdname[i+len(ModuleSeparator):]
 	}
 
 	return modname, cmdname
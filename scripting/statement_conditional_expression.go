@@ -52,7 +52,9 @@ func isTruthy(value interface{}) bool {
 		return false
 	}
 
-	if typeutil.IsEmpty(value) || typeutil.IsZero(value) || isEmpty(value) {
+	if d, ok := value.(Decimal); ok {
+		return !d.IsZero()
+	} else if typeutil.IsEmpty(value) || typeutil.IsZero(value) || isEmpty(value) {
 		return false
 	} else if stringutil.IsBooleanFalse(value) {
 		return false
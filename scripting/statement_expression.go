@@ -34,25 +34,110 @@ func (self *Expression) String() string {
 }
 
 func (self *Expression) Value() (interface{}, error) {
-	if lhs := self.node.first(ruleExpressionLHS); lhs != nil {
-		if value, err := self.resolveValue(lhs.firstChild(ruleValueYielding)); err == nil {
-			if rhs := self.node.first(ruleExpressionRHS); rhs != nil {
-				if op, err := parseOperator(rhs.firstChild(ruleOperator)); err == nil {
-					if exprNode := rhs.firstChild(ruleExpression); exprNode != nil {
-						return op.evaluate(value, NewExpression(self.statement, exprNode))
+	if value, err := self.operandValue(); err == nil {
+		if tail := self.node.directChild(ruleExpressionTail); tail != nil {
+			return self.evaluateTail(value, tail.directChild())
+		}
+
+		// log.Debugf("EXPR %T(%v)", value, value)
+		return value, nil
+	} else {
+		return nil, err
+	}
+}
+
+// evaluate the arithmetic portion of the expression (everything except a trailing ternary or null-coalescing operator)
+func (self *Expression) operandValue() (interface{}, error) {
+	var operands []interface{}
+	var operators []operator
+	var node = self.node
+
+	// the grammar nests each operator and the rest of the expression to its right, so the chain
+	// is flattened out here and evaluated according to operator precedence.
+	for node != nil {
+		if lhs := node.directChild(ruleExpressionLHS); lhs != nil {
+			if value, err := self.resolveValue(lhs.firstChild(ruleValueYielding)); err == nil {
+				operands = append(operands, value)
+			} else {
+				return nil, fmt.Errorf("invalid value: %v", err)
+			}
+		} else {
+			return nil, fmt.Errorf("left-hand side of expression did not yield a value")
+		}
+
+		if rhs := node.directChild(ruleExpressionRHS); rhs != nil {
+			if op, err := parseOperator(rhs.firstChild(ruleOperator)); err == nil {
+				operators = append(operators, op)
+				node = rhs.directChild(ruleExpressionOperand)
+			} else if op != opNull {
+				return new(emptyValue), err
+			} else {
+				node = nil
+			}
+		} else {
+			node = nil
+		}
+	}
+
+	if len(operands) != len(operators)+1 {
+		return nil, fmt.Errorf("operator is missing its right-hand side")
+	}
+
+	return evaluateOperators(operands, operators)
+}
+
+func (self *Expression) evaluateTail(value interface{}, tail *node32) (interface{}, error) {
+	switch tail.rule() {
+	case ruleExpressionCoalesce:
+		// null and declared-but-unset values are replaced by the fallback
+		if isEmpty(value) {
+			return NewExpression(self.statement, tail.directChild(ruleExpression)).Value()
+		}
+
+		return value, nil
+
+	case ruleExpressionTernary:
+		var branches = tail.directChildren(ruleExpression)
+		var result bool
+
+		if len(branches) != 2 {
+			return nil, fmt.Errorf("malformed ternary expression")
+		}
+
+		if cond := tail.directChild(ruleExpressionTernaryCondition); cond != nil {
+			if cmpNode := cond.directChild(ruleComparisonOperator); cmpNode != nil {
+				if cmp, err := parseComparator(cmpNode); err == nil {
+					if rhs, err := NewExpression(self.statement, cond.directChild(ruleExpressionOperand)).Value(); err == nil {
+						if result, err = cmp.Compare(value, rhs); err != nil {
+							return nil, err
+						}
+					} else {
+						return nil, err
 					}
-				} else if op != opNull {
-					return new(emptyValue), err
+				} else {
+					return nil, err
 				}
+			} else if mo, err := parseMatchComparator(cond.directChild(ruleMatchOperator)); err == nil {
+				if rx, err := self.statement.parseRegex(cond.directChild(ruleRegularExpression)); err == nil {
+					result = mo.Evaluate(rx, value)
+				} else {
+					return nil, err
+				}
+			} else {
+				return nil, err
 			}
+		} else {
+			result = isTruthy(value)
+		}
 
-			// log.Debugf("EXPR %T(%v)", value, value)
-			return value, nil
+		if result {
+			return NewExpression(self.statement, branches[0]).Value()
 		} else {
-			return nil, fmt.Errorf("invalid value: %v", err)
+			return NewExpression(self.statement, branches[1]).Value()
 		}
-	} else {
-		return nil, fmt.Errorf("left-hand side of expression did not yield a value")
+
+	default:
+		return nil, fmt.Errorf("unrecognized expression %q", self.statement.raw(tail))
 	}
 }
 
@@ -63,6 +148,8 @@ func (self *Expression) resolveValue(node *node32) (interface{}, error) {
 
 	} else if typeNode := node.firstN(1, ruleType); typeNode != nil {
 		return self.statement.parseValue(typeNode)
+	} else if groupNode := node.firstN(1, ruleExpressionGroup); groupNode != nil {
+		return NewExpression(self.statement, groupNode.directChild(ruleExpression)).Value()
 	} else {
 		return nil, fmt.Errorf("invalid value argument '%v'", self.statement.raw(node))
 	}
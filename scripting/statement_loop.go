@@ -1,13 +1,16 @@
 package scripting
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/PerformLine/go-stockutil/log"
 	"github.com/PerformLine/go-stockutil/stringutil"
 )
 
 var DefaultIteratorCommandResultVariableName = `result`
+var DefaultIteratorMatchesVariableName = `matches`
 
 type LoopType int
 
@@ -24,11 +27,17 @@ type FlowControlType int
 const (
 	FlowBreak FlowControlType = iota
 	FlowContinue
+	FlowReturn
 )
 
 type FlowControlErr struct {
 	Type  FlowControlType
 	Level int
+	Value interface{}
+
+	// Errors from deferred blocks that failed while the break, continue, or return was underway,
+	// which are reported once it has been carried out.
+	Err error
 }
 
 func NewFlowControl(flowType FlowControlType, levels int) *FlowControlErr {
@@ -38,6 +47,14 @@ func NewFlowControl(flowType FlowControlType, levels int) *FlowControlErr {
 	}
 }
 
+// Create a flow control error that ends evaluation of the current script with the given value.
+func NewFlowReturn(value interface{}) *FlowControlErr {
+	return &FlowControlErr{
+		Type:  FlowReturn,
+		Value: value,
+	}
+}
+
 func (self FlowControlErr) Error() string {
 	msg := ``
 
@@ -46,6 +63,8 @@ func (self FlowControlErr) Error() string {
 		msg = `break`
 	case FlowContinue:
 		msg = `continue`
+	case FlowReturn:
+		return fmt.Sprintf("return %v", self.Value)
 	default:
 		self.Level = 0
 		return `invalid flow control statement`
@@ -54,6 +73,27 @@ func (self FlowControlErr) Error() string {
 	return fmt.Sprintf("%v %d", msg, self.Level)
 }
 
+// An ExitError is returned from evaluation when a script ends early with the "exit" statement.  The
+// status code given to exit is available to the embedding application as Status.
+type ExitError struct {
+	Status int
+}
+
+func (self *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", self.Status)
+}
+
+// Return the status code of the given error if it is (or wraps) an ExitError.
+func ExitStatus(err error) (int, bool) {
+	var exit *ExitError
+
+	if errors.As(err, &exit) {
+		return exit.Status, true
+	}
+
+	return 0, false
+}
+
 func (self LoopType) String() string {
 	switch self {
 	case InfiniteLoop:
@@ -71,6 +111,53 @@ func (self LoopType) String() string {
 	}
 }
 
+// An Iterator produces the values a loop iterates over one at a time, as they are needed.  Commands
+// can return an Iterator to be looped over without computing (or fetching) all of its values up
+// front, e.g.: `loop $item in http::paginate 'https://example.com/items' { ... }`
+type Iterator interface {
+	// Return the next value, or false once there are no more values.
+	Next() (interface{}, bool, error)
+}
+
+// Represents the right-hand side of a loop that iterates over regular expression matches,
+// e.g.: `loop $m in $text =~ /id=(\d+)/g { ... }`
+type MatchIterable struct {
+	Expression *Expression
+	Pattern    *regexp.Regexp
+	Global     bool
+}
+
+// Represents the right-hand side of a loop that iterates over the values of a variable reference
+// containing slices, wildcards, or filters, e.g.: `loop $u in $users[?active] { ... }`
+type PathIterable struct {
+	Value interface{}
+}
+
+// Return the capture groups of every match in the expression value (or only the first match if the
+// pattern does not have the "g" flag set.)  Each element is structured as described in MatchOperator.Captures.
+func (self *MatchIterable) Matches() ([]interface{}, error) {
+	var matches = make([]interface{}, 0)
+	var limit = 1
+
+	if self.Global {
+		limit = -1
+	}
+
+	if value, err := self.Expression.Value(); err == nil {
+		if isEmpty(value) {
+			return matches, nil
+		}
+
+		for _, groups := range self.Pattern.FindAllStringSubmatch(fmt.Sprintf("%v", value), limit) {
+			matches = append(matches, capturesToMap(self.Pattern, groups))
+		}
+
+		return matches, nil
+	} else {
+		return nil, err
+	}
+}
+
 type Loop struct {
 	statement  *Statement
 	iterations int
@@ -212,12 +299,32 @@ func (self *Loop) IteratableParts() ([]string, interface{}) {
 					}
 				}
 
-				if rhsNode := rhs.first(0, ruleCommand, ruleVariable); rhsNode != nil {
-					if rhsNode.rule() == ruleCommand {
+				if rhsNode := rhs.first(ruleLoopIterableMatch, ruleCommand, ruleVariable); rhsNode != nil {
+					if rhsNode.rule() == ruleLoopIterableMatch {
+						rxNode := rhsNode.firstChild(ruleRegularExpression)
+
+						if rx, err := self.statement.parseRegex(rxNode); err == nil {
+							rightHand = &MatchIterable{
+								Expression: NewExpression(self.statement, rhsNode.firstChild(ruleExpression)),
+								Pattern:    rx,
+								Global:     self.statement.isGlobalRegex(rxNode),
+							}
+						} else {
+							log.Panicf("malformed regular expression: %v", err)
+						}
+					} else if rhsNode.rule() == ruleCommand {
 						rightHand = &Command{
 							statement: self.statement,
 							node:      rhsNode,
 						}
+					} else if segments, err := self.statement.resolveVariableSegments(rhsNode); err == nil && simplePrefixLength(segments) < len(segments) {
+						if value, err := resolvePath(self.statement.Script().Scope(), segments); err == nil {
+							rightHand = &PathIterable{
+								Value: value,
+							}
+						} else {
+							log.Panicf("unable to resolve variable: %v", err)
+						}
 					} else if key, err := self.statement.resolveVariableKey(rhsNode); err == nil {
 						rightHand = key
 					} else {
@@ -0,0 +1,322 @@
+package scripting
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/maputil"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+type ParamType string
+
+const (
+	ParamAny      ParamType = `any`
+	ParamString   ParamType = `string`
+	ParamInt      ParamType = `int`
+	ParamFloat    ParamType = `float`
+	ParamBool     ParamType = `bool`
+	ParamArray    ParamType = `array`
+	ParamObject   ParamType = `object`
+	ParamDuration ParamType = `duration`
+	ParamTime     ParamType = `time`
+)
+
+// alternative names that are accepted for parameter types
+var paramTypeAliases = map[string]ParamType{
+	`integer`:   ParamInt,
+	`number`:    ParamFloat,
+	`boolean`:   ParamBool,
+	`list`:      ParamArray,
+	`map`:       ParamObject,
+	`timestamp`: ParamTime,
+}
+
+// Return the parameter type with the given name (or one of its aliases.)
+func ParseParamType(name string) (ParamType, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+
+	switch ptype := ParamType(name); ptype {
+	case ParamAny, ParamString, ParamInt, ParamFloat, ParamBool, ParamArray, ParamObject, ParamDuration, ParamTime:
+		return ptype, nil
+	case ``:
+		return ParamAny, nil
+	}
+
+	if ptype, ok := paramTypeAliases[name]; ok {
+		return ptype, nil
+	}
+
+	return ``, fmt.Errorf("unknown parameter type %q", name)
+}
+
+// Convert the given value to this type, or return an error if it cannot be converted.  Strings
+// (e.g.: values given on the command line) are parsed into the appropriate type.
+func (self ParamType) Coerce(value interface{}) (interface{}, error) {
+	var out interface{}
+	var err error
+
+	switch self {
+	case ParamString:
+		if typeutil.IsScalar(value) || isTemporal(value) {
+			out = typeutil.String(value)
+		} else {
+			err = fmt.Errorf("not a string")
+		}
+
+	case ParamInt:
+		// integers are parsed directly so that large values don't lose precision as floats
+		if i, ok := toInt64(value); ok {
+			out = i
+		} else if i, perr := strconv.ParseInt(strings.TrimSpace(typeutil.String(value)), 10, 64); perr == nil {
+			out = i
+		} else if f, ferr := stringutil.ConvertToFloat(value); ferr != nil {
+			err = ferr
+		} else if f != float64(int64(f)) {
+			err = fmt.Errorf("not a whole number")
+		} else {
+			out = int64(f)
+		}
+
+	case ParamFloat:
+		out, err = stringutil.ConvertToFloat(value)
+
+	case ParamBool:
+		if b, ok := value.(bool); ok {
+			out = b
+		} else {
+			switch strings.ToLower(typeutil.String(value)) {
+			case `true`, `yes`, `on`, `1`:
+				out = true
+			case `false`, `no`, `off`, `0`:
+				out = false
+			default:
+				err = fmt.Errorf("not a boolean")
+			}
+		}
+
+	case ParamArray:
+		if typeutil.IsArray(value) {
+			out = sliceutil.Sliceify(value)
+		} else if s, ok := value.(string); ok {
+			out = sliceutil.CompactString(strings.Split(s, `,`))
+		} else {
+			err = fmt.Errorf("not an array")
+		}
+
+	case ParamObject:
+		if typeutil.IsMap(value) {
+			out = maputil.DeepCopy(value)
+		} else {
+			err = fmt.Errorf("not an object")
+		}
+
+	case ParamDuration:
+		if d, ok := toDuration(value); ok {
+			out = d
+		} else if ms, cerr := stringutil.ConvertToInteger(value); cerr == nil {
+			out = time.Duration(ms) * time.Millisecond
+		} else {
+			err = fmt.Errorf("not a duration")
+		}
+
+	case ParamTime:
+		if t, ok := toTime(value); ok {
+			out = t
+		} else {
+			err = fmt.Errorf("not a timestamp")
+		}
+
+	default:
+		out = value
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("expected %v, got %T(%v)", self, value, value)
+	}
+
+	return out, nil
+}
+
+// Describes a value that a script expects to be given by whatever runs it, as declared by a
+// param statement, e.g.: `param $url: string required` or `param $retries: int = 3`
+type Param struct {
+	Name       string    `json:"name"`
+	Type       ParamType `json:"type"`
+	Required   bool      `json:"required,omitempty"`
+	HasDefault bool      `json:"has_default,omitempty"`
+
+	// The default value, unless it depends on the values of variables (or on the time) when the
+	// script is run, in which case it is only evaluated then.
+	Default interface{} `json:"default,omitempty"`
+
+	// The default value as it is written in the script.
+	DefaultExpression string `json:"default_expression,omitempty"`
+
+	Line int `json:"line,omitempty"`
+
+	expression *Expression
+}
+
+func (self *Param) String() string {
+	return fmt.Sprintf("param<$%s: %v>", self.Name, self.Type)
+}
+
+// Check the given value against this parameter's declaration, returning the value converted to
+// the declared type.  Missing values are replaced with the default (if any), and are an error
+// if the parameter is required.
+func (self *Param) Validate(value interface{}) (interface{}, error) {
+	if isEmpty(value) {
+		if self.HasDefault {
+			if v, err := self.defaultValue(); err == nil {
+				value = v
+			} else {
+				return nil, &ParamError{
+					Param: self,
+					Err:   fmt.Errorf("invalid default: %v", err),
+				}
+			}
+		} else if self.Required {
+			return nil, &ParamError{
+				Param:   self,
+				Missing: true,
+			}
+		}
+
+		if isEmpty(value) {
+			return nil, nil
+		}
+	}
+
+	if out, err := self.Type.Coerce(value); err == nil {
+		return out, nil
+	} else {
+		return nil, &ParamError{
+			Param: self,
+			Err:   err,
+		}
+	}
+}
+
+// defaults that depend on the script's scope are evaluated against it as it is now
+func (self *Param) defaultValue() (interface{}, error) {
+	if self.expression != nil {
+		return self.expression.Value()
+	}
+
+	return self.Default, nil
+}
+
+// Returned when the value given for a parameter is missing or invalid.
+type ParamError struct {
+	Param   *Param
+	Missing bool
+	Err     error
+}
+
+func (self *ParamError) Error() string {
+	if self.Missing {
+		return fmt.Sprintf("missing required parameter $%s", self.Param.Name)
+	} else {
+		return fmt.Sprintf("invalid value for parameter $%s: %v", self.Param.Name, self.Err)
+	}
+}
+
+func (self *ParamError) Unwrap() error {
+	return self.Err
+}
+
+// Return the parameter declared by a param statement.  Default values that refer to variables (or
+// the current time) are left to be evaluated by Validate, using the script's scope at that point.
+func (self *Statement) Param() (*Param, error) {
+	if self.Type() != ParamStatement {
+		return nil, fmt.Errorf("not a param statement")
+	}
+
+	var param = &Param{
+		Type: ParamAny,
+		Line: self.SourceContext().Line(),
+	}
+
+	if name, err := self.resolveVariableKey(self.node.directChild(ruleVariable)); err == nil {
+		param.Name = name
+	} else {
+		return nil, err
+	}
+
+	if typeNode := self.node.directChild(ruleParamType); typeNode != nil {
+		if ptype, err := ParseParamType(self.raw(typeNode)); err == nil {
+			param.Type = ptype
+		} else {
+			return nil, fmt.Errorf("parameter $%s: %v", param.Name, err)
+		}
+	}
+
+	if self.node.directChild(ruleParamRequired) != nil {
+		param.Required = true
+	} else if defNode := self.node.directChild(ruleParamDefault); defNode != nil {
+		var exprNode = defNode.directChild(ruleExpression)
+		var expr = NewExpression(self, exprNode)
+
+		param.HasDefault = true
+		param.DefaultExpression = strings.TrimSpace(self.raw(exprNode))
+
+		if self.isConstant(exprNode) {
+			if value, err := expr.Value(); err == nil {
+				param.Default = value
+			} else {
+				return nil, fmt.Errorf("parameter $%s: invalid default: %v", param.Name, err)
+			}
+		} else {
+			param.expression = expr
+		}
+	}
+
+	return param, nil
+}
+
+// whether the given expression evaluates to the same value regardless of the scope (or time) it is
+// evaluated in
+func (self *Statement) isConstant(node *node32) bool {
+	for _, child := range node.find(ruleVariable, ruleNow, ruleStringInterpolated) {
+		if child.rule() != ruleStringInterpolated || strings.Contains(self.raw(child), `{`) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Report whether this statement appears at the top level of its script (i.e.: not inside of a
+// conditional, loop, or any other block.)
+func (self *Statement) IsTopLevel() bool {
+	return self.block == nil || self.block.parent == nil
+}
+
+// Return all parameters declared at the top level of this script, in the order they appear.  This
+// doesn't evaluate the script, so defaults that depend on it being run are only given as expressions.
+func (self *Friendscript) Params() ([]*Param, error) {
+	var params = make([]*Param, 0)
+
+	for _, block := range self.Blocks() {
+		if block.Type() != StatementBlock {
+			continue
+		}
+
+		for _, statement := range block.Statements() {
+			if statement.Type() == ParamStatement {
+				if param, err := statement.Param(); err == nil {
+					params = append(params, param)
+				} else {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return params, nil
+}
@@ -0,0 +1,226 @@
+package scripting
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+// The name of the variable that holds the current attempt number (starting at 1) inside of a
+// retry block.
+var RetryAttemptVariableName = `attempt`
+
+type RetryBackoff string
+
+const (
+	BackoffConstant    RetryBackoff = `constant`
+	BackoffLinear      RetryBackoff = `linear`
+	BackoffExponential RetryBackoff = `exponential`
+)
+
+// Options that control how (and whether) the blocks of a retry statement are attempted again
+// after failing.
+type RetryOptions struct {
+	// How the delay between attempts grows: "constant" (always Initial), "linear" (Initial times
+	// the attempt number), or "exponential" (Initial doubled after each attempt).
+	Backoff RetryBackoff
+
+	// The delay after the first failed attempt.
+	Initial time.Duration
+
+	// The longest delay between two attempts (zero means unlimited).
+	Max time.Duration
+
+	// If set, only errors whose message matches this pattern are retried; others fail immediately.
+	On *regexp.Regexp
+}
+
+// Return how long to wait after the given (1-based) attempt fails.
+func (self *RetryOptions) Delay(attempt int) time.Duration {
+	var delay = self.Initial
+
+	switch self.Backoff {
+	case BackoffLinear:
+		delay = self.Initial * time.Duration(attempt)
+	case BackoffExponential:
+		for i := 1; i < attempt && (self.Max <= 0 || delay < self.Max); i++ {
+			delay *= 2
+		}
+	}
+
+	if self.Max > 0 && delay > self.Max {
+		delay = self.Max
+	}
+
+	return delay
+}
+
+// Return whether the given error should cause another attempt to be made.
+func (self *RetryOptions) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	} else if self.On != nil {
+		return self.On.MatchString(err.Error())
+	}
+
+	return true
+}
+
+// Represents a statement that evaluates its blocks until they succeed, up to a maximum number of
+// attempts, e.g.: `retry 5 { backoff: "exponential", initial: 200ms } { ... }`
+type Retry struct {
+	statement *Statement
+}
+
+func (self *Retry) String() string {
+	return fmt.Sprintf("retry<%v>", self.statement.raw(self.statement.node.directChild(ruleRetryAttempts)))
+}
+
+// Return the maximum number of times the blocks will be evaluated.
+func (self *Retry) Attempts() (int, error) {
+	if node := self.statement.node.directChild(ruleRetryAttempts); node != nil {
+		var value interface{}
+		var err error
+
+		if varNode := node.directChild(ruleVariable); varNode != nil {
+			value, err = self.statement.resolveVariable(varNode)
+		} else {
+			value = self.statement.raw(node)
+		}
+
+		if err != nil {
+			return 0, err
+		} else if attempts, err := stringutil.ConvertToInteger(value); err == nil && attempts > 0 {
+			return int(attempts), nil
+		} else {
+			return 0, fmt.Errorf("invalid number of retry attempts %v", value)
+		}
+	}
+
+	return 0, fmt.Errorf("malformed retry statement")
+}
+
+// Return the options given to the retry statement.
+func (self *Retry) Options() (*RetryOptions, error) {
+	var options = &RetryOptions{
+		Backoff: BackoffConstant,
+	}
+
+	if node := self.statement.node.directChild(ruleRetryOptions); node != nil {
+		if opts, err := self.statement.parseObject(node.directChild(ruleObject)); err == nil {
+			for key, value := range opts {
+				if isEmpty(value) {
+					continue
+				}
+
+				switch key {
+				case `backoff`:
+					switch backoff := RetryBackoff(strings.ToLower(typeutil.String(value))); backoff {
+					case BackoffConstant, BackoffLinear, BackoffExponential:
+						options.Backoff = backoff
+					default:
+						return nil, fmt.Errorf("invalid backoff %q", value)
+					}
+
+				case `initial`, `max`:
+					var d time.Duration
+
+					if dv, ok := toDuration(value); ok {
+						d = dv
+					} else if ms, err := stringutil.ConvertToInteger(value); err == nil {
+						d = time.Duration(ms) * time.Millisecond
+					} else {
+						return nil, fmt.Errorf("invalid duration for %q: %v", key, value)
+					}
+
+					if key == `initial` {
+						options.Initial = d
+					} else {
+						options.Max = d
+					}
+
+				case `on`:
+					if rx, ok := value.(*regexp.Regexp); ok {
+						options.On = rx
+					} else if rx, err := regexp.Compile(typeutil.String(value)); err == nil {
+						options.On = rx
+					} else {
+						return nil, fmt.Errorf("invalid pattern for %q: %v", key, err)
+					}
+
+				default:
+					return nil, fmt.Errorf("unknown retry option %q", key)
+				}
+			}
+		} else {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+func (self *Retry) Blocks() []*Block {
+	return directBlocks(self.statement, self.statement.node)
+}
+
+// Represents a statement that fails if its blocks do not finish within a given duration,
+// e.g.: `timeout 30s { ... }`
+type Timeout struct {
+	statement *Statement
+}
+
+func (self *Timeout) String() string {
+	return fmt.Sprintf("timeout<%v>", self.statement.raw(self.statement.node.directChild(ruleTimeoutDuration)))
+}
+
+// Return how long the blocks are allowed to run for.  Integers are interpreted as milliseconds.
+func (self *Timeout) Duration() (time.Duration, error) {
+	if node := self.statement.node.directChild(ruleTimeoutDuration); node != nil {
+		var value interface{}
+		var err error
+
+		if varNode := node.directChild(ruleVariable); varNode != nil {
+			value, err = self.statement.resolveVariable(varNode)
+		} else if durNode := node.directChild(ruleDuration); durNode != nil {
+			value, err = ParseDuration(self.statement.raw(durNode))
+		} else {
+			value = self.statement.raw(node)
+		}
+
+		if err != nil {
+			return 0, err
+		} else if d, ok := toDuration(value); ok && d > 0 {
+			return d, nil
+		} else if ms, err := stringutil.ConvertToInteger(value); err == nil && ms > 0 {
+			return time.Duration(ms) * time.Millisecond, nil
+		} else {
+			return 0, fmt.Errorf("invalid timeout %v", value)
+		}
+	}
+
+	return 0, fmt.Errorf("malformed timeout statement")
+}
+
+func (self *Timeout) Blocks() []*Block {
+	return directBlocks(self.statement, self.statement.node)
+}
+
+// return the blocks that are immediate children of the given node
+func directBlocks(statement *Statement, node *node32) []*Block {
+	blocks := make([]*Block, 0)
+
+	for _, child := range node.directChildren(ruleBlock) {
+		blocks = append(blocks, &Block{
+			friendscript: statement.Script(),
+			node:         child.first(),
+			parent:       statement,
+		})
+	}
+
+	return blocks
+}
@@ -0,0 +1,138 @@
+package scripting
+
+import (
+	"fmt"
+)
+
+type SwitchCaseType int
+
+const (
+	SwitchCaseValues SwitchCaseType = iota
+	SwitchCaseRegex
+	SwitchCaseMembership
+	SwitchCaseDefault
+)
+
+func (self SwitchCaseType) String() string {
+	switch self {
+	case SwitchCaseValues:
+		return `SwitchCaseValues`
+	case SwitchCaseRegex:
+		return `SwitchCaseRegex`
+	case SwitchCaseMembership:
+		return `SwitchCaseMembership`
+	case SwitchCaseDefault:
+		return `SwitchCaseDefault`
+	default:
+		return `UNKNOWN`
+	}
+}
+
+// Represents a statement that compares one value against several cases, e.g.:
+// `switch $value { case 1, 2 { ... } case /regex/ { ... } case in $list { ... } default { ... } }`
+type Switch struct {
+	statement *Statement
+}
+
+func (self *Switch) String() string {
+	return fmt.Sprintf("switch<%d cases>", len(self.Cases()))
+}
+
+// Return the expression whose value is compared against each case.
+func (self *Switch) Subject() *Expression {
+	return NewExpression(self.statement, self.statement.node.directChild(ruleExpression))
+}
+
+// Return all cases in the order they appear, with the default case (if any) last.
+func (self *Switch) Cases() []*SwitchCase {
+	cases := make([]*SwitchCase, 0)
+
+	for _, node := range self.statement.node.directChildren(ruleSwitchCase, ruleSwitchDefault) {
+		cases = append(cases, &SwitchCase{
+			statement: self.statement,
+			node:      node,
+		})
+	}
+
+	return cases
+}
+
+type SwitchCase struct {
+	statement *Statement
+	node      *node32
+}
+
+func (self *SwitchCase) String() string {
+	return fmt.Sprintf("case<%v>", self.Type())
+}
+
+func (self *SwitchCase) Type() SwitchCaseType {
+	if self.node.rule() == ruleSwitchDefault {
+		return SwitchCaseDefault
+	} else if cond := self.condition(); cond != nil {
+		switch cond.rule() {
+		case ruleSwitchCaseRegex:
+			return SwitchCaseRegex
+		case ruleSwitchCaseMembership:
+			return SwitchCaseMembership
+		}
+	}
+
+	return SwitchCaseValues
+}
+
+// Report whether the given value satisfies this case.  Values cases match if the value is equal to
+// any of the listed values (values of incomparable types are not equal), regular expression cases match if the pattern matches the value, and
+// membership cases match if the value is an element of the given array (or key of the given object.)
+// For regular expression cases, the capture groups of the match are also returned.
+func (self *SwitchCase) Matches(value interface{}) (bool, map[string]interface{}, error) {
+	switch self.Type() {
+	case SwitchCaseDefault:
+		return true, nil, nil
+
+	case SwitchCaseRegex:
+		if rx, err := self.statement.parseRegex(self.condition().directChild(ruleRegularExpression)); err == nil {
+			if captures := matchOp.Captures(rx, value); captures != nil {
+				return true, captures, nil
+			}
+
+			return false, nil, nil
+		} else {
+			return false, nil, fmt.Errorf("malformed regular expression: %v", err)
+		}
+
+	case SwitchCaseMembership:
+		if list, err := NewExpression(self.statement, self.condition().directChild(ruleExpression)).Value(); err == nil {
+			ok, err := cmpMembership.Compare(value, list)
+			return ok, nil, err
+		} else {
+			return false, nil, err
+		}
+
+	default:
+		if seq := self.condition().directChild(ruleExpressionSequence); seq != nil {
+			for _, exprNode := range seq.directChildren(ruleExpression) {
+				if want, err := NewExpression(self.statement, exprNode).Value(); err == nil {
+					// values that can't be compared with each other (e.g.: an object and a number)
+					// simply don't match
+					if ok, err := cmpEquality.Compare(value, want); err == nil && ok {
+						return true, nil, nil
+					}
+				} else {
+					return false, nil, err
+				}
+			}
+		}
+
+		return false, nil, nil
+	}
+}
+
+// Return the blocks to evaluate if this case matches.
+func (self *SwitchCase) Blocks() []*Block {
+	return directBlocks(self.statement, self.node)
+}
+
+func (self *SwitchCase) condition() *node32 {
+	return self.node.directChild(ruleSwitchCaseRegex, ruleSwitchCaseMembership, ruleSwitchCaseValues)
+}
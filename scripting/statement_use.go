@@ -0,0 +1,63 @@
+package scripting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The separator between the names of modules, submodules, and commands (e.g.: "aws::s3::get").
+var ModuleSeparator = `::`
+
+// Describes a module that a script depends on, as declared by a use statement, e.g.:
+// `use http` or `use aws::s3 as s3`
+type Import struct {
+	Module string `json:"module"`
+	Alias  string `json:"alias,omitempty"`
+	Line   int    `json:"line,omitempty"`
+}
+
+func (self *Import) String() string {
+	if self.Alias != `` {
+		return fmt.Sprintf("use<%s as %s>", self.Module, self.Alias)
+	}
+
+	return fmt.Sprintf("use<%s>", self.Module)
+}
+
+// Return the module declared by a use statement.
+func (self *Statement) Import() *Import {
+	if self.Type() == UseStatement {
+		var imp = &Import{
+			Module: strings.TrimSpace(self.raw(self.node.directChild(ruleUseModule))),
+			Line:   self.SourceContext().Line(),
+		}
+
+		if alias := self.node.directChild(ruleUseAlias); alias != nil {
+			imp.Alias = strings.TrimSpace(self.raw(alias))
+		}
+
+		return imp
+	}
+
+	return nil
+}
+
+// Return all modules declared by use statements at the top level of this script, in the order
+// they appear.
+func (self *Friendscript) Imports() []*Import {
+	var imports = make([]*Import, 0)
+
+	for _, block := range self.Blocks() {
+		if block.Type() != StatementBlock {
+			continue
+		}
+
+		for _, statement := range block.Statements() {
+			if statement.Type() == UseStatement {
+				imports = append(imports, statement.Import())
+			}
+		}
+	}
+
+	return imports
+}
@@ -0,0 +1,150 @@
+package scripting
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expand the backslash escape sequences supported in double-quoted strings.  Unrecognized
+// sequences are left as-is (e.g.: "C:\path" yields "C:\path").
+func unescapeString(in string) string {
+	if !strings.Contains(in, `\`) {
+		return in
+	}
+
+	var out strings.Builder
+	var runes = []rune(in)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' || i+1 >= len(runes) {
+			out.WriteRune(runes[i])
+			continue
+		}
+
+		switch esc := runes[i+1]; esc {
+		case '"', '\\', '/', '\'':
+			out.WriteRune(esc)
+		case 'n':
+			out.WriteRune('\n')
+		case 't':
+			out.WriteRune('\t')
+		case 'r':
+			out.WriteRune('\r')
+		case '0':
+			out.WriteRune(0)
+		case 'a':
+			out.WriteRune('\a')
+		case 'b':
+			out.WriteRune('\b')
+		case 'f':
+			out.WriteRune('\f')
+		case 'v':
+			out.WriteRune('\v')
+		case 'x', 'u', 'U':
+			if r, n := parseCodepoint(esc, runes[i+2:]); n > 0 {
+				out.WriteRune(r)
+				i += n
+			} else {
+				out.WriteRune('\\')
+				out.WriteRune(esc)
+			}
+		default:
+			out.WriteRune('\\')
+			out.WriteRune(esc)
+		}
+
+		i++
+	}
+
+	return out.String()
+}
+
+// parse the hexadecimal codepoint following a \x, \u, or \U escape, returning the rune and the
+// number of characters consumed.
+func parseCodepoint(esc rune, in []rune) (rune, int) {
+	var digits string
+	var consumed int
+
+	if esc == 'u' && len(in) > 0 && in[0] == '{' {
+		if end := indexRune(in, '}'); end > 1 && end <= 7 {
+			digits = string(in[1:end])
+			consumed = end + 1
+		}
+	} else {
+		var width = map[rune]int{'x': 2, 'u': 4, 'U': 8}[esc]
+
+		if len(in) >= width {
+			digits = string(in[:width])
+			consumed = width
+		}
+	}
+
+	if digits != `` {
+		if v, err := strconv.ParseUint(digits, 16, 32); err == nil && utf8.ValidRune(rune(v)) {
+			return rune(v), consumed
+		}
+	}
+
+	return utf8.RuneError, 0
+}
+
+func indexRune(in []rune, r rune) int {
+	for i, c := range in {
+		if c == r {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// Remove the leading and trailing blank lines from the body of a triple-quoted string, as well
+// as any indentation that is common to all of the remaining non-blank lines.
+func dedent(in string) string {
+	if !strings.Contains(in, "\n") {
+		return strings.TrimSpace(in)
+	}
+
+	var lines = strings.Split(in, "\n")
+
+	// the remainder of the line containing the opening quotes
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == `` {
+		lines = lines[1:]
+	}
+
+	for len(lines) > 1 && strings.TrimSpace(lines[len(lines)-1]) == `` {
+		lines = lines[:len(lines)-1]
+	}
+
+	var indent string
+	var found bool
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == `` {
+			continue
+		}
+
+		var lead = line[:len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+
+		if !found {
+			indent = lead
+			found = true
+		} else {
+			for !strings.HasPrefix(lead, indent) {
+				indent = indent[:len(indent)-1]
+			}
+		}
+	}
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) == `` {
+			lines[i] = ``
+		} else {
+			lines[i] = strings.TrimPrefix(line, indent)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,668 @@
+package scripting
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/PerformLine/go-stockutil/log"
+	"github.com/PerformLine/go-stockutil/sliceutil"
+	"github.com/PerformLine/go-stockutil/stringutil"
+	"github.com/PerformLine/go-stockutil/typeutil"
+)
+
+// The maximum number of compiled templates that will be kept around for reuse.  Once there are
+// this many, the least recently used template is discarded to make room for a new one.
+var MaxCachedTemplates = 1024
+
+var templateCache = newTemplateLRU()
+var templateFiltersLock sync.RWMutex
+var rxTemplateFilterName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// A function that transforms a value in an interpolation sequence, e.g.: {name | upper}
+type TemplateFilterFunc func(value interface{}, args ...interface{}) (interface{}, error)
+
+var templateFilters = map[string]TemplateFilterFunc{
+	`upper`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		return strings.ToUpper(typeutil.String(value)), nil
+	},
+	`lower`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		return strings.ToLower(typeutil.String(value)), nil
+	},
+	`title`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		return strings.Title(typeutil.String(value)), nil
+	},
+	`trim`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		return strings.TrimSpace(typeutil.String(value)), nil
+	},
+	`length`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		if isEmpty(value) {
+			return 0, nil
+		} else if typeutil.IsArray(value) || typeutil.IsMap(value) {
+			return typeutil.Len(value), nil
+		} else {
+			return len([]rune(typeutil.String(value))), nil
+		}
+	},
+	`json`: func(value interface{}, _ ...interface{}) (interface{}, error) {
+		if data, err := json.Marshal(value); err == nil {
+			return string(data), nil
+		} else {
+			return nil, err
+		}
+	},
+	`default`: func(value interface{}, args ...interface{}) (interface{}, error) {
+		if isEmpty(value) || typeutil.IsEmpty(value) {
+			if len(args) > 0 {
+				return args[0], nil
+			}
+
+			return nil, nil
+		}
+
+		return value, nil
+	},
+	`join`: func(value interface{}, args ...interface{}) (interface{}, error) {
+		var joiner = `,`
+
+		if len(args) > 0 {
+			joiner = typeutil.String(args[0])
+		}
+
+		return strings.Join(sliceutil.Stringify(value), joiner), nil
+	},
+	`round`: func(value interface{}, args ...interface{}) (interface{}, error) {
+		var places float64
+
+		if len(args) > 0 {
+			places = typeutil.Float(args[0])
+		}
+
+		if v, err := stringutil.ConvertToFloat(value); err == nil {
+			var factor = math.Pow(10, places)
+
+			return intIfYouCan(math.Round(v*factor) / factor), nil
+		} else {
+			return nil, err
+		}
+	},
+}
+
+// Register a filter that can be used in interpolation sequences.  Filters with the same name as an
+// existing filter will replace it.
+func RegisterTemplateFilter(name string, filter TemplateFilterFunc) {
+	templateFiltersLock.Lock()
+	defer templateFiltersLock.Unlock()
+
+	templateFilters[name] = filter
+}
+
+func lookupTemplateFilter(name string) (TemplateFilterFunc, bool) {
+	templateFiltersLock.RLock()
+	defer templateFiltersLock.RUnlock()
+
+	fn, ok := templateFilters[name]
+	return fn, ok
+}
+
+// A compiled interpolated string.  Templates consist of literal text and interpolation sequences
+// wrapped in curly braces, which may contain expressions, filters, and a format specifier:
+//
+//	"Total: {price * 1.2:%.2f} for {name | upper} ({user.email ?? 'none'})"
+//
+// A literal opening brace can be included by doubling it ("{{").
+type Template struct {
+	source string
+	parts  []*templatePart
+}
+
+type templatePart struct {
+	literal   string
+	sequence  bool
+	legacyKey string
+	expr      *templateExpr
+	filters   []*templateFilter
+	format    string
+}
+
+type templateFilter struct {
+	name string
+	args []*templateExpr
+}
+
+// Compile the given string into a Template, reusing a previously-compiled one if available.  Any
+// string is a valid template: braces that don't enclose an interpolation sequence are literal text.
+func CompileTemplate(in string) *Template {
+	if tpl, ok := templateCache.get(in); ok {
+		return tpl
+	}
+
+	var tpl = compileTemplate(in)
+
+	templateCache.add(in, tpl)
+	return tpl
+}
+
+// a fixed-size cache of compiled templates that discards the least recently used one when full
+type templateLRU struct {
+	entries map[string]*list.Element
+	order   *list.List
+	lock    sync.Mutex
+}
+
+type templateLRUEntry struct {
+	source   string
+	template *Template
+}
+
+func newTemplateLRU() *templateLRU {
+	return &templateLRU{
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func (self *templateLRU) get(source string) (*Template, bool) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if el, ok := self.entries[source]; ok {
+		self.order.MoveToFront(el)
+		return el.Value.(*templateLRUEntry).template, true
+	}
+
+	return nil, false
+}
+
+func (self *templateLRU) add(source string, tpl *Template) {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	if MaxCachedTemplates <= 0 {
+		return
+	} else if el, ok := self.entries[source]; ok {
+		self.order.MoveToFront(el)
+		return
+	}
+
+	for self.order.Len() >= MaxCachedTemplates {
+		var oldest = self.order.Back()
+
+		self.order.Remove(oldest)
+		delete(self.entries, oldest.Value.(*templateLRUEntry).source)
+	}
+
+	self.entries[source] = self.order.PushFront(&templateLRUEntry{
+		source:   source,
+		template: tpl,
+	})
+}
+
+func (self *templateLRU) len() int {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+
+	return self.order.Len()
+}
+
+func compileTemplate(in string) *Template {
+	var tpl = &Template{
+		source: in,
+	}
+
+	var literal strings.Builder
+	var runes = []rune(in)
+
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; r {
+		case '{':
+			if i+1 < len(runes) && runes[i+1] == '{' {
+				literal.WriteRune('{')
+				i++
+				continue
+			}
+
+			if end := findSequenceEnd(runes, i+1); end > 0 {
+				if literal.Len() > 0 {
+					tpl.parts = append(tpl.parts, &templatePart{
+						literal: literal.String(),
+					})
+
+					literal.Reset()
+				}
+
+				tpl.parts = append(tpl.parts, compileSequence(string(runes[i+1:end])))
+				i = end
+			} else {
+				literal.WriteRune(r)
+			}
+
+		default:
+			literal.WriteRune(r)
+		}
+	}
+
+	if literal.Len() > 0 {
+		tpl.parts = append(tpl.parts, &templatePart{
+			literal: literal.String(),
+		})
+	}
+
+	return tpl
+}
+
+// locate the closing brace of an interpolation sequence, skipping over quoted strings
+func findSequenceEnd(runes []rune, start int) int {
+	var quote rune
+	var depth int
+
+	for i := start; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '{':
+			depth++
+		case r == '}':
+			if depth == 0 {
+				if i == start {
+					return -1
+				}
+
+				return i
+			}
+
+			depth--
+		}
+	}
+
+	return -1
+}
+
+func compileSequence(seq string) *templatePart {
+	var part = &templatePart{
+		sequence: true,
+	}
+
+	if key := strings.TrimSpace(seq); key != `` && !strings.ContainsAny(key, " \t'\"") {
+		part.legacyKey = key
+	}
+
+	var segments = splitTemplateSequence(seq, '|')
+
+	segments[len(segments)-1], part.format = splitTemplateFormat(segments[len(segments)-1])
+
+	if expr, err := compileTemplateExpression(segments[0]); err == nil {
+		part.expr = expr
+	} else {
+		return part
+	}
+
+	for _, segment := range segments[1:] {
+		if filter, err := compileTemplateFilter(segment); err == nil {
+			part.filters = append(part.filters, filter)
+		} else {
+			part.expr = nil
+			part.filters = nil
+			break
+		}
+	}
+
+	return part
+}
+
+// call fn with the position of each character in an interpolation sequence that is not part of a
+// string or regular expression literal, along with how deeply it is nested in brackets
+func scanTemplateSequence(runes []rune, fn func(i int, depth int)) {
+	var quote rune
+	var prev rune
+	var depth int
+
+	for i, r := range runes {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+
+			continue
+		}
+
+		switch {
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '/' && prev == '~':
+			quote = r
+		case r == '(' || r == '[' || r == '{':
+			fn(i, depth)
+			depth++
+		case r == ')' || r == ']' || r == '}':
+			depth--
+			fn(i, depth)
+		default:
+			fn(i, depth)
+		}
+
+		if !unicode.IsSpace(r) {
+			prev = r
+		}
+	}
+}
+
+// split an interpolation sequence wherever the separator appears outside of literals and brackets
+func splitTemplateSequence(seq string, sep rune) []string {
+	var runes = []rune(seq)
+	var parts []string
+	var start int
+
+	scanTemplateSequence(runes, func(i int, depth int) {
+		if depth == 0 && runes[i] == sep {
+			parts = append(parts, string(runes[start:i]))
+			start = i + 1
+		}
+	})
+
+	return append(parts, string(runes[start:]))
+}
+
+// separate a trailing printf-style format specifier from the rest of a sequence, e.g.: {total:%.2f}
+func splitTemplateFormat(seq string) (string, string) {
+	var runes = []rune(seq)
+	var at = -1
+
+	scanTemplateSequence(runes, func(i int, depth int) {
+		if depth == 0 && runes[i] == ':' && strings.HasPrefix(strings.TrimSpace(string(runes[i+1:])), `%`) {
+			at = i
+		}
+	})
+
+	if at >= 0 {
+		return string(runes[:at]), strings.TrimSpace(string(runes[at+1:]))
+	}
+
+	return seq, ``
+}
+
+// parse an expression from an interpolation sequence using the same grammar as script expressions
+func compileTemplateExpression(src string) (*templateExpr, error) {
+	var script = &Friendscript{
+		Buffer: templateToScript(src),
+		Pretty: true,
+		runtime: runtime{
+			scope: NewScope(nil),
+		},
+	}
+
+	script.Init()
+
+	if err := script.Parse(int(ruleExpression)); err == nil {
+		if node := script.AST(); node.rule() == ruleExpression && int(node.end) == len([]rune(script.Buffer)) {
+			return &templateExpr{
+				script: script,
+				node:   node,
+			}, nil
+		}
+
+		return nil, fmt.Errorf("invalid expression %q", src)
+	} else {
+		return nil, err
+	}
+}
+
+// filter := identifier ( '(' expression ( ',' expression )* ')' )?
+func compileTemplateFilter(src string) (*templateFilter, error) {
+	var filter = &templateFilter{
+		name: strings.TrimSpace(src),
+	}
+
+	if i := strings.IndexRune(filter.name, '('); i >= 0 && strings.HasSuffix(filter.name, `)`) {
+		var args = filter.name[i+1 : len(filter.name)-1]
+
+		filter.name = strings.TrimSpace(filter.name[:i])
+
+		if strings.TrimSpace(args) != `` {
+			for _, arg := range splitTemplateSequence(args, ',') {
+				if expr, err := compileTemplateExpression(arg); err == nil {
+					filter.args = append(filter.args, expr)
+				} else {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if !rxTemplateFilterName.MatchString(filter.name) {
+		return nil, fmt.Errorf("invalid filter name %q", filter.name)
+	}
+
+	return filter, nil
+}
+
+// Rewrite an expression from an interpolation sequence into script syntax.  Variables in templates
+// don't need a leading "$", and bare words in brackets are keys rather than variables, so
+// "user[tags][0]" is read as "$user['tags'][0]".
+func templateToScript(src string) string {
+	var runes = []rune(src)
+	var out strings.Builder
+	var written int
+	var field bool
+
+	scanTemplateSequence(runes, func(i int, _ int) {
+		if i < written {
+			return
+		} else if r := runes[i]; r != '_' && !unicode.IsLetter(r) {
+			// field names in filters are written as-is, e.g.: [?name.first == 'x']
+			if r == '?' && previousRune(runes, i) == '[' {
+				field = true
+			} else if r != '@' && r != '.' && !unicode.IsSpace(r) {
+				field = false
+			}
+
+			return
+		}
+
+		var end = i
+
+		for end < len(runes) && (runes[end] == '_' || unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+			end++
+		}
+
+		var word = string(runes[i:end])
+
+		out.WriteString(string(runes[written:i]))
+		written = end
+
+		switch {
+		case field, i > 0 && strings.ContainsRune(`$._`, runes[i-1]):
+			out.WriteString(word)
+		case i > 0 && (unicode.IsLetter(runes[i-1]) || unicode.IsDigit(runes[i-1])):
+			// units and suffixes of literals, e.g.: 10s, 5KB, 1.5D
+			out.WriteString(word)
+		case word == `true`, word == `false`, word == `null`, word == `now`:
+			out.WriteString(word)
+		case previousRune(runes, i) == '[' && nextRune(runes, end) == ']':
+			out.WriteString(`'` + word + `'`)
+		default:
+			out.WriteString(`$` + word)
+		}
+	})
+
+	out.WriteString(string(runes[written:]))
+	return out.String()
+}
+
+// return the last non-space character before the given position
+func previousRune(runes []rune, i int) rune {
+	for i--; i >= 0; i-- {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+
+	return 0
+}
+
+// return the first non-space character at or after the given position
+func nextRune(runes []rune, i int) rune {
+	for ; i < len(runes); i++ {
+		if !unicode.IsSpace(runes[i]) {
+			return runes[i]
+		}
+	}
+
+	return 0
+}
+
+// Render the template using values from the given scope.  An error is returned if any sequence
+// fails to evaluate.
+func (self *Template) Render(scope *Scope) (string, error) {
+	var out strings.Builder
+
+	for _, part := range self.parts {
+		if value, err := part.render(scope); err == nil {
+			out.WriteString(value)
+		} else {
+			return ``, err
+		}
+	}
+
+	return out.String(), nil
+}
+
+// render the template, replacing sequences that fail to evaluate with an empty string
+func (self *Template) render(scope *Scope) string {
+	var out strings.Builder
+
+	for _, part := range self.parts {
+		if value, err := part.render(scope); err == nil {
+			out.WriteString(value)
+		} else {
+			log.Warningf("interpolation failed: %v", err)
+		}
+	}
+
+	return out.String()
+}
+
+func (self *templatePart) render(scope *Scope) (string, error) {
+	if !self.sequence {
+		return self.literal, nil
+	}
+
+	if value, err := self.evaluate(scope); err == nil {
+		if isEmpty(value) {
+			return ``, nil
+		} else if self.format != `` {
+			return formatValue(self.format, value), nil
+		} else {
+			return typeutil.String(value), nil
+		}
+	} else {
+		return ``, err
+	}
+}
+
+// coerce numeric values to suit the verb in a printf-style format string
+func formatValue(format string, value interface{}) string {
+	if d, ok := value.(Decimal); ok {
+		value = d.Float64()
+	}
+
+	if typeutil.IsNumeric(value) {
+		switch format[len(format)-1] {
+		case 'e', 'E', 'f', 'F', 'g', 'G':
+			value = typeutil.Float(value)
+		case 'd', 'x', 'X', 'o', 'b', 'c':
+			if v := typeutil.Float(value); v == math.Trunc(v) {
+				value = int64(v)
+			}
+		}
+	}
+
+	return fmt.Sprintf(format, value)
+}
+
+func (self *Template) String() string {
+	return self.source
+}
+
+func (self *templatePart) evaluate(scope *Scope) (interface{}, error) {
+	var value interface{}
+
+	// sequences that name a variable directly (even one whose name contains characters that
+	// are otherwise considered operators) always resolve to that variable.
+	if self.legacyKey != `` {
+		if v := scope.Get(self.legacyKey); !isEmpty(v) {
+			value = v
+		}
+	}
+
+	if value == nil {
+		if self.expr == nil {
+			return nil, nil
+		} else if v, err := self.expr.eval(scope); err == nil {
+			value = v
+		} else if self.legacyKey != `` && len(self.filters) == 0 && self.format == `` {
+			// a sequence that could only have been a variable name renders as an empty string
+			// when that variable isn't set, as it always has.
+			return nil, nil
+		} else {
+			return nil, err
+		}
+	}
+
+	for _, filter := range self.filters {
+		if fn, ok := lookupTemplateFilter(filter.name); ok {
+			var args = make([]interface{}, len(filter.args))
+
+			for i, arg := range filter.args {
+				if v, err := arg.eval(scope); err == nil {
+					args[i] = v
+				} else {
+					return nil, err
+				}
+			}
+
+			if v, err := fn(value, args...); err == nil {
+				value = v
+			} else {
+				return nil, fmt.Errorf("filter %q: %v", filter.name, err)
+			}
+		} else {
+			return nil, fmt.Errorf("unknown filter %q", filter.name)
+		}
+	}
+
+	return intIfYouCan(value), nil
+}
+
+// an expression from an interpolation sequence
+type templateExpr struct {
+	script *Friendscript
+	node   *node32
+}
+
+func (self *templateExpr) eval(scope *Scope) (interface{}, error) {
+	// the parsed expression is shared by every render of a cached template, so each evaluation
+	// gets its own copy of the script to resolve variables against the given scope.
+	var script = *self.script
+
+	script.SetScope(scope)
+
+	var statement = &Statement{
+		node: self.node,
+		block: &Block{
+			friendscript: &script,
+			node:         self.node,
+		},
+	}
+
+	return NewExpression(statement, self.node).Value()
+}
@@ -0,0 +1,170 @@
+package scripting
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rxDurationPart = regexp.MustCompile(`(\d+(?:\.\d+)?)(ns|us|ms|s|m|h|d|w)`)
+var rxByteSize = regexp.MustCompile(`^(\d+(?:\.\d+)?)([kKMGTP]?i?B)$`)
+
+var durationUnits = map[string]time.Duration{
+	`ns`: time.Nanosecond,
+	`us`: time.Microsecond,
+	`ms`: time.Millisecond,
+	`s`:  time.Second,
+	`m`:  time.Minute,
+	`h`:  time.Hour,
+	`d`:  24 * time.Hour,
+	`w`:  7 * 24 * time.Hour,
+}
+
+var byteSizeUnits = map[string]float64{
+	`B`:   1,
+	`kB`:  1e3,
+	`KB`:  1e3,
+	`MB`:  1e6,
+	`GB`:  1e9,
+	`TB`:  1e12,
+	`PB`:  1e15,
+	`KiB`: 1 << 10,
+	`MiB`: 1 << 20,
+	`GiB`: 1 << 30,
+	`TiB`: 1 << 40,
+	`PiB`: 1 << 50,
+}
+
+// The layouts (in order of preference) that timestamp strings are parsed with.
+var TimestampLayouts = []string{
+	time.RFC3339Nano,
+	`2006-01-02T15:04:05.999999999Z0700`,
+	`2006-01-02T15:04:05.999999999`,
+	`2006-01-02T15:04Z07:00`,
+	`2006-01-02T15:04Z0700`,
+	`2006-01-02T15:04`,
+	`2006-01-02`,
+}
+
+// Parse a duration string (e.g.: "500ms", "2m30s", "1.5h", "1w2d").  In addition to the units
+// supported by time.ParseDuration, "d" (days) and "w" (weeks) are also accepted.
+func ParseDuration(in string) (time.Duration, error) {
+	var raw = strings.TrimSpace(in)
+	var negative bool
+
+	if strings.HasPrefix(raw, `-`) {
+		negative = true
+		raw = raw[1:]
+	}
+
+	var parts = rxDurationPart.FindAllStringSubmatchIndex(raw, -1)
+	var total float64
+	var offset int
+
+	if len(parts) == 0 {
+		return 0, fmt.Errorf("invalid duration %q", in)
+	}
+
+	for _, part := range parts {
+		if part[0] != offset {
+			return 0, fmt.Errorf("invalid duration %q", in)
+		}
+
+		if v, err := strconv.ParseFloat(raw[part[2]:part[3]], 64); err == nil {
+			total += v * float64(durationUnits[raw[part[4]:part[5]]])
+		} else {
+			return 0, err
+		}
+
+		offset = part[1]
+	}
+
+	if offset != len(raw) {
+		return 0, fmt.Errorf("invalid duration %q", in)
+	} else if total > math.MaxInt64 {
+		return 0, fmt.Errorf("duration %q is out of range", in)
+	}
+
+	if negative {
+		total = -total
+	}
+
+	return time.Duration(total), nil
+}
+
+// Parse an ISO-8601 timestamp (e.g.: "2024-01-15", "2024-01-15T10:30:00Z").  Timestamps without
+// a timezone are interpreted as UTC.
+func ParseTimestamp(in string) (time.Time, error) {
+	var raw = strings.TrimSpace(in)
+
+	for _, layout := range TimestampLayouts {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", in)
+}
+
+// Parse a byte size (e.g.: "512B", "10MiB", "1.5GB") into the number of bytes it represents.
+func ParseByteSize(in string) (int64, error) {
+	if match := rxByteSize.FindStringSubmatch(strings.TrimSpace(in)); match != nil {
+		if factor, ok := byteSizeUnits[match[2]]; ok {
+			if v, err := strconv.ParseFloat(match[1], 64); err == nil {
+				if size := v * factor; size <= math.MaxInt64 {
+					return int64(size), nil
+				}
+
+				return 0, fmt.Errorf("byte size %q is out of range", in)
+			} else {
+				return 0, err
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("invalid byte size %q", in)
+}
+
+// convert the given value into a time.Time (if it is one, or is a string that can be parsed as one)
+func toTime(in interface{}) (time.Time, bool) {
+	switch v := in.(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v != nil {
+			return *v, true
+		}
+	case string:
+		if t, err := ParseTimestamp(v); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// convert the given value into a time.Duration (if it is one, or is a string that can be parsed as one)
+func toDuration(in interface{}) (time.Duration, bool) {
+	switch v := in.(type) {
+	case time.Duration:
+		return v, true
+	case string:
+		if d, err := ParseDuration(v); err == nil {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
+
+func isTemporal(in interface{}) bool {
+	switch in.(type) {
+	case time.Time, *time.Time, time.Duration:
+		return true
+	default:
+		return false
+	}
+}
@@ -2,12 +2,18 @@ package friendscript
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/PerformLine/friendscript/scripting"
 	"github.com/PerformLine/friendscript/utils"
 	"github.com/PerformLine/go-stockutil/httputil"
 	"github.com/PerformLine/go-stockutil/maputil"
@@ -32,14 +38,55 @@ func newTestCommands(env utils.Runtime) *testCommands {
 }
 
 func (self *testCommands) MapArg(key string, m map[string]interface{}) error {
-	self.env.Scope().Set(strings.TrimSpace(key), m)
-	return nil
+	return self.env.Scope().TrySet(strings.TrimSpace(key), m)
+}
+
+func (self *testCommands) Echo(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+type testParentCommands struct {
+	utils.Module
+	children map[string]utils.Module
+}
+
+func (self *testParentCommands) Submodule(name string) (utils.Module, bool) {
+	child, ok := self.children[name]
+	return child, ok
+}
+
+// a command whose name is now a keyword, and so can only be called with its module's name
+func (self *testCommands) Exit(status int) (int, error) {
+	return status, nil
 }
 
 func (self *testCommands) Noop() error {
 	return nil
 }
 
+// sleeps without watching the runtime context, so it can't be cancelled
+func (self *testCommands) Sleep(d time.Duration) error {
+	time.Sleep(d)
+	return nil
+}
+
+func (self *testCommands) Panic(message string) error {
+	panic(message)
+}
+
+type testTemporalArgs struct {
+	Timeout time.Duration `json:"timeout"`
+	Since   time.Time     `json:"since"`
+}
+
+func (self *testCommands) Temporal(d time.Duration, args *testTemporalArgs) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		`duration`: d,
+		`timeout`:  args.Timeout,
+		`since`:    args.Since,
+	}, nil
+}
+
 func eval(script string, items ...interface{}) (map[string]interface{}, error) {
 	env := NewEnvironment()
 	env.RegisterModule(`testing`, newTestCommands(env))
@@ -83,12 +130,12 @@ func TestAssignments(t *testing.T) {
 		`c2`:   "Test {c}",
 		`d`:    3.14159,
 		`e`: []interface{}{
-			float64(1),
+			1,
 			true,
 			"Test",
 			3.14159,
 			[]interface{}{
-				float64(1),
+				1,
 				true,
 				"Test",
 				3.14159,
@@ -118,7 +165,7 @@ func TestAssignments(t *testing.T) {
 		`x`: "Test",
 		`y`: 3.14159,
 		`z`: []interface{}{
-			float64(1),
+			1,
 			true,
 			"Test",
 			3.14159,
@@ -180,11 +227,11 @@ func TestAssignments(t *testing.T) {
 		`put_1`: `test 1`,
 		`put_2`: `test {a}`,
 		`put_3`: []interface{}{
-			float64(1),
-			float64(2),
-			float64(3),
+			1,
+			2,
+			3,
 		},
-		`put_4`: "put test four\n\t\tput test\n\t\tput end\n\t\tend friend end",
+		`put_4`: "put test four\nput test\nput end\nend friend end",
 		`t_maparg`: map[string]interface{}{
 			`one`:   `first`,
 			`two`:   `second`,
@@ -448,6 +495,448 @@ func TestConditionals(t *testing.T) {
 	assert.NoError(err)
 	// fmt.Println(jsondiff(expected, actual))
 	assert.Equal(expected, actual)
+
+	// sequences that fail to evaluate fail the statement they appear in
+	_, err = eval(`$x = "total: {1 / 0}"`)
+	assert.Error(err)
+
+	_, err = eval(`$x = "{name | nope}"`)
+	assert.Error(err)
+}
+
+func TestRegexCaptures(t *testing.T) {
+	assert := require.New(t)
+
+	expected := map[string]interface{}{
+		`line`:  `user id=42 name=bob`,
+		`text`:  `a=1 b=2 c=3`,
+		`id`:    `42`,
+		`name`:  `bob`,
+		`whole`: `id=42`,
+		`elif`:  `bob`,
+		`first`: `a`,
+		`pairs`: []interface{}{`a:1`, `b:2`, `c:3`},
+	}
+
+	script := `
+        $line = "user id=42 name=bob"
+        $text = "a=1 b=2 c=3"
+        $id = null
+        $name = null
+        $whole = null
+        $elif = null
+        $first = null
+        $pairs = null
+
+        if $line =~ /id=(\d+) name=(?P<name>\w+)/ {
+            $id = $match[1]
+            $name = $match.name
+        }
+
+        if $line =~ /id=\d+/ {
+            $whole = $match[0]
+        }
+
+        if $line =~ /nope/ {
+            $elif = "wrong"
+        } else if $line =~ /name=(?P<who>\w+)/ {
+            $elif = $match.who
+        }
+
+        loop $m in $text =~ /(\w)=(\d)/ {
+            $first = $m[1]
+        }
+
+        loop $m in $text =~ /(\w)=(\d)/g {
+            $pairs << "{m.1}:{m.2}"
+        }`
+
+	actual, err := eval(script)
+	assert.NoError(err)
+	assert.Equal(expected, actual)
+}
+
+func TestSwitch(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $results = null
+        $admins = ['alice', 'carol']
+        $values = [1, 2, 3, 'v1.2', 'alice', 'zed', null]
+
+        loop $value in $values {
+            switch $value {
+            # numbers
+            case 1, 2 {
+                $results << "low:{value}"
+            }
+            case 3 {
+                $results << "three"
+            }
+            case /^v(\d+)\.(\d+)$/ {
+                $results << "version:{match.1}.{match.2}"
+            }
+            case in $admins {
+                $results << "admin:{value}"
+            }
+            case null {
+                $results << "null"
+            }
+            default {
+                $results << "other:{value}"
+            }
+            }
+        }
+
+        $unmatched = 'untouched'
+
+        switch 42 {
+        case 1 {
+            $unmatched = 'wrong'
+        }
+        }
+
+        $compound = 'untouched'
+
+        switch {a: 1} {
+        case 1 {
+            $compound = 'wrong'
+        }
+        default {
+            $compound = 'default'
+        }
+        }
+
+        $count = 0
+
+        loop $i in $values {
+            switch $i {
+            case 3 {
+                break
+            }
+            }
+
+            $count += 1
+        }
+    `)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{
+		`low:1`,
+		`low:2`,
+		`three`,
+		`version:1.2`,
+		`admin:alice`,
+		`other:zed`,
+		`null`,
+	}, actual[`results`])
+	assert.Nil(actual[`match`])
+	assert.Equal(`untouched`, actual[`unmatched`])
+	assert.Equal(`default`, actual[`compound`])
+	assert.Equal(2, actual[`count`])
+}
+
+func TestStrings(t *testing.T) {
+	assert := require.New(t)
+
+	expected := map[string]interface{}{
+		`name`:    `friend`,
+		`quoted`:  `say "hello" friend`,
+		`escaped`: "one\ttwo\nthree\\four",
+		`unicode`: "\u2211 \u00e9 \U0001F600 A",
+		`unknown`: `C:\path\My Files`,
+		`raw`:     `no {name} or \n here`,
+		`rawkey`:  map[string]interface{}{`a-b`: `raw`},
+		`heredoc`: "first\n  indented\n\nlast",
+		`inline`:  `inline`,
+	}
+
+	script := "$name = 'friend'\n" +
+		`$quoted = "say \"hello\" {name}"
+        $escaped = "one\ttwo\nthree\\four"
+        $unicode = "\u2211 \u{e9} \u{1F600} \x41"
+        $unknown = "C:\path\My Files"
+        $raw = ` + "`no {name} or \\n here`" + `
+        $rawkey = {` + "`a-b`" + `: 'raw'}
+        $heredoc = """
+            first
+              indented
+
+            last
+        """
+        $inline = """inline"""`
+
+	actual, err := eval(script)
+	assert.NoError(err)
+	assert.Equal(expected, actual)
+}
+
+func TestTemporalLiterals(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $short = 500ms
+        $long = 2m30s
+        $days = 1w2d
+        $fractional = 1.5h
+        $negative = -5m
+        $date = 2024-01-15
+        $ts = 2024-01-15T10:30:00Z
+        $offset = 2024-01-15T10:30:00+02:00
+        $size = 10MiB
+        $decimal = 1.5KB
+        $sum = $long + 30s
+        $diff = $ts - $date
+        $later = $ts + 1d
+        $scaled = $short * 3
+        $ratio = $long / 30s
+        $deadline = now + 10m
+        $remaining = false
+        $before = false
+        $sameTime = false
+
+        if $deadline - now > 5m {
+            $remaining = true
+        }
+
+        if $date < $ts {
+            $before = true
+        }
+
+        if $offset == 2024-01-15T08:30:00Z {
+            $sameTime = true
+        }
+
+        testing::temporal "1m" {
+            timeout: 5s,
+            since:   "2024-01-15T10:30:00Z",
+        } -> $converted
+    `)
+
+	assert.NoError(err)
+
+	ts := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	assert.Equal(500*time.Millisecond, actual[`short`])
+	assert.Equal(150*time.Second, actual[`long`])
+	assert.Equal(9*24*time.Hour, actual[`days`])
+	assert.Equal(90*time.Minute, actual[`fractional`])
+	assert.Equal(-5*time.Minute, actual[`negative`])
+	assert.Equal(time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC), actual[`date`])
+	assert.Equal(ts, actual[`ts`])
+	assert.Equal(10*1024*1024, actual[`size`])
+	assert.Equal(1500, actual[`decimal`])
+	assert.Equal(3*time.Minute, actual[`sum`])
+	assert.Equal(10*time.Hour+30*time.Minute, actual[`diff`])
+	assert.Equal(ts.Add(24*time.Hour), actual[`later`])
+	assert.Equal(1500*time.Millisecond, actual[`scaled`])
+	assert.Equal(5, actual[`ratio`])
+	assert.IsType(time.Time{}, actual[`deadline`])
+	assert.Equal(true, actual[`remaining`])
+	assert.Equal(true, actual[`before`])
+	assert.Equal(true, actual[`sameTime`])
+	assert.Equal(map[string]interface{}{
+		`duration`: time.Minute,
+		`timeout`:  5 * time.Second,
+		`since`:    ts,
+	}, actual[`converted`])
+}
+
+func TestNumericTypes(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $big = 9007199254740993
+        $bigger = $big + 2
+        $product = 3037000499 * 3037000499
+        $quotient = 7 / 2
+        $fquotient = 7.0 / 2
+        $remainder = -7 % 3
+        $power = 2 ** 62
+        $mixed = 1 + 0.5
+        $count = 0
+        $count += 1
+        $count *= 10
+        $count /= 4
+        $unset += 5
+        $price = 19.99D
+        $total = $price * 3
+        $tax = $total * 0.08D
+        $split = 10.00D / 3
+        $sum = 0.1D + 0.2D
+        $dmod = 7.25D % 2
+        $dnegmod = -7.5D % 2
+        $numeric = 1
+        $numeric += "2"
+        $text = 'a'
+        $text += 'b'
+        $exact = false
+        $larger = false
+        $a, $b = 1, 2 + 3
+
+        if $sum == 0.3D {
+            $exact = true
+        }
+
+        if $big + 1 > $big {
+            $larger = true
+        }
+    `)
+
+	assert.NoError(err)
+	assert.Equal(9007199254740993, actual[`big`])
+	assert.Equal(9007199254740995, actual[`bigger`])
+	assert.Equal(9223372030926249001, actual[`product`])
+	assert.Equal(3, actual[`quotient`])
+	assert.Equal(3.5, actual[`fquotient`])
+	assert.Equal(-1, actual[`remainder`])
+	assert.Equal(4611686018427387904, actual[`power`])
+	assert.Equal(1.5, actual[`mixed`])
+	assert.Equal(2, actual[`count`])
+	assert.Equal(5, actual[`unset`])
+	assert.Equal(`19.99`, fmt.Sprintf("%v", actual[`price`]))
+	assert.Equal(`59.97`, fmt.Sprintf("%v", actual[`total`]))
+	assert.Equal(`4.7976`, fmt.Sprintf("%v", actual[`tax`]))
+	assert.Equal(`3.3333333333333333`, fmt.Sprintf("%v", actual[`split`]))
+	assert.Equal(`1.25`, fmt.Sprintf("%v", actual[`dmod`]))
+	assert.Equal(`-1.5`, fmt.Sprintf("%v", actual[`dnegmod`]))
+	assert.Equal(3, actual[`numeric`])
+	assert.Equal(`ab`, actual[`text`])
+	assert.Equal(true, actual[`exact`])
+	assert.Equal(true, actual[`larger`])
+	assert.Equal(1, actual[`a`])
+	assert.Equal(5, actual[`b`])
+
+	for _, overflow := range []string{
+		`$x = 9223372036854775807 + 1`,
+		`$x = 3037000500 * 3037000500`,
+		`$x = 2 ** 63`,
+		`$x = -9223372036854775807 - 2`,
+	} {
+		_, err = eval(overflow)
+		assert.Error(err, overflow)
+		assert.Contains(err.Error(), `integer overflow`)
+	}
+
+	_, err = eval(`$x = 1 / 0`)
+	assert.Error(err)
+
+	_, err = eval(`$x = 1D % 0`)
+	assert.Error(err)
+}
+
+func TestCollectionOperators(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $concat = [1, 2] + [3, 4]
+        $merged = {a: 1, nested: {x: 1, y: 2}, list: [1]} + {b: 2, nested: {y: 3}, list: [2]}
+        $difference = [1, 2, 2, 3, 4] - [2, 4]
+        $intersection = [1, 2, 2, 3] & [2, 3, 5]
+        $union = [1, 2, 2] | [2, 3]
+        $dashes = '-' * 3
+        $repeated = [1, 2] * 2
+        $prefixed = 2 * 'ab'
+        $acc = null
+        $acc += [1]
+        $acc += [2, 3]
+        $acc -= [1]
+        $acc |= [3, 4]
+        $acc &= [2, 4]
+        $obj = {a: 1}
+        $obj += {b: 2}
+    `)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{1, 2, 3, 4}, actual[`concat`])
+	assert.Equal(map[string]interface{}{
+		`a`: 1,
+		`b`: 2,
+		`nested`: map[string]interface{}{
+			`x`: 1,
+			`y`: 3,
+		},
+		`list`: []interface{}{2},
+	}, actual[`merged`])
+	assert.Equal([]interface{}{1, 3}, actual[`difference`])
+	assert.Equal([]interface{}{2, 3}, actual[`intersection`])
+	assert.Equal([]interface{}{1, 2, 3}, actual[`union`])
+	assert.Equal(`---`, actual[`dashes`])
+	assert.Equal([]interface{}{1, 2, 1, 2}, actual[`repeated`])
+	assert.Equal(`abab`, actual[`prefixed`])
+	assert.Equal([]interface{}{2, 4}, actual[`acc`])
+	assert.Equal(map[string]interface{}{`a`: 1, `b`: 2}, actual[`obj`])
+
+	_, err = eval(`$x = [1] - {a: 1}`)
+	assert.Error(err)
+
+	// repeating something too many times is an error rather than a crash
+	_, err = eval(`$x = [1, 2] * 9223372036854775807`)
+	assert.Error(err)
+
+	_, err = eval(`$x = 'ab' * 9223372036854775807`)
+	assert.Error(err)
+
+	actual, err = eval(`$x = '' * 9223372036854775807`)
+	assert.NoError(err)
+	assert.Empty(actual[`x`])
+}
+
+func TestVariablePaths(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $items = ['a', 'b', 'c', 'd', 'e']
+        $users = [{name: 'alice', age: 31, email: 'alice@example.com', active: true}, {name: 'bob', age: 25, email: 'bob@example.com', active: false}, {name: 'carol', age: 42, email: 'carol@example.com'}]
+        $matrix = [[1, 2], [3, 4]]
+        $last = $items[-1]
+        $secondLast = $items[-2]
+        $middle = $items[1:3]
+        $head = $items[:2]
+        $tail = $items[-2:]
+        $word = 'friendscript'
+        $prefix = $word[0:6]
+        $emails = $users[*].email
+        $statuses = $users[*].active
+        $older = $users[?age > 30].name
+        $named = $users[?name =~ /^b/].email
+        $enabled = $users[?active].name
+        $cell = $matrix[1][0]
+        $scores = [72, 95, 90, 88]
+        $high = $scores[?@ >= 90]
+        $interpolated = "{users[-1].name} {items[1:3] | join('')} {users[?age < 30].name | join(',')}"
+        $items[-1] = 'z'
+        $users[*].verified = true
+        $users[?age > 40].senior = true
+        $i = 0
+
+        loop $u in $users[?age >= 30] {
+            $i += 1
+        }
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`e`, actual[`last`])
+	assert.Equal(`d`, actual[`secondLast`])
+	assert.Equal([]interface{}{`b`, `c`}, actual[`middle`])
+	assert.Equal([]interface{}{`a`, `b`}, actual[`head`])
+	assert.Equal([]interface{}{`d`, `e`}, actual[`tail`])
+	assert.Equal(`friend`, actual[`prefix`])
+	assert.Equal([]interface{}{`alice@example.com`, `bob@example.com`, `carol@example.com`}, actual[`emails`])
+	assert.Equal([]interface{}{true, false, nil}, actual[`statuses`])
+	assert.Equal([]interface{}{`alice`, `carol`}, actual[`older`])
+	assert.Equal([]interface{}{`bob@example.com`}, actual[`named`])
+	assert.Equal([]interface{}{`alice`}, actual[`enabled`])
+	assert.Equal(3, actual[`cell`])
+	assert.Equal([]interface{}{95, 90}, actual[`high`])
+	assert.Equal(`carol bc bob`, actual[`interpolated`])
+	assert.Equal([]interface{}{`a`, `b`, `c`, `d`, `z`}, actual[`items`])
+	assert.Equal(2, actual[`i`])
+
+	for i, user := range actual[`users`].([]interface{}) {
+		assert.Equal(true, user.(map[string]interface{})[`verified`], i)
+		assert.Equal(i == 2, user.(map[string]interface{})[`senior`] == true, i)
+	}
 }
 
 func TestExpressions(t *testing.T) {
@@ -462,9 +951,14 @@ func TestExpressions(t *testing.T) {
 		`bb`:    6,
 		`cc`:    20,
 		`dd`:    5,
+		`e`:     -610,
 		`f`:     `This 2 is {b} and done`,
+		`g`:     5,
+		`h`:     10,
+		`i`:     512,
+		`j`:     `5 10 512 -610`,
 		`put_a`: `this is some stuff`,
-		`put_b`: "buncha\n    muncha\n    cruncha\n    lines",
+		`put_b`: "buncha\nmuncha\ncruncha\nlines",
 	}
 
 	script := `
@@ -472,7 +966,7 @@ func TestExpressions(t *testing.T) {
         $b = 9 - 3
         $c = 5 * 4
         $d = 50 / 10
-        #$e = 4 * -6 * (3 * 7 + 5) + 2 * 7
+        $e = 4 * -6 * (3 * 7 + 5) + 2 * 7
         $aa = 1
         $aa += 1
         $bb = 9
@@ -482,6 +976,10 @@ func TestExpressions(t *testing.T) {
         $dd = 50
         $dd /= 10
         $f = "This {a}" + ' is {b}' + " and done"
+        $g = 10 - 2 - 3
+        $h = 2 * 3 + 4
+        $i = 2 ** 3 ** 2
+        $j = "{10 - 2 - 3} {2 * 3 + 4} {2 ** 3 ** 2} {4 * -6 * (3 * 7 + 5) + 2 * 7}"
 
         put """
             this is some stuff
@@ -499,6 +997,55 @@ func TestExpressions(t *testing.T) {
 	assert.Equal(expected, actual)
 }
 
+func TestTernaryAndCoalesce(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        $count = 3
+        $name = null
+        declare $declared
+        $zero = 0
+
+        $plural = $count == 1 ? 'item' : 'items'
+        $truthy = $count ? 'yes' : 'no'
+        $falsy = $zero ? 'yes' : 'no'
+        $grouped = $count + 1 > 3 ? 'big' : 'small'
+        $nested = $count > 5 ? 'large' : $count > 1 ? 'medium' : 'small'
+        $matched = 'v1.2' =~ /^v\d/ ? 'version' : 'other'
+        $member = 2 in [1, 2] ? 'in' : 'out'
+        $who = $name ?? 'anonymous'
+        $unset = $declared ?? 'fallback'
+        $kept = $zero ?? 42
+        $chained = $name ?? $declared ?? 'last'
+        $sum = $name ?? 1 + 2
+        $message = "{count} {count == 1 ? 'item' : 'items'} for {name ?? 'nobody'}"
+
+        put {
+            label: $name ?? 'default',
+            size:  $count > 2 ? 'L' : 'S',
+        } -> $options
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`items`, actual[`plural`])
+	assert.Equal(`yes`, actual[`truthy`])
+	assert.Equal(`no`, actual[`falsy`])
+	assert.Equal(`big`, actual[`grouped`])
+	assert.Equal(`medium`, actual[`nested`])
+	assert.Equal(`version`, actual[`matched`])
+	assert.Equal(`in`, actual[`member`])
+	assert.Equal(`anonymous`, actual[`who`])
+	assert.Equal(`fallback`, actual[`unset`])
+	assert.Equal(0, actual[`kept`])
+	assert.Equal(`last`, actual[`chained`])
+	assert.Equal(3, actual[`sum`])
+	assert.Equal(`3 items for nobody`, actual[`message`])
+	assert.Equal(map[string]interface{}{
+		`label`: `default`,
+		`size`:  `L`,
+	}, actual[`options`])
+}
+
 func TestLoops(t *testing.T) {
 	assert := require.New(t)
 
@@ -508,17 +1055,17 @@ func TestLoops(t *testing.T) {
 		`double_continue`: []interface{}{8, 9},
 		`iterations`:      4,
 		`things`: []interface{}{
-			float64(1),
-			float64(2),
-			float64(3),
-			float64(4),
-			float64(5),
+			1,
+			2,
+			3,
+			4,
+			5,
 		},
 		`topindex`: 9,
 		`map`: map[string]interface{}{
-			`first`:  float64(1),
-			`second`: float64(2),
-			`third`:  float64(3),
+			`first`:  1,
+			`second`: 2,
+			`third`:  3,
 		},
 		`m1`: `first:1`,
 		`m2`: `second:2`,
@@ -584,6 +1131,676 @@ func TestLoops(t *testing.T) {
 	assert.Equal(expected, actual)
 }
 
+func TestReturnAndExit(t *testing.T) {
+	assert := require.New(t)
+
+	// return ends the script early
+	actual, err := eval(`
+        $before = true
+        $items = [1, 2, 3]
+
+        loop $i in $items {
+            if $i == 2 {
+                return $i * 10
+            }
+
+            $last = $i
+        }
+
+        $after = true
+    `)
+
+	assert.NoError(err)
+	assert.Equal(true, actual[`before`])
+	assert.Equal(1, actual[`last`])
+	assert.Nil(actual[`after`])
+
+	// exit surfaces its status code as an ExitError
+	_, err = eval(`
+        $code = 3
+
+        if $code > 0 {
+            exit $code
+        }
+
+        $after = true
+    `)
+
+	assert.Error(err)
+
+	status, ok := scripting.ExitStatus(err)
+	assert.True(ok)
+	assert.Equal(3, status)
+
+	_, err = eval(`exit`)
+	status, ok = scripting.ExitStatus(err)
+	assert.True(ok)
+	assert.Equal(0, status)
+
+	// the value given to return is the result of running a script
+	dir, err := ioutil.TempDir(``, `friendscript-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `child.fs`), []byte(`
+        $ignored = 'not me'
+        return {status: 'done', value: $input * 2}
+    `), 0644))
+
+	t.Setenv(`FRIENDSCRIPT_PATH`, dir)
+
+	actual, err = eval(`
+        run 'child' {
+            data: {input: 21},
+        } -> $result
+    `)
+
+	assert.NoError(err)
+	assert.Equal(map[string]interface{}{
+		`status`: `done`,
+		`value`:  42,
+	}, actual[`result`])
+
+	// exit ends the whole evaluation, even from within another script
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `bail.fs`), []byte("exit 4\n"), 0644))
+
+	_, err = eval(`
+        run 'bail'
+        $after = true
+    `)
+
+	status, ok = scripting.ExitStatus(err)
+	assert.True(ok)
+	assert.Equal(4, status)
+
+	// commands named "exit" are only reachable by their qualified name
+	actual, err = eval(`testing::exit 5 -> $code`)
+	assert.NoError(err)
+	assert.Equal(5, actual[`code`])
+}
+
+func TestDefer(t *testing.T) {
+	assert := require.New(t)
+
+	// deferred blocks run last-in, first-out after the rest of the script
+	actual, err := eval(`
+        $order = null
+
+        defer {
+            $order << 'first'
+        }
+
+        defer {
+            $order << 'second'
+        }
+
+        $order << 'body'
+    `)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{`body`, `second`, `first`}, actual[`order`])
+
+	// blocks deferred inside a conditional, loop, or other block run when that block finishes
+	actual, err = eval(`
+        $order = null
+
+        defer {
+            $order << 'script'
+        }
+
+        if 1 == 1 {
+            $local = 'inner'
+
+            defer {
+                $order << "if:{local}"
+            }
+
+            $order << 'if'
+        }
+
+        $values = [1, 2]
+
+        loop $i in $values {
+            defer {
+                $order << "cleanup:{i}"
+            }
+
+            $order << "loop:{i}"
+
+            if $i == 2 {
+                break
+            }
+        }
+
+        $order << 'body'
+    `)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{
+		`if`, `if:inner`,
+		`loop:1`, `cleanup:1`,
+		`loop:2`, `cleanup:2`,
+		`body`, `script`,
+	}, actual[`order`])
+
+	// ...including timeouts that have expired
+	scope, err := NewEnvironment().EvaluateString(`
+        $cleaned = false
+
+        timeout 10ms {
+            defer {
+                $cleaned = true
+            }
+
+            wait 1s
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `timeout`)
+	assert.Equal(true, scope.Get(`cleaned`))
+
+	// ...even when the timeout expires inside a block nested within it
+	scope, err = NewEnvironment().EvaluateString(`
+        $cleaned = false
+
+        timeout 10ms {
+            if 1 == 1 {
+                defer {
+                    $cleaned = true
+                }
+
+                wait 1s
+            }
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `timeout`)
+	assert.Equal(true, scope.Get(`cleaned`))
+
+	// a return still happens when a block deferred on the way out fails, and the error is reported
+	scope, err = NewEnvironment().EvaluateString(`
+        if 1 == 1 {
+            defer {
+                fail 'cleanup failed'
+            }
+
+            return 5
+        }
+
+        $after = true
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `cleanup failed`)
+	assert.Nil(scope.Get(`after`))
+
+	value, ok := scope.ReturnValue()
+	assert.True(ok)
+	assert.EqualValues(5, value)
+
+	// a deferred block that fails inside a loop stops the loop, even when it is breaking out
+	actual, err = eval(`
+        $values = [1, 2, 3]
+
+        loop $i in $values {
+            defer {
+                fail "cleanup {i} failed"
+            }
+
+            break
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `cleanup 1 failed`)
+
+	// deferred blocks still run when a command fails, and their errors are added to the original
+	env := NewEnvironment()
+
+	scope, err = env.EvaluateString(`
+        $cleaned = false
+
+        defer {
+            $cleaned = true
+        }
+
+        defer {
+            fail 'cleanup failed'
+        }
+
+        fail 'something broke'
+        $after = true
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `something broke`)
+	assert.Contains(err.Error(), `cleanup failed`)
+	assert.Equal(true, scope.Get(`cleaned`))
+	assert.Nil(scope.Get(`after`))
+
+	// ...and when the script exits early
+	scope, err = env.EvaluateString(`
+        $cleaned = false
+
+        defer {
+            $cleaned = true
+        }
+
+        exit 2
+    `)
+
+	status, ok := scripting.ExitStatus(err)
+	assert.True(ok)
+	assert.Equal(2, status)
+	assert.Equal(true, scope.Get(`cleaned`))
+
+	// ...and when evaluation panics, leaving no frames of deferred blocks behind
+	env = NewEnvironment()
+	env.RegisterModule(`testing`, newTestCommands(env))
+
+	assert.Panics(func() {
+		env.EvaluateString(`
+            $cleaned = false
+
+            defer {
+                $cleaned = true
+            }
+
+            if 1 == 1 {
+                testing::panic 'boom'
+            }
+        `)
+	})
+
+	assert.Equal(true, env.Get(`cleaned`))
+	assert.Empty(env.deferred)
+}
+
+func TestConstants(t *testing.T) {
+	assert := require.New(t)
+
+	actual, err := eval(`
+        const $API_BASE = 'https://example.com'
+        const $LIMIT, $NAME = 10, 'test'
+
+        $url = "{API_BASE}/items?limit={LIMIT}"
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`https://example.com`, actual[`API_BASE`])
+	assert.Equal(10, actual[`LIMIT`])
+	assert.Equal(`test`, actual[`NAME`])
+	assert.Equal(`https://example.com/items?limit=10`, actual[`url`])
+
+	// reassignment is an error that points to the offending line
+	for _, script := range []string{
+		"const $API_BASE = 'a'\n\n$API_BASE = 'b'",
+		"const $API_BASE = 'a'\n\n$API_BASE += 'b'",
+		"const $API_BASE = 'a'\n\nconst $API_BASE = 'b'",
+		"const $API_BASE = 'a'\n$items = [1, 2]\nloop $x in $items { $API_BASE = $x }",
+		"const $API_BASE = 'a'\n$items = [1, 2]\nif 1 == 1 { $API_BASE = 'b' }",
+		"const $API_BASE = 'a'\n\nvars::set 'API_BASE' { value: 'b' }",
+	} {
+		_, err := eval(script)
+		assert.Error(err, script)
+		assert.Contains(err.Error(), `cannot assign to constant $API_BASE`, script)
+		assert.Contains(err.Error(), `line 3`, script)
+	}
+
+	_, err = eval("const $API_BASE = 'a'\n$items = [1, 2]\nloop $API_BASE in $items {}")
+	assert.Error(err)
+	assert.Contains(err.Error(), `cannot assign to constant $API_BASE`)
+
+	_, err = eval("const $API_BASE = 'a'\nenv 'HOME' -> $API_BASE")
+	assert.Error(err)
+	assert.Contains(err.Error(), `line 2: cannot assign to constant $API_BASE`)
+
+	_, err = eval(`const $list << 1`)
+	assert.Error(err)
+
+	// read-only variables injected by the host
+	env := NewEnvironment(map[string]interface{}{
+		`api_key`: scripting.Constant(`secret`),
+		`region`:  `us-east-1`,
+	})
+
+	env.SetData(map[string]interface{}{
+		`tenant`: scripting.Constant(`acme`),
+	})
+
+	scope, err := env.EvaluateString(`
+        $region = 'eu-west-1'
+        $key = "{tenant}:{api_key}"
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`eu-west-1`, scope.Get(`region`))
+	assert.Equal(`acme:secret`, scope.Get(`key`))
+
+	_, err = env.EvaluateString(`$api_key = 'stolen'`)
+	assert.Error(err)
+	assert.Equal(`secret`, env.Get(`api_key`))
+
+	assert.Error(env.TrySet(`tenant`, `other`))
+	assert.Equal(`acme`, env.Get(`tenant`))
+
+	// Set keeps its original signature, and skips values that can't be set
+	env.Set(`tenant`, `other`)
+	assert.Equal(`acme`, env.Get(`tenant`))
+
+	// data that can't be set is skipped instead of stopping the environment from being created
+	env = NewEnvironment(map[string]interface{}{
+		`api_key`: scripting.Constant(`secret`),
+	}, map[string]interface{}{
+		`api_key`:     scripting.Constant(`other`),
+		`deep.config`: scripting.Constant(true),
+		`region`:      `us-east-1`,
+	})
+
+	assert.Equal(`secret`, env.Get(`api_key`))
+	assert.Nil(env.Get(`deep.config`))
+	assert.Equal(`us-east-1`, env.Get(`region`))
+
+	// ...including from scripts started with "run", isolated or not
+	dir, err := ioutil.TempDir(``, `friendscript-const-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `child.fs`), []byte("$api_key = 'stolen'\n"), 0644))
+	t.Setenv(`FRIENDSCRIPT_PATH`, dir)
+
+	for _, isolated := range []bool{true, false} {
+		_, err = env.EvaluateString(fmt.Sprintf("run 'child' { isolated: %v }", isolated))
+		assert.Error(err)
+		assert.Contains(err.Error(), `cannot assign to constant $api_key`)
+	}
+}
+
+func TestParams(t *testing.T) {
+	assert := require.New(t)
+
+	script, err := scripting.Parse(`
+        #!/usr/bin/env friendscript
+        param $url: string required
+        param $retries: int = 3
+        param $verbose: boolean = false
+        param $tags: list
+        param $timeout: duration = 5s
+        param $anything
+
+        $done = true
+    `)
+
+	assert.NoError(err)
+
+	params, err := script.Params()
+	assert.NoError(err)
+	assert.Len(params, 6)
+
+	assert.Equal(`url`, params[0].Name)
+	assert.Equal(scripting.ParamString, params[0].Type)
+	assert.True(params[0].Required)
+	assert.False(params[0].HasDefault)
+	assert.Equal(3, params[0].Line)
+
+	assert.Equal(`retries`, params[1].Name)
+	assert.Equal(scripting.ParamInt, params[1].Type)
+	assert.False(params[1].Required)
+	assert.True(params[1].HasDefault)
+	assert.EqualValues(3, params[1].Default)
+
+	assert.Equal(scripting.ParamBool, params[2].Type)
+	assert.Equal(false, params[2].Default)
+	assert.Equal(scripting.ParamArray, params[3].Type)
+	assert.False(params[3].HasDefault)
+	assert.Equal(5*time.Second, params[4].Default)
+	assert.Equal(scripting.ParamAny, params[5].Type)
+
+	// defaults and type coercion
+	env := NewEnvironment(map[string]interface{}{
+		`url`:  `https://example.com`,
+		`tags`: `a,b,c`,
+	})
+
+	scope, err := env.Evaluate(script)
+	assert.NoError(err)
+	assert.Equal(`https://example.com`, scope.Get(`url`))
+	assert.Equal(3, scope.Get(`retries`))
+	assert.Equal(false, scope.Get(`verbose`))
+	assert.Equal([]interface{}{`a`, `b`, `c`}, scope.Get(`tags`))
+	assert.Equal(5*time.Second, scope.Get(`timeout`))
+	assert.Nil(scope.Get(`anything`))
+	assert.Equal(true, scope.Get(`done`))
+
+	// integers keep their precision
+	for _, value := range []interface{}{`9007199254740993`, int64(9007199254740993)} {
+		i, err := scripting.ParamInt.Coerce(value)
+		assert.NoError(err)
+		assert.Equal(int64(9007199254740993), i)
+	}
+
+	i, err := scripting.ParamInt.Coerce(`3.0`)
+	assert.NoError(err)
+	assert.Equal(int64(3), i)
+
+	_, err = scripting.ParamInt.Coerce(`3.5`)
+	assert.Error(err)
+
+	// all missing and invalid parameters are reported, and nothing is evaluated
+	env = NewEnvironment(map[string]interface{}{
+		`retries`: `lots`,
+		`verbose`: `yes`,
+	})
+
+	scope, err = env.Evaluate(script)
+	assert.Error(err)
+	assert.Contains(err.Error(), `missing required parameter $url`)
+	assert.Contains(err.Error(), `invalid value for parameter $retries`)
+	assert.NotContains(err.Error(), `$verbose`)
+	assert.Nil(scope.Get(`done`))
+
+	var perr *scripting.ParamError
+	assert.True(errors.As(err, &perr))
+	assert.Equal(`url`, perr.Param.Name)
+	assert.True(perr.Missing)
+
+	// parameters given to scripts started with "run"
+	dir, err := ioutil.TempDir(``, `friendscript-params-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `child.fs`), []byte(`
+        param $count: int required
+        param $label: string = 'item'
+
+        return "{count} {label}"
+    `), 0644))
+
+	t.Setenv(`FRIENDSCRIPT_PATH`, dir)
+
+	actual, err := eval(`run 'child' { data: { count: '2' } } -> $result`)
+	assert.NoError(err)
+	assert.Equal(`2 item`, actual[`result`])
+
+	actual, err = eval(`run 'child' { data: { count: 5, label: 'things' }, isolated: false } -> $result`)
+	assert.NoError(err)
+	assert.Equal(`5 things`, actual[`result`])
+
+	_, err = eval(`run 'child' { data: { count: 2.5 } }`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `invalid value for parameter $count`)
+
+	_, err = eval(`run 'child'`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `missing required parameter $count`)
+
+	// defaults that refer to variables are evaluated when the script is run, in the scope it is run in
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, `derived.fs`), []byte(`
+        param $count: int = 2
+        param $name: string = "{prefix}-{count}"
+        param $double: int = $count * 2
+
+        return "{name} {double}"
+    `), 0644))
+
+	actual, err = eval(`
+        $prefix = 'batch'
+        run 'derived' { data: { count: 3 }, isolated: false } -> $result
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`batch-3 6`, actual[`result`])
+
+	// ...and are only given as expressions when listing a script's parameters
+	params, err = NewEnvironment().Params(`derived`)
+	assert.NoError(err)
+	assert.Len(params, 3)
+
+	assert.True(params[0].HasDefault)
+	assert.EqualValues(2, params[0].Default)
+	assert.Equal(`2`, params[0].DefaultExpression)
+
+	assert.True(params[1].HasDefault)
+	assert.Nil(params[1].Default)
+	assert.Equal(`"{prefix}-{count}"`, params[1].DefaultExpression)
+
+	assert.Nil(params[2].Default)
+	assert.Equal(`$count * 2`, params[2].DefaultExpression)
+
+	_, err = NewEnvironment().Params(`nonexistent`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `could not locate script "nonexistent"`)
+
+	// invalid declarations
+	_, err = eval(`param $x: nope`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `unknown parameter type "nope"`)
+
+	_, err = eval(`if 1 == 1 { param $x }`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `top level`)
+}
+
+func TestRetryAndTimeout(t *testing.T) {
+	assert := require.New(t)
+
+	// retries re-run the block until it succeeds, with the attempt number available as $attempt
+	actual, err := eval(`
+        $seen = null
+
+        retry 5 { backoff: 'exponential', initial: 1ms, max: 5ms, on: /503|unavailable/ } {
+            $seen << $attempt
+
+            if $attempt < 3 {
+                fail "503 service unavailable"
+            }
+        }
+    `)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{1, 2, 3}, actual[`seen`])
+
+	// errors that don't match the "on" pattern are not retried
+	env := NewEnvironment()
+	env.RegisterModule(`testing`, newTestCommands(env))
+
+	scope, err := env.EvaluateString(`
+        $seen = null
+
+        retry 5 { on: /503/ } {
+            $seen << $attempt
+            fail "404 not found"
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `404 not found`)
+	assert.Equal([]interface{}{1}, scope.Get(`seen`))
+
+	// the last error is returned once all attempts are used up
+	scope, err = env.EvaluateString(`
+        $seen = null
+        $n = 3
+
+        retry $n {
+            $seen << $attempt
+            fail "attempt {attempt} failed"
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `attempt 3 failed`)
+	assert.Equal([]interface{}{1, 2, 3}, scope.Get(`seen`))
+
+	// timeouts cancel in-flight commands
+	started := time.Now()
+
+	scope, err = env.EvaluateString(`
+        $finished = false
+
+        timeout 50ms {
+            wait 5s
+            $finished = true
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `timeout`)
+	assert.True(time.Since(started) < time.Second)
+	assert.Equal(false, scope.Get(`finished`))
+
+	// commands that can't be cancelled run to completion, but still fail the timeout
+	scope, err = env.EvaluateString(`
+        $finished = false
+
+        timeout 20ms {
+            $finished = true
+            testing::sleep 100ms
+        }
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `timeout: did not complete within 20ms`)
+	assert.Equal(true, scope.Get(`finished`))
+
+	// blocks that finish in time are unaffected, and timeouts can be retried
+	actual, err = eval(`
+        timeout 1s {
+            $quick = true
+        }
+
+        retry 3 { on: /timeout/ } {
+            timeout 20ms {
+                if $attempt < 2 {
+                    wait 1s
+                }
+            }
+
+            $attempts = $attempt
+        }
+    `)
+
+	assert.NoError(err)
+	assert.Equal(true, actual[`quick`])
+	assert.Equal(2, actual[`attempts`])
+
+	// backoff delays
+	options := &scripting.RetryOptions{
+		Backoff: scripting.BackoffExponential,
+		Initial: 100 * time.Millisecond,
+		Max:     time.Second,
+	}
+
+	assert.Equal(100*time.Millisecond, options.Delay(1))
+	assert.Equal(400*time.Millisecond, options.Delay(3))
+	assert.Equal(time.Second, options.Delay(10))
+
+	options.Backoff = scripting.BackoffLinear
+	assert.Equal(300*time.Millisecond, options.Delay(3))
+}
+
 func TestCommands(t *testing.T) {
 	assert := require.New(t)
 
@@ -611,6 +1828,107 @@ func TestCommands(t *testing.T) {
 	assert.Equal(`test`, actual[`rv`])
 }
 
+func TestModules(t *testing.T) {
+	assert := require.New(t)
+
+	env := NewEnvironment()
+	env.RegisterModule(`testing`, newTestCommands(env))
+	env.RegisterModule(`Cloud::Object Store`, newTestCommands(env))
+	env.RegisterModule(`aws`, &testParentCommands{
+		children: map[string]utils.Module{
+			`s3`: &testParentCommands{
+				Module: newTestCommands(env),
+				children: map[string]utils.Module{
+					`buckets`: newTestCommands(env),
+				},
+			},
+		},
+	})
+
+	_, ok := env.Module(`cloud::object_store`)
+	assert.True(ok)
+	_, ok = env.Module(`aws::s3::buckets`)
+	assert.True(ok)
+	_, ok = env.Module(`aws::ec2`)
+	assert.False(ok)
+
+	// nested namespaces, both registered directly and provided by parent modules
+	scope, err := env.EvaluateString(`
+        cloud::object_store::echo 'one' -> $a
+        aws::s3::echo 'two' -> $b
+        aws::s3::buckets::echo 'three' -> $c
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`one`, scope.Get(`a`))
+	assert.Equal(`two`, scope.Get(`b`))
+	assert.Equal(`three`, scope.Get(`c`))
+
+	_, err = env.EvaluateString(`aws::ec2::echo 'nope'`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `Cannot locate module "aws::ec2"`)
+
+	// aliases
+	scope, err = env.EvaluateString(`
+        use testing as t
+        use aws::s3 as storage
+        use fmt
+
+        t::echo 'aliased' -> $a
+        storage::echo 'nested' -> $b
+        storage::buckets::echo 'deeper' -> $c
+        testing::echo 'original' -> $d
+        fmt::trim 'test' { prefix: 't' } -> $e
+    `)
+
+	assert.NoError(err)
+	assert.Equal(`aliased`, scope.Get(`a`))
+	assert.Equal(`nested`, scope.Get(`b`))
+	assert.Equal(`deeper`, scope.Get(`c`))
+	assert.Equal(`original`, scope.Get(`d`))
+	assert.Equal(`est`, scope.Get(`e`))
+
+	// aliases only apply to the script that declares them
+	_, err = env.EvaluateString(`t::echo 'nope'`)
+	assert.Error(err)
+
+	// aliases cannot be used to call disabled commands
+	env.DisableCommand(`testing`, `echo`)
+	_, err = env.EvaluateString("use testing as t\nt::echo 'nope'")
+	assert.Error(err)
+	assert.Contains(err.Error(), `disabled`)
+	env.EnableCommand(`testing`, `echo`)
+
+	// scripts that use unavailable modules fail before anything is evaluated
+	scope, err = env.EvaluateString(`
+        $started = true
+
+        use testing
+        use slack
+        use aws::ec2 as ec2
+    `)
+
+	assert.Error(err)
+	assert.Contains(err.Error(), `slack, aws::ec2`)
+	assert.Nil(scope.Get(`started`))
+
+	_, err = env.EvaluateString("use fmt as x\nuse url as x")
+	assert.Error(err)
+	assert.Contains(err.Error(), `already an alias for fmt`)
+
+	_, err = env.EvaluateString(`if 1 == 1 { use fmt }`)
+	assert.Error(err)
+	assert.Contains(err.Error(), `top level`)
+
+	// the modules a script uses can be inspected before it is evaluated
+	script, err := scripting.Parse("use http as web\nuse aws::s3\n\nweb::get 'https://example.com'")
+	assert.NoError(err)
+	assert.Equal([]*scripting.Import{
+		{Module: `http`, Alias: `web`, Line: 1},
+		{Module: `aws::s3`, Line: 2},
+	}, script.Imports())
+}
+
 func TestHttp(t *testing.T) {
 	assert := require.New(t)
 
@@ -641,10 +1959,79 @@ func TestHttp(t *testing.T) {
 		}
 	})
 
+	mux.HandleFunc(`/login`, func(w http.ResponseWriter, req *http.Request) {
+		http.SetCookie(w, &http.Cookie{
+			Name:  `sid`,
+			Value: `abc123`,
+		})
+	})
+
+	mux.HandleFunc(`/whoami`, func(w http.ResponseWriter, req *http.Request) {
+		if cookie, err := req.Cookie(`sid`); err == nil {
+			httputil.RespondJSON(w, map[string]interface{}{
+				`sid`: cookie.Value,
+			})
+		} else {
+			httputil.RespondJSON(w, nil, http.StatusUnauthorized)
+		}
+	})
+
+	mux.HandleFunc(`/moved`, func(w http.ResponseWriter, req *http.Request) {
+		http.Redirect(w, req, `/json/objects`, http.StatusFound)
+	})
+
+	var pagesServed int
+
+	mux.HandleFunc(`/pages`, func(w http.ResponseWriter, req *http.Request) {
+		var page = 1
+
+		if req.URL.Query().Get(`page`) == `2` {
+			page = 2
+		} else {
+			w.Header().Set(`Link`, `</pages?page=2>; rel="next"`)
+		}
+
+		pagesServed += 1
+
+		httputil.RespondJSON(w, map[string]interface{}{
+			`items`: []map[string]interface{}{
+				{`id`: page*10 + 1},
+				{`id`: page*10 + 2},
+			},
+		})
+	})
+
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	actual, err := eval("http::get %q -> $get_json_object", server.URL+`/json/objects`)
+	// redirects are followed unless told otherwise
+	actual, err := eval(`
+        http::get '%s/moved' -> $followed
+        $hop = $followed.redirects[0]
+        http::get '%s/moved' { follow_redirects: false, statuses: '200-399' } -> $unfollowed
+    `, server.URL, server.URL)
+
+	assert.NoError(err)
+	assert.EqualValues(server.URL+`/json/objects`, maputil.DeepGet(actual[`followed`], []string{`url`}))
+	assert.EqualValues(http.StatusFound, maputil.DeepGet(actual[`hop`], []string{`status`}))
+	assert.EqualValues(server.URL+`/moved`, maputil.DeepGet(actual[`hop`], []string{`url`}))
+	assert.EqualValues(http.StatusFound, maputil.DeepGet(actual[`unfollowed`], []string{`status`}))
+	assert.EqualValues(server.URL+`/moved`, maputil.DeepGet(actual[`unfollowed`], []string{`url`}))
+
+	// sessions remember cookies between requests
+	actual, err = eval(`
+        http::session 'api'
+        http::get '%s/login' { session: 'api' }
+        http::get '%s/whoami' { session: 'api' } -> $whoami
+        http::cookies 'api' -> $cookies
+    `, server.URL, server.URL)
+
+	assert.NoError(err)
+	assert.EqualValues(`abc123`, maputil.DeepGet(actual[`whoami`], []string{`body`, `sid`}))
+	assert.EqualValues(`sid`, maputil.DeepGet(actual[`cookies`], []string{`0`, `name`}))
+	assert.EqualValues(`abc123`, maputil.DeepGet(actual[`cookies`], []string{`0`, `value`}))
+
+	actual, err = eval("http::get %q -> $get_json_object", server.URL+`/json/objects`)
 	assert.NoError(err)
 	assert.EqualValues(http.StatusOK, maputil.DeepGet(actual[`get_json_object`], []string{`status`}))
 	assert.EqualValues(`got it, good`, maputil.DeepGet(actual[`get_json_object`], []string{`body`, `get`}))
@@ -672,6 +2059,42 @@ func TestHttp(t *testing.T) {
 	assert.EqualValues(1.23, maputil.DeepGet(actual[`post_json_object`], []string{`body`, `test`}))
 	assert.EqualValues(true, maputil.DeepGet(actual[`post_json_object`], []string{`body`, `data`}))
 	assert.EqualValues(`yes`, maputil.DeepGet(actual[`post_json_object`], []string{`body`, `value`}))
+
+	// requests can be recorded and replayed
+	dir, err := ioutil.TempDir(``, `friendscript-http-`)
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	actual, err = eval(`
+        http::record '%s'
+        http::get '%s/json/objects' -> $recorded
+        http::eject -> $count
+        http::replay '%s'
+        http::get '%s/json/objects' -> $replayed
+        http::export_har -> $har
+    `, filepath.Join(dir, `cassette.json`), server.URL, filepath.Join(dir, `cassette.json`), server.URL)
+
+	assert.NoError(err)
+	assert.EqualValues(1, actual[`count`])
+	assert.EqualValues(`got it, good`, maputil.DeepGet(actual[`replayed`], []string{`body`, `get`}))
+	assert.EqualValues(`1.2`, maputil.DeepGet(actual[`har`], []string{`log`, `version`}))
+
+	// paginated APIs can be looped over, and pages are only requested when they're needed
+	actual, err = eval(`
+        loop $item in http::paginate '%s/pages' { items: 'items' } {
+            $ids << $item.id
+        }
+
+        loop $item in http::paginate '%s/pages' { items: 'items' } {
+            $first = $item.id
+            break
+        }
+    `, server.URL, server.URL)
+
+	assert.NoError(err)
+	assert.Equal([]interface{}{float64(11), float64(12), float64(21), float64(22)}, actual[`ids`])
+	assert.EqualValues(11, actual[`first`])
+	assert.Equal(3, pagesServed)
 }
 
 func jsondiff(expected interface{}, actual interface{}) string {
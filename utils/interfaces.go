@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"context"
 	"io"
 
 	"github.com/PerformLine/friendscript/scripting"
@@ -19,8 +20,32 @@ type Runtime interface {
 	Open(fileOrReader interface{}) (io.ReadCloser, error)
 }
 
+// Implemented by runtimes whose scripts can be cancelled (e.g.: by an expiring timeout block), so
+// that long-running commands can stop when they are.
+type ContextProvider interface {
+	Context() context.Context
+}
+
+// Return the context of the given runtime, or a background context if it doesn't provide one.
+func RuntimeContext(runtime Runtime) context.Context {
+	if provider, ok := runtime.(ContextProvider); ok {
+		if ctx := provider.Context(); ctx != nil {
+			return ctx
+		}
+	}
+
+	return context.Background()
+}
+
 type Module interface {
 	ExecuteCommand(name string, arg interface{}, objargs map[string]interface{}) (interface{}, error)
 	FormatCommandName(string) string
 	SetInstance(interface{})
 }
+
+// Implemented by modules that contain other modules, whose commands are called by joining the
+// module names together, e.g.: "aws::s3::get" calls the "get" command of the "s3" submodule
+// of the module registered as "aws".
+type SubmoduleProvider interface {
+	Submodule(name string) (Module, bool)
+}
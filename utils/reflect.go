@@ -3,7 +3,10 @@ package utils
 import (
 	"fmt"
 	"reflect"
+	"strings"
+	"time"
 
+	"github.com/PerformLine/friendscript/scripting"
 	"github.com/PerformLine/go-stockutil/stringutil"
 
 	"github.com/PerformLine/go-stockutil/maputil"
@@ -12,6 +15,8 @@ import (
 )
 
 var errorInterface = reflect.TypeOf((*error)(nil)).Elem()
+var durationType = reflect.TypeOf(time.Duration(0))
+var timeType = reflect.TypeOf(time.Time{})
 
 func ListModuleCommands(module Module, skipNames ...string) []string {
 	commands := make([]string, 0)
@@ -87,7 +92,11 @@ func CallCommandFunction(from interface{}, name string, first interface{}, rest
 			// if we received a valid input for this argument, populate it
 			if i < len(inputs) {
 				if inV := reflect.ValueOf(inputs[i]); inV.IsValid() {
-					if inV.Type().AssignableTo(argT) {
+					if tV, ok := convertTemporal(inputs[i], argT); ok {
+						// parse durations and timestamps given as strings
+						arguments[i] = tV
+						continue
+					} else if inV.Type().AssignableTo(argT) {
 						// attempt direct assignment
 						arguments[i] = inV
 						continue
@@ -115,6 +124,8 @@ func CallCommandFunction(from interface{}, name string, first interface{}, rest
 							var inputM = maputil.DeepCopy(inputs[i])
 
 							if len(inputM) > 0 && arguments[i].IsValid() {
+								coerceTemporalFields(inputM, argT)
+
 								if err := maputil.TaggedStructFromMap(inputM, arguments[i], `json`); err != nil {
 									return nil, fmt.Errorf("Cannot populate %v: %v", arguments[i].Type(), err)
 								}
@@ -163,3 +174,50 @@ func CallCommandFunction(from interface{}, name string, first interface{}, rest
 		return nil, err
 	}
 }
+
+// convert strings into time.Duration or time.Time values if that is what the given type expects
+func convertTemporal(in interface{}, argT reflect.Type) (reflect.Value, bool) {
+	if str, ok := in.(string); ok {
+		switch argT {
+		case durationType:
+			if d, err := scripting.ParseDuration(str); err == nil {
+				return reflect.ValueOf(d), true
+			}
+		case timeType:
+			if t, err := scripting.ParseTimestamp(str); err == nil {
+				return reflect.ValueOf(t), true
+			}
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// walk the given map, converting the values of any fields in the struct it will be used to
+// populate that are durations or timestamps
+func coerceTemporalFields(in map[string]interface{}, structT reflect.Type) {
+	for structT.Kind() == reflect.Ptr {
+		structT = structT.Elem()
+	}
+
+	if structT.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < structT.NumField(); i++ {
+		var field = structT.Field(i)
+		var name = strings.Split(field.Tag.Get(`json`), `,`)[0]
+
+		if name == `` {
+			name = field.Name
+		}
+
+		if value, ok := in[name]; ok {
+			if tV, ok := convertTemporal(value, field.Type); ok {
+				in[name] = tV.Interface()
+			} else if sub, ok := value.(map[string]interface{}); ok {
+				coerceTemporalFields(sub, field.Type)
+			}
+		}
+	}
+}